@@ -29,6 +29,12 @@ type request struct {
 	Attributes *Attributes `json:"attributes,omitempty"`
 }
 
+// For setting attributes by name including ones Attributes doesn't know
+type setAttributeReq struct {
+	Command    string                 `json:"command"`
+	Attributes map[string]interface{} `json:"attributes"`
+}
+
 type response struct {
 	Status     string      `json:"status"`
 	Attributes *Attributes `json:"attributes"`
@@ -54,10 +60,13 @@ type Attributes struct {
 	NumericCharacters           string `json:"numericCharacters,omitempty"`
 	OpenTransaction             int    `json:"openTransaction,omitempty"` // Boolean, really (1/0)
 	QueryTimeout                uint32 `json:"queryTimeout,omitempty"`
+	ResultSetMaxRows            uint64 `json:"resultSetMaxRows,omitempty"`
 	SnapshotTransactionsEnabled bool   `json:"snapshotTransactionsEnabled,omitempty"`
 	TimestampUtcEnabled         bool   `json:"timestampUtcEnabled,omitempty"`
 	Timezone                    string `json:"timezone,omitempty"`
 	TimeZoneBehavior            string `json:"timeZoneBehavior,omitempty"`
+
+	set map[string]bool // The keys present when unmarshalled
 }
 
 type loginReq struct {
@@ -78,8 +87,10 @@ type loginData struct {
 }
 
 type authReq struct {
-	Username         string      `json:"username"`
-	Password         string      `json:"password"`
+	Username         string      `json:"username,omitempty"`
+	Password         string      `json:"password,omitempty"`
+	AccessToken      string      `json:"accessToken,omitempty"`
+	RefreshToken     string      `json:"refreshToken,omitempty"`
 	UseCompression   bool        `json:"useCompression"`
 	ClientName       string      `json:"clientName,omitempty"`
 	DriverName       string      `json:"driverName,omitempty"`
@@ -92,6 +103,14 @@ type authReq struct {
 	Attributes       *Attributes `json:"attributes,omitempty"`
 }
 
+// Protocol v3+ over TLS allows the credentials
+// to be sent directly with the login command
+type plainLoginReq struct {
+	Command         string `json:"command"`
+	ProtocolVersion uint16 `json:"protocolVersion"`
+	authReq
+}
+
 type authResp struct {
 	response
 	ResponseData *AuthData `json:"responseData"`
@@ -123,7 +142,7 @@ type execPrepStmt struct {
 	StatementHandle int             `json:"statementHandle"`
 	NumColumns      int             `json:"numColumns"`
 	NumRows         int             `json:"numRows"`
-	Columns         []column        `json:"columns"`
+	Columns         []Column        `json:"columns"`
 	Data            [][]interface{} `json:"data"`
 }
 
@@ -148,11 +167,12 @@ type resultSet struct {
 	NumColumns       int             `json:"numColumns"`
 	NumRows          uint64          `json:"numRows"`
 	NumRowsInMessage int             `json:"numRowsInMessage"`
-	Columns          []column        `json:"columns"`
+	Columns          []Column        `json:"columns"`
 	Data             [][]interface{} `json:"data"`
 }
 
-type column struct {
+// The name and data type of a result set (or bind parameter) column
+type Column struct {
 	Name     string   `json:"name"`
 	DataType DataType `json:"dataType"`
 }
@@ -216,7 +236,22 @@ type createPrepStmtData struct {
 
 type parameterData struct {
 	NumColumns int      `json:"numColumns"`
-	Columns    []column `json:"columns"`
+	Columns    []Column `json:"columns"`
+}
+
+type getHostsReq struct {
+	Command string `json:"command"`
+	HostIP  string `json:"hostIp"`
+}
+
+type getHostsRes struct {
+	response
+	ResponseData *hostsData `json:"responseData"`
+}
+
+type hostsData struct {
+	NumNodes int      `json:"numNodes"`
+	Nodes    []string `json:"nodes"`
 }
 
 type closePrepStmt struct {
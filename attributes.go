@@ -0,0 +1,256 @@
+/*
+	Exasol includes an attributes object in responses whenever session
+	attributes change (e.g. after OPEN SCHEMA or a statement that ends
+	the transaction). These are merged into the connection's view of the
+	session so that it doesn't drift from the server.
+
+	As the Attributes fields are omitempty a false/zero value can't be
+	distinguished from an absent one so the keys actually present in the
+	JSON are recorded when unmarshalling. Likewise, to send a zero value
+	the key has to be included explicitly e.g.
+
+	    conn.SetAttributes((&exasol.Attributes{}).Include("autocommit"))
+
+	Conn.Attributes returns this view without contacting the server.
+	If something may have changed the session behind our back (e.g.
+	a script) RefreshAttributes fetches them afresh.
+
+
+	AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+func (a *Attributes) UnmarshalJSON(data []byte) error {
+	type plain Attributes // Avoids recursing into this method
+	err := json.Unmarshal(data, (*plain)(a))
+	if err != nil {
+		return err
+	}
+	keys := map[string]json.RawMessage{}
+	err = json.Unmarshal(data, &keys)
+	if err != nil {
+		return err
+	}
+	a.set = map[string]bool{}
+	for k := range keys {
+		a.set[k] = true
+	}
+	return nil
+}
+
+// Zero valued fields are only sent if they have been included
+func (a Attributes) MarshalJSON() ([]byte, error) {
+	type plain Attributes
+	data, err := json.Marshal(plain(a))
+	if err != nil || len(a.set) == 0 {
+		return data, err
+	}
+	fields := map[string]json.RawMessage{}
+	err = json.Unmarshal(data, &fields)
+	if err != nil {
+		return nil, err
+	}
+	v := reflect.ValueOf(a)
+	for key := range a.set {
+		if _, ok := fields[key]; ok {
+			continue
+		}
+		if i, ok := attrFields[key]; ok {
+			fields[key], err = json.Marshal(v.Field(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return json.Marshal(fields)
+}
+
+// Marks the fields (by JSON key e.g. "autocommit") to be sent
+// even if they are zero valued. Returns the Attributes for chaining.
+func (a *Attributes) Include(keys ...string) *Attributes {
+	if a.set == nil {
+		a.set = map[string]bool{}
+	}
+	for _, key := range keys {
+		a.set[key] = true
+	}
+	return a
+}
+
+// Sets session attributes. Zero valued fields are not sent unless
+// they've been marked with Include.
+func (c *Conn) SetAttributes(attrs *Attributes) error {
+	if c.Conf.ReadOnly && attrs.Autocommit {
+		return c.errorf("Unable to set attributes: %w", &ReadOnlyError{SQL: "autocommit on"})
+	}
+	err := c.setAttributes(attrs)
+	if err != nil {
+		return c.errorf("Unable to set attributes: %w", err)
+	}
+	return nil
+}
+
+// Sets a single attribute by its JSON name e.g. "queryTimeout". Unlike
+// SetAttributes this also works for attributes that Attributes doesn't
+// have a field for (e.g. ones added in newer protocol versions).
+// Values for known attributes must be convertible to the field's type.
+func (c *Conn) SetAttribute(name string, value interface{}) error {
+	if name == "" {
+		return c.error("SetAttribute requires an attribute name")
+	}
+	i, known := attrFields[name]
+	if known {
+		ft := reflect.TypeOf(Attributes{}).Field(i).Type
+		if value == nil || !reflect.TypeOf(value).ConvertibleTo(ft) ||
+			(ft.Kind() == reflect.String) != (reflect.TypeOf(value).Kind() == reflect.String) {
+			return c.errorf("Unable to set attribute %s: it must be a %s not %T", name, ft, value)
+		}
+	}
+	if c.Conf.ReadOnly && name == "autocommit" && value == true {
+		return c.errorf("Unable to set attribute %s: %w", name, &ReadOnlyError{SQL: "autocommit on"})
+	}
+
+	err := c.send(&setAttributeReq{
+		Command:    "setAttributes",
+		Attributes: map[string]interface{}{name: value},
+	}, &response{})
+	if err != nil {
+		return c.errorf("Unable to set attribute %s to %v: %w", name, value, err)
+	}
+	if known {
+		sent := &Attributes{}
+		sent.setField(name, value)
+		c.trackAttributes(sent)
+	}
+	return nil
+}
+
+// The last known session attributes as tracked from the server's responses
+func (c *Conn) Attributes() Attributes {
+	return c.trackedAttributes()
+}
+
+// Fetches the session attributes from the server, updating the
+// connection's view of them, and returns them.
+func (c *Conn) RefreshAttributes(ctx context.Context) (Attributes, error) {
+	ctx, cancel := c.callContext(ctx)
+	defer cancel()
+	// The response's attributes are tracked as for every other response
+	err := c.sendContext(ctx, &request{Command: "getAttributes"}, &response{})
+	if err != nil {
+		return Attributes{}, c.errorf("Unable to get session attributes: %w", err)
+	}
+	return c.trackedAttributes(), nil
+}
+
+/*--- Private Routines ---*/
+
+// Maps JSON keys to Attributes field indexes
+var attrFields = func() map[string]int {
+	fields := map[string]int{}
+	t := reflect.TypeOf(Attributes{})
+	for i := 0; i < t.NumField(); i++ {
+		tag := t.Field(i).Tag.Get("json")
+		if tag == "" || tag == "-" {
+			continue
+		}
+		fields[strings.Split(tag, ",")[0]] = i
+	}
+	return fields
+}()
+
+// Copies the attributes that were present in the JSON into dst
+func (a *Attributes) mergeInto(dst *Attributes) {
+	src := reflect.ValueOf(a).Elem()
+	d := reflect.ValueOf(dst).Elem()
+	for key := range a.set {
+		if i, ok := attrFields[key]; ok {
+			d.Field(i).Set(src.Field(i))
+		}
+	}
+}
+
+// Sets the field with the given JSON key and includes it
+func (a *Attributes) setField(key string, val interface{}) {
+	if i, ok := attrFields[key]; ok {
+		f := reflect.ValueOf(a).Elem().Field(i)
+		f.Set(reflect.ValueOf(val).Convert(f.Type()))
+		a.Include(key)
+	}
+}
+
+// Gets the field with the given JSON key
+func (a *Attributes) field(key string) interface{} {
+	if i, ok := attrFields[key]; ok {
+		return reflect.ValueOf(a).Elem().Field(i).Interface()
+	}
+	return nil
+}
+
+// The keys that will be sent i.e. non-zero or included
+func (a *Attributes) keys() map[string]bool {
+	keys := map[string]bool{}
+	v := reflect.ValueOf(a).Elem()
+	for key, i := range attrFields {
+		if a.set[key] || !v.Field(i).IsZero() {
+			keys[key] = true
+		}
+	}
+	return keys
+}
+
+// Sends the attributes and then updates the connection's view of them
+// with what was sent (in case the server doesn't echo them back).
+func (c *Conn) setAttributes(attrs *Attributes) error {
+	err := c.send(&request{
+		Command:    "setAttributes",
+		Attributes: attrs,
+	}, &response{})
+	if err != nil {
+		return err
+	}
+	sent := *attrs
+	sent.set = attrs.keys()
+	c.trackAttributes(&sent)
+	return nil
+}
+
+func (c *Conn) trackAttributes(a *Attributes) {
+	if a == nil || len(a.set) == 0 {
+		return
+	}
+	c.attrMux.Lock()
+	defer c.attrMux.Unlock()
+	a.mergeInto(&c.attrs)
+}
+
+func (c *Conn) updateAttributes(update func(*Attributes)) {
+	c.attrMux.Lock()
+	defer c.attrMux.Unlock()
+	update(&c.attrs)
+}
+
+// Returns a copy of the connection's view of the session attributes
+func (c *Conn) trackedAttributes() Attributes {
+	c.attrMux.Lock()
+	defer c.attrMux.Unlock()
+	attrs := c.attrs
+	attrs.set = nil
+	return attrs
+}
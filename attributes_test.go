@@ -0,0 +1,181 @@
+package exasol
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+func (s *testSuite) TestAttributesMerge() {
+	a := &Attributes{}
+	err := json.Unmarshal([]byte(`{"autocommit":false,"queryTimeout":0}`), a)
+	s.NoError(err)
+
+	dst := Attributes{Autocommit: true, QueryTimeout: 10, CurrentSchema: "FOO"}
+	a.mergeInto(&dst)
+	s.False(dst.Autocommit, "Present false values are applied")
+	s.Equal(uint32(0), dst.QueryTimeout, "Present zero values are applied")
+	s.Equal("FOO", dst.CurrentSchema, "Absent values are left alone")
+}
+
+func (s *testSuite) TestTrackedAttributes() {
+	conf := s.connConf()
+	conf.Schema = s.schema
+	c, err := Connect(conf)
+	s.Require().NoError(err)
+	defer c.Disconnect()
+
+	attrs := c.trackedAttributes()
+	s.True(attrs.Autocommit)
+	s.Equal(s.schema, attrs.CurrentSchema)
+
+	_, err = c.Execute("OPEN SCHEMA sys")
+	s.NoError(err)
+	s.Equal("SYS", c.trackedAttributes().CurrentSchema)
+
+	s.NoError(c.DisableAutoCommit())
+	s.False(c.trackedAttributes().Autocommit)
+	s.NoError(c.EnableAutoCommit())
+	s.True(c.trackedAttributes().Autocommit)
+
+	s.NoError(c.SetTimeout(7))
+	_, err = c.Execute("SELECT 1", WithTimeout(2*time.Second))
+	s.NoError(err)
+	s.Equal(uint32(7), c.trackedAttributes().QueryTimeout, "Session timeout restored")
+	got, err := c.GetSessionAttr()
+	s.NoError(err)
+	s.Equal(uint32(7), got.QueryTimeout)
+}
+
+func (s *testSuite) TestSetAttributesZeroValues() {
+	data, err := json.Marshal((&Attributes{CurrentSchema: "FOO"}).Include("autocommit", "queryTimeout"))
+	s.NoError(err)
+	s.JSONEq(`{"autocommit":false,"queryTimeout":0,"currentSchema":"FOO"}`, string(data))
+
+	c, err := Connect(s.connConf())
+	s.Require().NoError(err)
+	defer c.Disconnect()
+
+	s.NoError(c.SetAttributes(&Attributes{QueryTimeout: 9}))
+	s.NoError(c.SetAttributes((&Attributes{}).Include("autocommit", "queryTimeout")))
+	got, err := c.GetSessionAttr()
+	s.Require().NoError(err)
+	s.False(got.Autocommit, "Sent autocommit=false")
+	s.Equal(uint32(0), got.QueryTimeout, "Sent queryTimeout=0")
+	s.False(c.Autocommit(), "Tracked")
+}
+
+func (s *testSuite) TestWithAttributes() {
+	c, err := Connect(s.connConf())
+	s.Require().NoError(err)
+	defer c.Disconnect()
+	s.NoError(c.SetTimeout(20))
+
+	got, err := c.FetchSlice(
+		"SELECT session_value FROM exa_parameters WHERE parameter_name = 'QUERY_TIMEOUT'",
+		WithAttributes(&Attributes{QueryTimeout: 7, CurrentSchema: s.schema}),
+	)
+	s.Require().NoError(err)
+	s.Equal("7", got[0][0], "Applied for the statement")
+
+	attrs, err := c.GetSessionAttr()
+	s.Require().NoError(err)
+	s.Equal(uint32(20), attrs.QueryTimeout, "Reverted afterwards")
+	s.Equal(uint32(20), c.trackedAttributes().QueryTimeout)
+
+	got, err = c.FetchSlice(
+		"SELECT session_value FROM exa_parameters WHERE parameter_name = 'QUERY_TIMEOUT'",
+		WithAttributes((&Attributes{}).Include("queryTimeout")),
+	)
+	s.Require().NoError(err)
+	s.Equal("0", got[0][0], "Included zero values are applied")
+	attrs, err = c.GetSessionAttr()
+	s.Require().NoError(err)
+	s.Equal(uint32(20), attrs.QueryTimeout)
+}
+
+func (s *testSuite) TestRefreshAttributes() {
+	c, err := Connect(s.connConf())
+	s.Require().NoError(err)
+	defer c.Disconnect()
+
+	s.True(c.Attributes().Autocommit)
+	s.NoError(c.SetQueryTimeout(12 * time.Second))
+	s.Equal(uint32(12), c.Attributes().QueryTimeout)
+
+	// Simulate the view drifting from the server
+	c.updateAttributes(func(a *Attributes) {
+		a.QueryTimeout = 99
+		a.Autocommit = false
+	})
+	s.Equal(uint32(99), c.Attributes().QueryTimeout)
+
+	attrs, err := c.RefreshAttributes(context.Background())
+	s.NoError(err)
+	s.Equal(uint32(12), attrs.QueryTimeout)
+	s.True(attrs.Autocommit)
+	s.Equal(attrs, c.Attributes())
+}
+
+func (s *testSuite) TestSetAttribute() {
+	c, err := Connect(s.connConf())
+	s.Require().NoError(err)
+	defer c.Disconnect()
+
+	s.NoError(c.SetAttribute("queryTimeout", 42))
+	s.Equal(uint32(42), c.Attributes().QueryTimeout)
+	attrs, err := c.GetSessionAttr()
+	s.NoError(err)
+	s.Equal(uint32(42), attrs.QueryTimeout)
+
+	s.NoError(c.SetAttribute("autocommit", false))
+	s.False(c.Attributes().Autocommit)
+
+	c.Conf.SuppressError = true
+	err = c.SetAttribute("queryTimeout", "soon")
+	s.EqualError(err, "Unable to set attribute queryTimeout: it must be a uint32 not string")
+	err = c.SetAttribute("currentSchema", 5)
+	s.Error(err, "Ints aren't converted to strings")
+	s.Error(c.SetAttribute("", 1))
+}
+
+func (s *testSuite) TestWithAttributesConcurrent() {
+	c, err := Connect(s.connConf())
+	s.Require().NoError(err)
+	defer c.Disconnect()
+	s.NoError(c.SetTimeout(20))
+
+	sql := "SELECT session_value FROM exa_parameters WHERE parameter_name = 'QUERY_TIMEOUT'"
+	var wg sync.WaitGroup
+	for i := 1; i <= 8; i++ {
+		wg.Add(2)
+		go func(timeout uint32) {
+			defer wg.Done()
+			got, err := c.FetchSlice(sql, WithAttributes(&Attributes{QueryTimeout: timeout}))
+			s.NoError(err)
+			s.Equal(fmt.Sprint(timeout), got[0][0], "Override applied")
+		}(uint32(i))
+		go func() {
+			defer wg.Done()
+			got, err := c.FetchSlice(sql)
+			s.NoError(err)
+			s.Equal("20", got[0][0], "Other statements don't see overrides")
+		}()
+	}
+	wg.Wait()
+
+	attrs, err := c.GetSessionAttr()
+	s.Require().NoError(err)
+	s.Equal(uint32(20), attrs.QueryTimeout, "Reverted afterwards")
+}
+
+func (s *testSuite) TestWithTimeoutRoundsUp() {
+	got, err := s.exaConn.FetchSlice(
+		"SELECT session_value FROM exa_parameters WHERE parameter_name = 'QUERY_TIMEOUT'",
+		WithTimeout(500*time.Millisecond),
+	)
+	s.Require().NoError(err)
+	s.Equal("1", got[0][0], "Sub-second timeouts aren't dropped")
+}
@@ -0,0 +1,376 @@
+/*
+	Chunked commits for bulk imports.
+
+	By default an import is a single IMPORT statement so a failure 90% of
+	the way through a huge load rolls back everything. With the
+	WithCommitEvery option the CSV stream is split (on record boundaries)
+	into chunks of N rows and each is imported and committed in turn.
+	The BulkProgress records how far it got so that the job can resume
+	by skipping the rows that were committed, e.g.
+
+	    progress := &exasol.BulkProgress{}
+	    err := conn.StreamInsert(schema, table, data,
+	        exasol.WithCommitEvery(1000000, progress))
+	    if err != nil {
+	        log.Printf("Failed after committing %d rows", progress.RowsCommitted)
+	    }
+
+	Each chunk is a separate transaction so a failed load leaves the
+	committed chunks in place.
+
+	To make a long load resumable persist the progress from a checkpoint
+	callback, which is called after each chunk is committed, and pass
+	it back in with WithResume when rerunning the job with the same data.
+	The rows that were already committed are then skipped, e.g.
+
+	    progress := loadCheckpoint() // A zero BulkProgress the first time
+	    err := conn.StreamInsert(schema, table, data,
+	        exasol.WithCommitEvery(1000000, progress),
+	        exasol.WithCheckpoint(saveCheckpoint),
+	        exasol.WithResume())
+
+	The same applies to prepared statement inserts via Execute's
+	WithChunks option. On failure a *ChunkError reports which chunk
+	failed and how many rows were committed. If bisect is enabled
+	failing chunks are instead repeatedly halved until the offending
+	rows are isolated. All the other rows are committed and the
+	offending ones are reported via a RowErrors error, e.g.
+
+	    n, err := conn.Execute(sql, exasol.WithBinds(rows...),
+	        exasol.WithChunks(10000, true))
+	    var rowErrs exasol.RowErrors
+	    if errors.As(err, &rowErrs) {
+	        for _, re := range rowErrs {
+	            log.Printf("Row %d failed: %s", re.Row, re.Err)
+	        }
+	    }
+
+
+	AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"fmt"
+)
+
+type BulkProgress struct {
+	RowsCommitted   int64
+	ChunksCommitted int
+}
+
+type ChunkError struct {
+	Offset        int // The index of the chunk's first row in the binds
+	Rows          int
+	RowsCommitted int64
+	Err           error
+}
+
+func (e *ChunkError) Error() string {
+	return fmt.Sprintf(
+		"Unable to execute rows %d-%d (%d rows were committed): %s",
+		e.Offset, e.Offset+e.Rows-1, e.RowsCommitted, e.Err,
+	)
+}
+
+func (e *ChunkError) Unwrap() error { return e.Err }
+
+type RowError struct {
+	Row int // The index of the row in the binds
+	Err error
+}
+
+type RowErrors []RowError
+
+func (e RowErrors) Error() string {
+	if len(e) == 1 {
+		return fmt.Sprintf("Row %d failed: %s", e[0].Row, e[0].Err)
+	}
+	return fmt.Sprintf("%d rows failed, the first (row %d) with: %s", len(e), e[0].Row, e[0].Err)
+}
+
+/*--- Private Routines ---*/
+
+func (c *Conn) streamExecuteChunked(sql string, data <-chan []byte, bc *BulkConf) error {
+	progress := bc.Progress
+	if progress == nil {
+		progress = &BulkProgress{}
+	}
+	// Stops the chunker if we bail out early. As with StreamExecute the
+	// rest of the data is left unread for the caller to deal with.
+	stop := make(chan struct{})
+	defer close(stop)
+	if bc.Resume && progress.RowsCommitted > 0 {
+		c.log.Infof("Resuming import after %d committed rows", progress.RowsCommitted)
+		data = skipCSVRecords(data, progress.RowsCommitted, bc.CSV.quote(), stop)
+	}
+	chunks := chunkCSV(data, bc.CommitEvery, bc.CSV.quote(), stop)
+
+	chunkSQL, err := withErrorClause(sql, bc)
+	if err != nil {
+		return err
+	}
+	for chunk := range chunks {
+		imported, err := c.streamExecute(chunkSQL, chunk.data, bc)
+		var rows int
+		if err == nil {
+			rows = <-chunk.rows
+			if bc.Verify {
+				err = verifyCount("import", progress.ChunksCommitted+1, int64(rows), imported)
+				if err != nil && !c.Autocommit() {
+					c.Rollback()
+				}
+			}
+		}
+		if err == nil && !c.Autocommit() {
+			err = c.Commit()
+		}
+		if err != nil {
+			return fmt.Errorf(
+				"Unable to import chunk %d (%d rows were committed): %w",
+				progress.ChunksCommitted+1, progress.RowsCommitted, err,
+			)
+		}
+		progress.ChunksCommitted++
+		progress.RowsCommitted += int64(rows)
+		if bc.Checkpoint != nil {
+			err = bc.Checkpoint(*progress)
+			if err != nil {
+				return fmt.Errorf(
+					"Unable to checkpoint after chunk %d (%d rows were committed): %w",
+					progress.ChunksCommitted, progress.RowsCommitted, err,
+				)
+			}
+		}
+		if bc.ErrorsMode != "" {
+			// Only the first chunk should replace/truncate the error table
+			appendConf := *bc
+			appendConf.ErrorsMode = ""
+			chunkSQL, err = withErrorClause(sql, &appendConf)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (c *Conn) executeChunked(sql string, ec *ExecConf) (int64, error) {
+	rows := ec.Binds
+	if ec.IsColumnar {
+		rows = Transpose(rows)
+	}
+	var affected int64
+	var rowErrs RowErrors
+	for offset := 0; offset < len(rows); offset += ec.ChunkSize {
+		end := offset + ec.ChunkSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+		n, err := c.executeChunk(sql, ec, rows[offset:end])
+		if err != nil && ec.Bisect {
+			n = c.bisectChunk(sql, ec, rows, offset, end, err, &rowErrs)
+			err = nil
+		}
+		if err != nil {
+			return affected, c.errorf("%w", &ChunkError{
+				Offset:        offset,
+				Rows:          end - offset,
+				RowsCommitted: affected,
+				Err:           err,
+			})
+		}
+		affected += n
+	}
+	if len(rowErrs) > 0 {
+		return affected, c.errorf("%w", rowErrs)
+	}
+	return affected, nil
+}
+
+// Executes and commits the rows
+func (c *Conn) executeChunk(sql string, ec *ExecConf, rows [][]interface{}) (int64, error) {
+	chunkConf := *ec
+	chunkConf.Binds = rows
+	chunkConf.IsColumnar = false
+	res, err := c.execute(sql, &chunkConf)
+	if err != nil {
+		return 0, err
+	}
+	if !c.Autocommit() {
+		err = c.Commit()
+		if err != nil {
+			return 0, err
+		}
+	}
+	if res.ResponseData != nil && res.ResponseData.NumResults > 0 {
+		return res.ResponseData.Results[0].RowCount, nil
+	}
+	return 0, nil
+}
+
+// Recursively halves the rows between from and to (which failed with err)
+// until the failing rows are isolated. Returns the number of rows affected
+// by the successful halves.
+func (c *Conn) bisectChunk(sql string, ec *ExecConf, rows [][]interface{}, from, to int, err error, rowErrs *RowErrors) int64 {
+	if to-from == 1 {
+		*rowErrs = append(*rowErrs, RowError{Row: from, Err: err})
+		return 0
+	}
+	mid := from + (to-from)/2
+	var affected int64
+	for _, half := range [][2]int{{from, mid}, {mid, to}} {
+		n, err := c.executeChunk(sql, ec, rows[half[0]:half[1]])
+		if err != nil {
+			n = c.bisectChunk(sql, ec, rows, half[0], half[1], err, rowErrs)
+		}
+		affected += n
+	}
+	return affected
+}
+
+type csvChunk struct {
+	data chan []byte
+	rows chan int // Receives the chunk's row count once data is closed
+}
+
+// Splits the CSV stream into a stream of chunks of up to n rows each.
+// Gives up once stop is closed.
+func chunkCSV(data <-chan []byte, n int, quote byte, stop <-chan struct{}) <-chan *csvChunk {
+	chunks := make(chan *csvChunk)
+	go func() {
+		defer close(chunks)
+		var cur *csvChunk
+		rows := 0
+		inQuote := false
+		partial := false // Whether the chunk ends mid-record
+		finish := func() {
+			close(cur.data)
+			cur.rows <- rows
+			cur = nil
+			rows = 0
+		}
+		send := func(b []byte) bool {
+			select {
+			case cur.data <- b:
+				return true
+			case <-stop:
+				return false
+			}
+		}
+		for {
+			b, ok := recvChunk(data, stop)
+			if !ok {
+				break
+			}
+			for len(b) > 0 {
+				if cur == nil {
+					cur = &csvChunk{make(chan []byte, 1), make(chan int, 1)}
+					select {
+					case chunks <- cur:
+					case <-stop:
+						return
+					}
+				}
+				pos, found := scanCSVRecords(b, n-rows, quote, &inQuote)
+				rows += found
+				if rows < n {
+					partial = inQuote || b[len(b)-1] != '\n'
+					if !send(b) {
+						return
+					}
+					break
+				}
+				partial = false
+				if !send(b[:pos]) {
+					return
+				}
+				b = b[pos:]
+				finish()
+			}
+		}
+		select {
+		case <-stop:
+			return
+		default:
+		}
+		if cur != nil {
+			if partial {
+				rows++ // A final record without a trailing newline
+			}
+			finish()
+		}
+	}()
+	return chunks
+}
+
+// Drops the first n records of the CSV stream. Gives up once stop is closed.
+func skipCSVRecords(data <-chan []byte, n int64, quote byte, stop <-chan struct{}) <-chan []byte {
+	out := make(chan []byte, 1)
+	go func() {
+		defer close(out)
+		inQuote := false
+		for {
+			b, ok := recvChunk(data, stop)
+			if !ok {
+				return
+			}
+			for n > 0 && len(b) > 0 {
+				k := n
+				if k > 1<<30 {
+					k = 1 << 30
+				}
+				pos, found := scanCSVRecords(b, int(k), quote, &inQuote)
+				n -= int64(found)
+				b = b[pos:]
+			}
+			if len(b) > 0 {
+				select {
+				case out <- b:
+				case <-stop:
+					return
+				}
+			}
+		}
+	}()
+	return out
+}
+
+// Returns false once data is closed or stop is
+func recvChunk(data <-chan []byte, stop <-chan struct{}) ([]byte, bool) {
+	select {
+	case b, ok := <-data:
+		return b, ok
+	case <-stop:
+		return nil, false
+	}
+}
+
+// Finds the end of up to k records in b returning the offset just past
+// the last one found and the number found. inQuote carries the quoting
+// state across calls.
+func scanCSVRecords(b []byte, k int, quote byte, inQuote *bool) (pos, found int) {
+	for i, ch := range b {
+		switch ch {
+		case quote:
+			*inQuote = !*inQuote
+		case '\n':
+			if !*inQuote {
+				found++
+				if found == k {
+					return i + 1, found
+				}
+			}
+		}
+	}
+	return len(b), found
+}
@@ -0,0 +1,103 @@
+package exasol
+
+import (
+	"bytes"
+	"errors"
+	"time"
+)
+
+func (s *testSuite) TestExecuteChunks() {
+	exa := s.exaConn
+	exa.Execute("CREATE TABLE foo ( id INT )")
+	exa.Conf.SuppressError = true
+	sql := "INSERT INTO foo VALUES (?)"
+	rows := [][]interface{}{{1}, {2}, {"x"}, {4}, {5}, {"y"}, {7}}
+
+	// Without bisecting it stops at the first failing chunk
+	n, err := exa.Execute(sql, WithBinds(rows...), WithChunks(2, false))
+	var chunkErr *ChunkError
+	if s.True(errors.As(err, &chunkErr)) {
+		s.Equal(2, chunkErr.Offset)
+		s.Equal(2, chunkErr.Rows)
+		s.Equal(int64(2), chunkErr.RowsCommitted)
+	}
+	s.Equal(int64(2), n)
+
+	exa.Execute("TRUNCATE TABLE foo")
+	n, err = exa.Execute(sql, WithBinds(rows...), WithChunks(3, true))
+	var rowErrs RowErrors
+	if s.True(errors.As(err, &rowErrs)) && s.Len(rowErrs, 2) {
+		s.Equal(2, rowErrs[0].Row)
+		s.Equal(5, rowErrs[1].Row)
+	}
+	s.Equal(int64(5), n)
+
+	got, err := exa.FetchSlice("SELECT id FROM foo ORDER BY id")
+	if s.NoError(err) {
+		s.Equal([][]interface{}{
+			{float64(1)}, {float64(2)}, {float64(4)}, {float64(5)}, {float64(7)},
+		}, got)
+	}
+}
+
+func (s *testSuite) TestResumeImport() {
+	exa := s.exaConn
+	s.execute("CREATE TABLE foo ( id INT )")
+	exa.Conf.SuppressError = true
+	data := "1\n2\n3\n4\nx\n6\n7\n"
+
+	// Simulate an interruption by failing on the bad row
+	var saved []BulkProgress
+	checkpoint := func(p BulkProgress) error {
+		saved = append(saved, p)
+		return nil
+	}
+	progress := &BulkProgress{}
+	err := exa.BulkInsert(s.qschema, "FOO", bytes.NewBufferString(data),
+		WithCommitEvery(2, progress), WithCheckpoint(checkpoint))
+	s.Error(err)
+	s.Equal([]BulkProgress{{2, 1}, {4, 2}}, saved)
+
+	// Fix the data and resume from the last checkpoint
+	data = "1\n2\n3\n4\n5\n6\n7\n"
+	resumed := saved[len(saved)-1]
+	err = exa.BulkInsert(s.qschema, "FOO", bytes.NewBufferString(data),
+		WithCommitEvery(2, &resumed), WithCheckpoint(checkpoint), WithResume())
+	s.Require().NoError(err)
+	s.Equal(BulkProgress{7, 4}, resumed)
+
+	got, err := exa.FetchSlice("SELECT COUNT(*), COUNT(DISTINCT id) FROM foo")
+	s.Require().NoError(err)
+	s.Equal([]interface{}{float64(7), float64(7)}, got[0])
+}
+
+func (s *testSuite) TestChunkedImportFailureStopsReading() {
+	exa := s.exaConn
+	s.execute("CREATE TABLE foo ( id INT )")
+	exa.Conf.SuppressError = true
+
+	// An endless stream whose rest isn't read once a chunk fails
+	data := make(chan []byte)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		data <- []byte("1\nx\n")
+		for {
+			select {
+			case data <- []byte("2\n"):
+			case <-done:
+				return
+			}
+		}
+	}()
+	errs := make(chan error, 1)
+	go func() {
+		errs <- exa.StreamInsert(s.qschema, "FOO", data, WithCommitEvery(2, nil))
+	}()
+	select {
+	case err := <-errs:
+		s.Error(err)
+	case <-time.After(30 * time.Second):
+		s.Fail("The import kept reading after the chunk failed")
+	}
+}
@@ -0,0 +1,107 @@
+/*
+	Bind values can be logged (at Debug level) alongside statements to help
+	diagnose data issues. Columns whose names match any of the Redact patterns
+	are masked and long values are truncated so that logs don't fill up with
+	PII, e.g.
+
+	    conf.LogBinds = &exasol.BindLogConf{
+	        Redact: []*regexp.Regexp{regexp.MustCompile(`(?i)ssn|email|pass`)},
+	    }
+
+
+	AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type BindLogConf struct {
+	// Matched against the column names of the prepared statement's parameters
+	Redact []*regexp.Regexp
+	// Values are truncated to this many characters. Defaults to 64.
+	MaxLen int
+	// Only the first MaxRows rows are logged. Defaults to 10.
+	MaxRows int
+}
+
+const redacted = "<redacted>"
+
+/*--- Private Routines ---*/
+
+// The binds are in columnar format
+func (c *Conn) logBinds(cols []Column, binds [][]interface{}) {
+	blc := c.Conf.LogBinds
+	if blc == nil || len(binds) == 0 {
+		return
+	}
+	maxLen := blc.MaxLen
+	if maxLen <= 0 {
+		maxLen = 64
+	}
+	maxRows := blc.MaxRows
+	if maxRows <= 0 {
+		maxRows = 10
+	}
+
+	redact := make([]bool, len(binds))
+	for i := range binds {
+		if i < len(cols) {
+			redact[i] = blc.shouldRedact(cols[i].Name)
+		}
+	}
+
+	numRows := len(binds[0])
+	for row := 0; row < numRows && row < maxRows; row++ {
+		vals := make([]string, len(binds))
+		for i, col := range binds {
+			name := fmt.Sprintf("%d", i+1)
+			if i < len(cols) && cols[i].Name != "" {
+				name = cols[i].Name
+			}
+			val := redacted
+			if row >= len(col) {
+				val = "<missing>" // Ragged binds which the server will reject
+			} else if !redact[i] {
+				val = truncateValue(col[row], maxLen)
+			}
+			vals[i] = name + "=" + val
+		}
+		c.log.Debugf("Bind row %d: %s", row+1, strings.Join(vals, ", "))
+	}
+	if numRows > maxRows {
+		c.log.Debugf("Not logging the remaining %d bind rows", numRows-maxRows)
+	}
+}
+
+func (blc *BindLogConf) shouldRedact(name string) bool {
+	for _, re := range blc.Redact {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+func truncateValue(val interface{}, maxLen int) string {
+	if val == nil {
+		return "NULL"
+	}
+	str := []rune(fmt.Sprintf("%v", val))
+	if len(str) <= maxLen {
+		return string(str)
+	}
+	return fmt.Sprintf("%s...(%d chars)", string(str[:maxLen]), len(str))
+}
@@ -0,0 +1,43 @@
+package exasol
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+)
+
+func (s *testSuite) TestLogBinds() {
+	s.exaConn.Execute("CREATE TABLE foo ( name VARCHAR(100), secret VARCHAR(100) )")
+
+	conf := s.connConf()
+	output := &bytes.Buffer{}
+	logger := customTestLogger("debug")
+	logger.SetOutput(output)
+	conf.Logger = logger
+	conf.LogBinds = &BindLogConf{
+		Redact:  []*regexp.Regexp{regexp.MustCompile(`(?i)secret`)},
+		MaxLen:  5,
+		MaxRows: 1,
+	}
+	c, err := Connect(conf)
+	s.Require().NoError(err)
+	defer c.Disconnect()
+
+	_, err = c.Execute("INSERT INTO foo VALUES (?, ?)", WithSchema(s.schema), WithBinds(
+		[]interface{}{"abcdefgh", "hunter2"},
+		[]interface{}{"xyz", "letmein"},
+	))
+	s.Require().NoError(err)
+
+	out := output.String()
+	s.Contains(out, "NAME=abcde...(8 chars)", "Truncated")
+	s.Contains(out, "SECRET="+redacted, "Redacted")
+	s.False(strings.Contains(out, "hunter2"), "Secret not logged")
+	s.False(strings.Contains(out, "xyz"), "Only MaxRows logged")
+	s.Contains(out, "remaining 1 bind rows")
+
+	// Columns with fewer rows than the first one don't panic
+	output.Reset()
+	c.logBinds(nil, [][]interface{}{{"a", "b"}, {}})
+	s.Contains(output.String(), "1=a, 2=<missing>")
+}
@@ -20,7 +20,8 @@
 	For each of the Bulk & Streaming interfaces there are 4 possible interactions:
 
 	1) "Insert" is for inserting into a single table with the data provided
-	   mapping directly into the table columns
+	   mapping directly into the table columns (or as given by the
+	   WithColumnMapping option)
 
  	2) "Execute" allow you to do a bulk data import for arbitrarily complex
 	   INSERT or MERGE statements. The DML provided must include an IMPORT
@@ -53,64 +54,147 @@ package exasol
 
 import (
 	"bytes"
+	"compress/gzip"
 	"errors"
 	"fmt"
+	"io"
+	"math/rand"
+	"net"
 	"regexp"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
-func (c *Conn) BulkInsert(schema, table string, data *bytes.Buffer) (err error) {
-	sql := c.getTableImportSQL(schema, table)
-	return c.BulkExecute(sql, data)
+// The number of rejected rows fetched into BulkResult.Rejected
+const rejectSampleSize = 10
+
+func (c *Conn) BulkInsert(schema, table string, data *bytes.Buffer, opts ...BulkOption) (err error) {
+	sql, err := c.getTableImportSQL(schema, table, newBulkConf(opts))
+	if err != nil {
+		return c.errorf("Unable to import data: %w", err)
+	}
+	return c.BulkExecute(sql, data, opts...)
 }
 
-func (c *Conn) BulkExecute(sql string, data *bytes.Buffer) error {
+func (c *Conn) BulkExecute(sql string, data *bytes.Buffer, opts ...BulkOption) error {
 	if data == nil {
 		return fmt.Errorf("You must pass in a bytes.Buffer pointer to BulkExecute")
 	}
 	dataChan := make(chan []byte, 1)
 	dataChan <- data.Bytes()
 	close(dataChan)
-	return c.StreamExecute(sql, dataChan)
+	return c.StreamExecute(sql, dataChan, opts...)
 }
 
-func (c *Conn) BulkSelect(schema, table string, data *bytes.Buffer) (err error) {
-	sql := c.getTableExportSQL(schema, table)
-	return c.BulkQuery(sql, data)
+func (c *Conn) BulkSelect(schema, table string, data *bytes.Buffer, opts ...BulkOption) (err error) {
+	sql := c.getTableExportSQL(schema, table, newBulkConf(opts))
+	return c.BulkQuery(sql, data, opts...)
 }
 
-func (c *Conn) BulkQuery(sql string, data *bytes.Buffer) error {
+func (c *Conn) BulkQuery(sql string, data *bytes.Buffer, opts ...BulkOption) error {
 	if data == nil {
 		return fmt.Errorf("You must pass in a bytes.Buffer pointer to BulkQuery")
 	}
-	rows := c.StreamQuery(sql)
+	rows := c.StreamQuery(sql, opts...)
 	for b := range rows.Data {
 		data.Write(b)
 	}
 	if rows.Error != nil {
-		return fmt.Errorf("Unable to BulkQuery: %s", rows.Error)
+		return fmt.Errorf("Unable to BulkQuery: %w", rows.Error)
 	}
 	return nil
 }
 
-func (c *Conn) StreamInsert(schema, table string, data <-chan []byte) (err error) {
-	sql := c.getTableImportSQL(schema, table)
-	return c.StreamExecute(sql, data)
+func (c *Conn) StreamInsert(schema, table string, data <-chan []byte, opts ...BulkOption) (err error) {
+	sql, err := c.getTableImportSQL(schema, table, newBulkConf(opts))
+	if err != nil {
+		return c.errorf("Unable to import data: %w", err)
+	}
+	return c.StreamExecute(sql, data, opts...)
 }
 
-func (c *Conn) StreamExecute(origSQL string, data <-chan []byte) error {
+func (c *Conn) StreamExecute(origSQL string, data <-chan []byte, opts ...BulkOption) error {
 	if data == nil {
 		return fmt.Errorf("You must pass in a []byte chan to StreamExecute")
 	}
+	bc := newBulkConf(opts)
+	if bc.CSV.Skip > 0 && (bc.Parallelism > 1 || bc.CommitEvery > 0) {
+		// Each proxy's file or chunk would have its first rows skipped
+		return c.error("CSV Skip can't be combined with Parallelism or CommitEvery")
+	}
+	if bc.Resume && bc.CommitEvery <= 0 {
+		return c.error("Resuming an import requires CommitEvery")
+	}
+	if bc.Verify && bc.RejectLimit != 0 {
+		return c.error("Verifying an import can't be combined with RejectLimit")
+	}
+	sql, err := withErrorClause(origSQL, bc)
+	if err != nil {
+		return c.errorf("Unable to import data: %w", err)
+	}
+	if bc.Result != nil {
+		*bc.Result = BulkResult{}
+		before := c.countRejects(bc)
+		defer c.collectRejects(bc, before)
+	}
+	if bc.CommitEvery > 0 {
+		return c.streamExecuteChunked(origSQL, data, bc)
+	}
+	counter := &csvCounter{quote: bc.CSV.quote()}
+	if bc.Verify {
+		data = countCSV(data, counter)
+	}
+	rowCount, err := c.streamExecute(sql, data, bc)
+	if err == nil && bc.Verify {
+		err = verifyCount("import", 0, counter.total()-int64(bc.CSV.Skip), rowCount)
+		if err != nil {
+			return c.errorf("Unable to verify import: %w", err)
+		}
+	}
+	return err
+}
+
+// Returns the number of rows imported
+func (c *Conn) streamExecute(origSQL string, data <-chan []byte, bc *BulkConf) (int64, error) {
+	hosts, err := c.bulkHosts(bc.Parallelism)
+	if err != nil {
+		return 0, c.errorf("Unable to import data: %w", err)
+	}
+	if bc.rowsLimiter != nil {
+		data = throttleRows(data, bc.rowsLimiter, bc.CSV.quote())
+	}
+	// Stops the sharder and compressors if the data isn't all read
+	done := make(chan struct{})
+	defer close(done)
+	shards := []<-chan []byte{data}
+	if len(hosts) > 1 {
+		shards = shardCSV(data, len(hosts), bc.CSV.quote(), done)
+	}
+	var gzErrs []func() error
+	if bc.Gzip || isGzipSQL(origSQL) {
+		gzErrs = make([]func() error, len(shards))
+		for i := range shards {
+			shards[i], gzErrs[i] = gzipChan(shards[i], done)
+		}
+	}
 
 	// Retry twice cuz it seems we sometimes get sentient errors
-	for range []int{1, 2} {
-		bytesWritten, err := c.streamExecuteNoRetry(origSQL, data)
+	for attempt := 1; ; attempt++ {
+		bytesWritten, rowCount, err := c.streamExecuteNoRetry(origSQL, hosts, shards, bc.bytesLimiter)
+		for _, gzErr := range gzErrs {
+			if err == nil {
+				err = gzErr()
+			}
+		}
 		if err != nil {
 			if retryableError(err) {
-				if bytesWritten == 0 {
-					c.error("Retrying...")
+				// Nothing has been read from the shards unless
+				// bytes were written so the data is all still there
+				if bytesWritten == 0 && attempt < 2 {
+					c.errorf("Retrying after command %d...", c.lastCommandID())
 					continue
 				}
 				// If there was an error while writing the data
@@ -118,16 +202,18 @@ func (c *Conn) StreamExecute(origSQL string, data <-chan []byte) error {
 				c.error("Data already sent can't retry...")
 			}
 			c.error(err.Error())
-			return err
+			return 0, err
 		}
-		break
+		if bc.Result != nil {
+			bc.Result.RowsImported += rowCount
+		}
+		return rowCount, nil
 	}
-	return nil
 }
 
-func (c *Conn) StreamSelect(schema, table string) *Rows {
-	sql := c.getTableExportSQL(schema, table)
-	return c.StreamQuery(sql)
+func (c *Conn) StreamSelect(schema, table string, opts ...BulkOption) *Rows {
+	sql := c.getTableExportSQL(schema, table, newBulkConf(opts))
+	return c.StreamQuery(sql, opts...)
 }
 
 var bufPool = sync.Pool{
@@ -136,13 +222,16 @@ var bufPool = sync.Pool{
 	},
 }
 
-func (c *Conn) StreamQuery(exportSQL string) *Rows {
+func (c *Conn) StreamQuery(exportSQL string, opts ...BulkOption) *Rows {
+	bc := newBulkConf(opts)
 	r := &Rows{
 		Data: make(chan []byte, 1),
 		Pool: &bufPool,
 		conn: c,
-		stop: make(chan bool, 1),
+		stop: make(chan bool),
 		wg:   sync.WaitGroup{},
+		gzip: bc.Gzip || isGzipSQL(exportSQL),
+		bc:   bc,
 	}
 
 	// Asynchronously read in the data from Exasol
@@ -155,10 +244,15 @@ func (c *Conn) StreamQuery(exportSQL string) *Rows {
 
 		// Retry once because for some reason we occasionally get "connection refused"
 		// errors when Exasol tries to connect to the internal proxy that it set up.
+		hosts, err := c.bulkHosts(bc.Parallelism)
+		if err != nil {
+			r.Error = c.errorf("Unable to export data: %w", err)
+			return
+		}
 		for i := 0; i <= 2; i++ {
-			r.Error = r.streamQuery(exportSQL)
+			r.Error = r.streamQuery(exportSQL, hosts)
 			if retryableError(r.Error) {
-				c.error("Retrying...")
+				c.errorf("Retrying after command %d...", c.lastCommandID())
 				r.Error = nil
 				continue
 			}
@@ -175,21 +269,22 @@ type Rows struct {
 	Pool      *sync.Pool // Use this to return the []bytes
 	Error     error
 
-	conn  *Conn
-	proxy *Proxy
-	stop  chan bool
-	wg    sync.WaitGroup
+	conn     *Conn
+	proxies  []*Proxy
+	stop     chan bool // Closed to stop all the proxies
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+	gzip     bool
+	bc       *BulkConf
+	counters []*csvCounter // One per proxy
 }
 
 func (r *Rows) Close() {
 	origCfg := r.conn.Conf.SuppressError
-	if r.proxy.IsRunning() {
+	if r.isRunning() {
 		// Suppress errors from forcing it to stop
 		r.conn.Conf.SuppressError = true
-		select {
-		case r.stop <- true:
-		default:
-		}
+		r.stopOnce.Do(func() { close(r.stop) })
 	}
 	r.wg.Wait()
 	r.conn.Conf.SuppressError = origCfg
@@ -197,30 +292,70 @@ func (r *Rows) Close() {
 
 /*--- Private Routines ---*/
 
-func (r *Rows) streamQuery(exportSQL string) error {
-	proxy, receiver, err := r.conn.initProxy(exportSQL)
+func (r *Rows) isRunning() bool {
+	for _, p := range r.proxies {
+		if p.IsRunning() {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rows) streamQuery(exportSQL string, hosts []string) error {
+	proxies, receiver, err := r.conn.initProxies(exportSQL, hosts)
 	if err != nil {
 		return err
 	}
-	r.proxy = proxy
-	defer r.proxy.Shutdown()
+	r.proxies = proxies
+	defer shutdownProxies(proxies)
+	for _, p := range proxies {
+		p.limiter = r.bc.bytesLimiter
+	}
+	r.counters = make([]*csvCounter, len(proxies))
+	for i := range r.counters {
+		r.counters[i] = &csvCounter{quote: r.bc.CSV.quote()}
+	}
 
 	dataErr := make(chan error, 1)
 	respErr := make(chan error, 1)
 	go func() {
-		// This is a blocking reader of the CSV data
-		r.BytesRead, err = r.proxy.Read(r.Data, r.stop)
-		dataErr <- err
+		// These are blocking readers of the CSV data. With multiple
+		// proxies each one's data is passed on a whole record at a time
+		// so that records from different nodes don't interleave.
+		var wg sync.WaitGroup
+		errs := make([]error, len(proxies))
+		for i, proxy := range proxies {
+			wg.Add(1)
+			go func(i int, proxy *Proxy) {
+				defer wg.Done()
+				out, counted := r.tap(i)
+				var n int64
+				if r.gzip {
+					n, errs[i] = r.readGzip(proxy, out)
+				} else {
+					n, errs[i] = proxy.Read(out, r.stop)
+				}
+				counted()
+				atomic.AddInt64(&r.BytesRead, n)
+			}(i, proxy)
+		}
+		wg.Wait()
+		dataErr <- firstError(errs)
 	}()
+	var rowCount int64
 	go func() {
 		// This returns the result of the EXPORT query
-		err := receiver(&response{})
+		res := &execRes{}
+		err := receiver(res)
+		if err == nil && res.ResponseData != nil && len(res.ResponseData.Results) > 0 {
+			atomic.StoreInt64(&rowCount, res.ResponseData.Results[0].RowCount)
+		}
 		respErr <- err
 	}()
 
 	timeout := make(<-chan time.Time)
-	if r.conn.Conf.QueryTimeout.Seconds() > 0 {
-		timeout = time.After(r.conn.Conf.QueryTimeout)
+	if queryTimeout := r.conn.QueryTimeout(); queryTimeout > 0 {
+		timeout = time.After(queryTimeout)
 	}
 
 	select {
@@ -236,72 +371,381 @@ func (r *Rows) streamQuery(exportSQL string) error {
 		err = errors.New("Timed out doing BulkQuery")
 	}
 
+	if err == nil && r.bc.Verify && !r.stopped() {
+		expected := atomic.LoadInt64(&rowCount)
+		if r.bc.WithColumnNames {
+			expected += int64(len(proxies)) // A header per file
+		}
+		var received int64
+		for _, cc := range r.counters {
+			received += cc.total()
+		}
+		err = verifyCount("export", 0, received, expected)
+	}
+
 	// If we purposefully prematurely closed the connection
 	// we don't want to raise any errors.
 	if err != nil {
-		r.conn.errorf("Unable to bulk export data: %s %s", exportSQL, err)
+		r.conn.errorf("Unable to bulk export data: %s %w", exportSQL, err)
 	}
 
 	return err
 }
 
-func (c *Conn) streamExecuteNoRetry(origSQL string, data <-chan []byte) (
-	bytesWritten int64, err error,
+// Returns the channel the i-th proxy's data should be sent to. When
+// there are multiple proxies, or when verifying or throttling rows,
+// the data passes through a goroutine on its way to r.Data in which
+// case the returned func must be called once the proxy is done.
+func (r *Rows) tap(i int) (chan<- []byte, func()) {
+	parallel := len(r.proxies) > 1
+	if !parallel && !r.bc.Verify && r.bc.rowsLimiter == nil {
+		return r.Data, func() {}
+	}
+	cc := r.counters[i]
+	in := make(chan []byte, 1)
+	done := make(chan bool)
+	go func() {
+		defer close(done)
+		var ra *recordAligner
+		if parallel {
+			ra = &recordAligner{quote: cc.quote}
+		}
+		send := func(b []byte) {
+			select {
+			case r.Data <- b:
+			case <-r.stop:
+			}
+		}
+		for b := range in {
+			before := cc.rows
+			cc.add(b)
+			r.bc.rowsLimiter.wait(cc.rows-before, r.stop)
+			if ra != nil {
+				b = ra.add(b)
+			}
+			if len(b) > 0 {
+				send(b)
+			}
+		}
+		if ra != nil && len(ra.partial) > 0 {
+			send(ra.partial) // A final record without a trailing newline
+		}
+	}()
+	return in, func() {
+		close(in)
+		<-done
+	}
+}
+
+// Holds back any trailing partial record of each chunk until
+// the rest of it arrives
+type recordAligner struct {
+	quote   byte
+	inQuote bool
+	partial []byte
+}
+
+// Takes ownership of b returning the whole records to pass on, if any
+func (ra *recordAligner) add(b []byte) []byte {
+	end := 0
+	for i, ch := range b {
+		switch ch {
+		case ra.quote:
+			ra.inQuote = !ra.inQuote
+		case '\n':
+			if !ra.inQuote {
+				end = i + 1
+			}
+		}
+	}
+	if end == 0 {
+		ra.partial = append(ra.partial, b...)
+		bufPool.Put(b[:cap(b)])
+		return nil
+	}
+	if len(ra.partial) == 0 {
+		ra.partial = append(ra.partial, b[end:]...)
+		return b[:end]
+	}
+	out := append(ra.partial, b[:end]...)
+	ra.partial = append([]byte(nil), b[end:]...)
+	bufPool.Put(b[:cap(b)])
+	return out
+}
+
+func (r *Rows) stopped() bool {
+	select {
+	case <-r.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// The compressed data is read from the proxy and
+// decompressed into bufPool slices sent to out
+func (r *Rows) readGzip(proxy *Proxy, out chan<- []byte) (int64, error) {
+	raw := make(chan []byte, 1)
+	pr, pw := io.Pipe()
+	go func() {
+		for b := range raw {
+			pw.Write(b)
+			bufPool.Put(b[:cap(b)])
+		}
+		pw.Close()
+	}()
+
+	readErr := make(chan error, 1)
+	go func() {
+		gz, err := gzip.NewReader(pr)
+		if err == nil {
+			err = readChunks(gz, out, r.stop)
+		}
+		// Unblock the pipe writer if we bailed early
+		pr.CloseWithError(err)
+		readErr <- err
+	}()
+
+	bytesRead, err := proxy.Read(raw, r.stop)
+	close(raw)
+	if e := <-readErr; err == nil && e != io.EOF {
+		err = e
+	}
+	return bytesRead, err
+}
+
+func readChunks(rdr io.Reader, data chan<- []byte, stop <-chan bool) error {
+	for {
+		chunk := bufPool.Get().([]byte)
+		chunk = chunk[:cap(chunk)]
+		n, err := io.ReadFull(rdr, chunk)
+		if n > 0 {
+			select {
+			case <-stop:
+				return nil
+			case data <- chunk[:n]:
+			}
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+var errGzipAborted = errors.New("The compressed data is no longer being read")
+
+// Compresses the data as it passes through until done is closed.
+// The returned func reports any compression error once out is closed.
+// After an error the rest of the data is discarded.
+func gzipChan(data <-chan []byte, done <-chan struct{}) (<-chan []byte, func() error) {
+	out := make(chan []byte, 1)
+	var err error
+	go func() {
+		defer close(out)
+		gz := gzip.NewWriter(&chanWriter{out, done})
+		for b := range data {
+			if err == nil {
+				_, err = gz.Write(b)
+			}
+		}
+		if err == nil {
+			err = gz.Close()
+		}
+		if err != nil {
+			err = fmt.Errorf("Unable to gzip data: %w", err)
+		}
+	}()
+	return out, func() error { return err }
+}
+
+type chanWriter struct {
+	out  chan<- []byte
+	done <-chan struct{}
+}
+
+func (w *chanWriter) Write(b []byte) (int, error) {
+	// The gzip writer reuses its buffer so we need a copy
+	select {
+	case w.out <- append([]byte(nil), b...):
+		return len(b), nil
+	case <-w.done:
+		return 0, errGzipAborted
+	}
+}
+
+func isGzipSQL(sql string) bool {
+	return regexp.MustCompile(`(?i)\bFILE\s+'[^']*\.gz'`).MatchString(sql)
+}
+
+// Splits the CSV stream into n streams on record boundaries
+// so that it can be uploaded via n proxies in parallel.
+func shardCSV(data <-chan []byte, n int, quote byte, done <-chan struct{}) []<-chan []byte {
+	shards := make([]chan []byte, n)
+	ret := make([]<-chan []byte, n)
+	for i := range shards {
+		shards[i] = make(chan []byte, 1)
+		ret[i] = shards[i]
+	}
+
+	go func() {
+		defer func() {
+			for _, shard := range shards {
+				close(shard)
+			}
+		}()
+		var carry []byte
+		scanned := 0
+		inQuote := false
+		next := 0
+		for b := range data {
+			carry = append(carry, b...)
+			cut := -1
+			for i := scanned; i < len(carry); i++ {
+				switch carry[i] {
+				case quote:
+					inQuote = !inQuote
+				case '\n':
+					if !inQuote {
+						cut = i
+					}
+				}
+			}
+			scanned = len(carry)
+			if cut >= 0 {
+				select {
+				case shards[next] <- carry[:cut+1]:
+				case <-done:
+					return
+				}
+				next = (next + 1) % n
+				carry = append([]byte(nil), carry[cut+1:]...)
+				scanned = len(carry)
+			}
+		}
+		if len(carry) > 0 {
+			select {
+			case shards[next] <- carry:
+			case <-done:
+			}
+		}
+	}()
+
+	return ret
+}
+
+func (c *Conn) streamExecuteNoRetry(origSQL string, hosts []string, shards []<-chan []byte, limiter *rateLimiter) (
+	bytesWritten, rowCount int64, err error,
 ) {
-	proxy, receiver, err := c.initProxy(origSQL)
+	proxies, receiver, err := c.initProxies(origSQL, hosts)
 	if err != nil {
-		return 0, fmt.Errorf("Unable to import or export data: %s\n%s", origSQL, err)
+		return 0, 0, fmt.Errorf("Unable to import or export data: %s\n%w", origSQL, err)
 	}
-	defer proxy.Shutdown()
+	defer shutdownProxies(proxies)
+	for _, p := range proxies {
+		p.limiter = limiter
+	}
+
+	// Stops the other writers if one fails. The shards aren't drained
+	// so that anything not yet read is still there to retry with.
+	stop := make(chan struct{})
+	var stopOnce sync.Once
+	stopWriters := func() { stopOnce.Do(func() { close(stop) }) }
 
 	dataErr := make(chan error, 1)
 	respErr := make(chan error, 1)
 	go func() {
-		// This is a blocking writer of the CSV data
-		var e error
-		bytesWritten, e = proxy.Write(data)
-		dataErr <- e
+		// These are blocking writers of the CSV data
+		var wg sync.WaitGroup
+		errs := make([]error, len(proxies))
+		for i, proxy := range proxies {
+			wg.Add(1)
+			go func(i int, proxy *Proxy) {
+				defer wg.Done()
+				n, err := proxy.write(shards[i], stop)
+				atomic.AddInt64(&bytesWritten, n)
+				if err != nil && err != errProxyStopped {
+					errs[i] = err
+					stopWriters()
+				}
+			}(i, proxy)
+		}
+		wg.Wait()
+		dataErr <- firstError(errs)
 	}()
 	go func() {
 		// This returns the result of the IMPORT query
-		e := receiver(&response{})
+		res := &execRes{}
+		e := receiver(res)
+		if e == nil && res.ResponseData != nil && len(res.ResponseData.Results) > 0 {
+			atomic.StoreInt64(&rowCount, res.ResponseData.Results[0].RowCount)
+		}
 		respErr <- e
 	}()
 
 	timeout := make(<-chan time.Time)
-	if c.Conf.QueryTimeout.Seconds() > 0 {
-		timeout = time.After(c.Conf.QueryTimeout)
+	if queryTimeout := c.QueryTimeout(); queryTimeout > 0 {
+		timeout = time.After(queryTimeout)
 	}
 
+	writersDone := false
 	select {
 	case err = <-dataErr:
+		writersDone = true
 		if err == nil {
 			err = <-respErr
 		}
 	case err = <-respErr:
 		if err == nil {
 			err = <-dataErr
+			writersDone = true
 		}
 	case <-timeout:
 		err = fmt.Errorf("Timed out doing StreamExecute")
 	}
+	if !writersDone {
+		// Makes sure nothing more is read from the shards
+		stopWriters()
+		shutdownProxies(proxies)
+		<-dataErr
+	}
 
 	if err != nil {
-		err = fmt.Errorf("Unable to import or export data: %s\n%s", origSQL, err)
+		err = fmt.Errorf("Unable to import or export data: %s\n%w", origSQL, err)
 	}
 
-	return bytesWritten, err
+	return atomic.LoadInt64(&bytesWritten), atomic.LoadInt64(&rowCount), err
 }
 
-func (c *Conn) initProxy(sql string) (*Proxy, func(interface{}) error, error) {
-	proxy, err := NewProxy(c.Conf.Host, c.Conf.Port, &bufPool, c.log)
+// Sets up a proxy on each of the hosts and then sends the SQL
+// with the proxy URLs filled in. The returned func receives the response.
+func (c *Conn) initProxies(sql string, hosts []string) ([]*Proxy, func(interface{}) error, error) {
+	if err := c.checkOpen(); err != nil {
+		return nil, nil, err
+	}
+	if err := c.checkReadOnly(sql); err != nil {
+		return nil, nil, err
+	}
+	sql, err := expandProxySQL(sql, len(hosts))
 	if err != nil {
 		c.error(err.Error())
 		return nil, nil, err
 	}
 
-	proxyURL := fmt.Sprintf("http://%s:%d", proxy.Host, proxy.Port)
-	sql = fmt.Sprintf(sql, proxyURL)
+	proxies := []*Proxy{}
+	proxyURLs := []interface{}{}
+	for _, host := range hosts {
+		proxy, err := NewProxy(host, c.Conf.Port, &bufPool, c.log)
+		if err != nil {
+			c.error(err.Error())
+			shutdownProxies(proxies)
+			return nil, nil, err
+		}
+		proxies = append(proxies, proxy)
+		proxyURLs = append(proxyURLs, "http://"+net.JoinHostPort(proxy.Host, strconv.Itoa(int(proxy.Port))))
+	}
+	sql = fmt.Sprintf(sql, proxyURLs...)
 
 	req := &execReq{
 		Command: "execute",
@@ -310,12 +754,91 @@ func (c *Conn) initProxy(sql string) (*Proxy, func(interface{}) error, error) {
 	c.log.Debug("Stream sql: ", sql)
 	receiver, err := c.asyncSend(req)
 	if err != nil {
-		c.errorf("Unable to stream sql: %s %s", sql, err)
-		proxy.Shutdown()
+		c.errorf("Unable to stream sql: %s %w", sql, err)
+		shutdownProxies(proxies)
 		return nil, nil, err
 	}
 
-	return proxy, receiver, nil
+	return proxies, receiver, nil
+}
+
+func shutdownProxies(proxies []*Proxy) {
+	for _, p := range proxies {
+		p.Shutdown()
+	}
+}
+
+// Returns the hosts to set up proxies on. For parallel transfers
+// this is up to n of the cluster's nodes.
+func (c *Conn) bulkHosts(n int) ([]string, error) {
+	if n <= 1 {
+		return []string{c.host}, nil
+	}
+	hosts, err := c.getHosts()
+	if err != nil {
+		return nil, err
+	}
+	rand.Shuffle(len(hosts), func(i, j int) { hosts[i], hosts[j] = hosts[j], hosts[i] })
+	if len(hosts) > n {
+		hosts = hosts[:n]
+	}
+	return hosts, nil
+}
+
+func (c *Conn) getHosts() ([]string, error) {
+	hostIP := c.host
+	if net.ParseIP(hostIP) == nil {
+		ips, err := net.LookupHost(hostIP)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to resolve %s: %w", hostIP, err)
+		}
+		hostIP = ips[0]
+	}
+	req := &getHostsReq{
+		Command: "getHosts",
+		HostIP:  hostIP,
+	}
+	res := &getHostsRes{}
+	err := c.send(req, res)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to get cluster hosts: %w", err)
+	}
+	if len(res.ResponseData.Nodes) == 0 {
+		return []string{c.host}, nil
+	}
+	return res.ResponseData.Nodes, nil
+}
+
+// For parallel transfers the AT '%s' FILE '...' clause
+// is repeated once per proxy with unique file names
+func expandProxySQL(sql string, n int) (string, error) {
+	if n <= 1 {
+		return sql, nil
+	}
+	re := regexp.MustCompile(`(?i)\bAT\s+'%s'\s+FILE\s+'([^']*)'`)
+	m := re.FindStringSubmatchIndex(sql)
+	if m == nil {
+		return "", errors.New("Parallel transfers require an AT '%s' FILE '...' clause")
+	}
+	file := sql[m[2]:m[3]]
+	base, ext := file, ""
+	if i := strings.Index(file, "."); i >= 0 {
+		base, ext = file[:i], file[i:]
+	}
+	clauses := make([]string, n)
+	for i := range clauses {
+		clauses[i] = fmt.Sprintf("AT '%%s' FILE '%s-%03d%s'", base, i+1, ext)
+	}
+	return sql[:m[0]] + strings.Join(clauses, " ") + sql[m[1]:], nil
+}
+
+func firstError(errs []error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func retryableError(err error) bool {
@@ -327,16 +850,144 @@ func retryableError(err error) bool {
 	return false
 }
 
-func (c *Conn) getTableImportSQL(schema, table string) string {
+func (c *Conn) getTableImportSQL(schema, table string, bc *BulkConf) (string, error) {
+	target := c.QuoteIdent(schema) + "." + c.QuoteIdent(table)
+	if len(bc.Mapping) == 0 {
+		sql := fmt.Sprintf("IMPORT INTO %s FROM CSV AT '%%s' FILE '%s'", target, bulkFileName(bc))
+		return strings.Join(append([]string{sql}, bc.CSV.importOpts()...), " "), nil
+	}
+
+	cols := make([]string, len(bc.Mapping))
+	fileCols := make([]string, len(bc.Mapping))
+	hasExpr := false
+	for i, m := range bc.Mapping {
+		if m.FileColumn < 1 || m.Column == "" {
+			return "", fmt.Errorf("Invalid column mapping: %+v", m)
+		}
+		cols[i] = c.QuoteIdent(m.Column)
+		fileCols[i] = strconv.Itoa(m.FileColumn)
+		if m.Format != "" {
+			fileCols[i] += " FORMAT = '" + QuoteStr(m.Format) + "'"
+		}
+		hasExpr = hasExpr || m.Expr != ""
+	}
+	src := fmt.Sprintf("FROM CSV AT '%%s' FILE '%s' (%s)", bulkFileName(bc), strings.Join(fileCols, ", "))
+	src = strings.Join(append([]string{src}, bc.CSV.importOpts()...), " ")
+	if !hasExpr {
+		return fmt.Sprintf("IMPORT INTO %s (%s) %s", target, strings.Join(cols, ", "), src), nil
+	}
+
+	// Expressions require importing into a subselect of strings
+	if bc.ErrorsInto != "" || bc.RejectLimit != 0 {
+		return "", errors.New("Column mapping expressions can't be combined with ErrorsInto or RejectLimit")
+	}
+	vcols := make([]string, len(bc.Mapping))
+	exprs := make([]string, len(bc.Mapping))
+	for i, m := range bc.Mapping {
+		vcol := fmt.Sprintf("c%d", i+1)
+		vcols[i] = vcol + " VARCHAR(2000000)"
+		exprs[i] = vcol
+		if m.Expr != "" {
+			exprs[i] = replacePlaceholders(m.Expr, vcol)
+		}
+	}
 	return fmt.Sprintf(
-		"IMPORT INTO %s.%s FROM CSV AT '%%s' FILE 'data.csv'",
-		c.QuoteIdent(schema), c.QuoteIdent(table),
-	)
+		"INSERT INTO %s (%s) SELECT %s FROM (IMPORT INTO (%s) %s)",
+		target, strings.Join(cols, ", "), strings.Join(exprs, ", "), strings.Join(vcols, ", "), src,
+	), nil
 }
 
-func (c *Conn) getTableExportSQL(schema, table string) string {
-	return fmt.Sprintf(
-		"EXPORT %s.%s INTO CSV AT '%%s' FILE 'data.csv'",
-		c.QuoteIdent(schema), c.QuoteIdent(table),
+// Replaces the ? placeholders in an expression except within
+// string literals, quoted identifiers and comments
+func replacePlaceholders(expr, with string) string {
+	var sb strings.Builder
+	last := 0
+	for _, loc := range quotedOrComment.FindAllStringIndex(expr, -1) {
+		sb.WriteString(strings.ReplaceAll(expr[last:loc[0]], "?", with))
+		sb.WriteString(expr[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	sb.WriteString(strings.ReplaceAll(expr[last:], "?", with))
+	return sb.String()
+}
+
+func (c *Conn) getTableExportSQL(schema, table string, bc *BulkConf) string {
+	sql := fmt.Sprintf(
+		"EXPORT %s.%s INTO CSV AT '%%s' FILE '%s'",
+		c.QuoteIdent(schema), c.QuoteIdent(table), bulkFileName(bc),
 	)
+	opts := bc.CSV.commonOpts()
+	if bc.WithColumnNames {
+		opts = append(opts, "WITH COLUMN NAMES")
+	}
+	return strings.Join(append([]string{sql}, opts...), " ")
+}
+
+// Appends any ERRORS INTO / REJECT LIMIT clause unless the SQL has its own
+func withErrorClause(sql string, bc *BulkConf) (string, error) {
+	clause, err := errorClause(bc.ErrorsInto, bc.ErrorsMode, bc.RejectLimit)
+	if err != nil {
+		return "", err
+	}
+	if len(clause) == 0 ||
+		regexp.MustCompile(`(?i)\bERRORS\s+INTO\b|\bREJECT\s+LIMIT\b`).MatchString(sql) {
+		return sql, nil
+	}
+	return strings.TrimRight(sql, "; \t\n") + " " + strings.Join(clause, " "), nil
+}
+
+// The number of rows in the error table before the import
+// so that only the ones the import adds are counted
+func (c *Conn) countRejects(bc *BulkConf) int64 {
+	if bc.ErrorsInto == "" || bc.ErrorsMode != "" {
+		return 0 // Either not tracked or it's emptied by the import
+	}
+	n, err := c.rejectCount(bc.ErrorsInto)
+	if err != nil {
+		return 0 // It's presumably created by the import
+	}
+	return n
+}
+
+func (c *Conn) collectRejects(bc *BulkConf, before int64) {
+	if bc.ErrorsInto == "" {
+		return
+	}
+	n, err := c.rejectCount(bc.ErrorsInto)
+	if err != nil {
+		c.log.Warning("Unable to count rejected rows: ", err)
+		return
+	}
+	bc.Result.RowsRejected = n - before
+	if bc.Result.RowsRejected <= 0 {
+		return
+	}
+	// The error table has no insertion order so with an appended
+	// error table the sample may include earlier rejects
+	sample, err := c.FetchSlice(fmt.Sprintf(
+		"SELECT * FROM %s LIMIT %d", bc.ErrorsInto, rejectSampleSize,
+	))
+	if err != nil {
+		c.log.Warning("Unable to fetch rejected rows: ", err)
+		return
+	}
+	bc.Result.Rejected = sample
+}
+
+func (c *Conn) rejectCount(table string) (int64, error) {
+	res, err := c.FetchSlice("SELECT COUNT(*) FROM " + table)
+	if err != nil {
+		return 0, err
+	}
+	if len(res) != 1 {
+		return 0, fmt.Errorf("Unexpected COUNT(*) result: %v", res)
+	}
+	return FetchResult{Data: res[0]}.Int64(0)
+}
+
+func bulkFileName(bc *BulkConf) string {
+	if bc.Gzip {
+		return "data.csv.gz"
+	}
+	return "data.csv"
 }
@@ -2,7 +2,11 @@ package exasol
 
 import (
 	"bytes"
+	"encoding/csv"
 	"fmt"
+	"net"
+	"strings"
+	"time"
 )
 
 func (s *testSuite) TestBulkInsert() {
@@ -107,6 +111,101 @@ func (s *testSuite) TestBulkQuery() {
 	}
 }
 
+func (s *testSuite) TestBulkGzip() {
+	exa := s.exaConn
+	exa.Execute("CREATE TABLE foo ( id INT, val CHAR(1) )")
+
+	data := bytes.NewBufferString("1,a\n2,b\n3,c\n")
+	err := exa.BulkInsert(s.qschema, "FOO", data, WithGzip())
+	s.Nil(err)
+
+	got := &bytes.Buffer{}
+	err = exa.BulkSelect(s.qschema, "FOO", got, WithGzip())
+	if s.NoError(err) {
+		s.Equal("1,a\n2,b\n3,c\n", got.String())
+	}
+
+	// The .gz file name alone is enough
+	got.Reset()
+	err = exa.BulkQuery(
+		"EXPORT (SELECT * FROM foo WHERE id > 1 ORDER BY id) INTO CSV AT '%s' FILE 'data.csv.gz'",
+		got,
+	)
+	if s.NoError(err) {
+		s.Equal("2,b\n3,c\n", got.String())
+	}
+}
+
+func (s *testSuite) TestBulkParallel() {
+	exa := s.exaConn
+	exa.Execute("CREATE TABLE foo ( id INT, val VARCHAR(10) )")
+
+	data := &bytes.Buffer{}
+	for i := 1; i <= 1000; i++ {
+		fmt.Fprintf(data, "%d,\"line\n%d\"\n", i, i)
+	}
+	err := exa.BulkInsert(s.qschema, "FOO", data, WithParallelism(4))
+	s.Nil(err)
+
+	got, err := exa.FetchSlice("SELECT COUNT(*), SUM(id) FROM foo WHERE val LIKE 'line_%'")
+	if s.NoError(err) {
+		s.Equal([]interface{}{float64(1000), float64(500500)}, got[0])
+	}
+
+	for _, opts := range [][]BulkOption{{WithParallelism(4)}, {WithParallelism(4), WithGzip()}} {
+		out := &bytes.Buffer{}
+		err = exa.BulkSelect(s.qschema, "FOO", out, opts...)
+		s.Require().NoError(err)
+		s.Equal(2000, strings.Count(out.String(), "\n"))
+
+		// Records from different nodes mustn't interleave
+		records, err := csv.NewReader(out).ReadAll()
+		s.Require().NoError(err)
+		s.Len(records, 1000)
+		seen := map[string]bool{}
+		for _, rec := range records {
+			s.Require().Len(rec, 2)
+			s.Equal("line\n"+rec[0], rec[1])
+			seen[rec[0]] = true
+		}
+		s.Len(seen, 1000)
+	}
+
+	sql, err := expandProxySQL("IMPORT INTO t FROM CSV AT '%s' FILE 'data.csv.gz' SKIP=1", 2)
+	s.Nil(err)
+	s.Equal("IMPORT INTO t FROM CSV AT '%s' FILE 'data-001.csv.gz' AT '%s' FILE 'data-002.csv.gz' SKIP=1", sql)
+}
+
+func (s *testSuite) TestBulkCommitEvery() {
+	exa := s.exaConn
+	exa.Execute("CREATE TABLE foo ( id INT, val CHAR(1) )")
+
+	progress := &BulkProgress{}
+	data := bytes.NewBufferString("1,a\n2,b\n3,c\n4,d\n5,e")
+	err := exa.BulkInsert(s.qschema, "FOO", data, WithCommitEvery(2, progress))
+	s.Nil(err)
+	s.Equal(int64(5), progress.RowsCommitted)
+	s.Equal(3, progress.ChunksCommitted)
+
+	// A failure part way through leaves the earlier chunks committed
+	exa.Execute("TRUNCATE TABLE foo")
+	exa.Conf.SuppressError = true
+	progress = &BulkProgress{}
+	data = bytes.NewBufferString("1,a\n2,b\n3,c\nx,d\n5,e\n")
+	err = exa.BulkInsert(s.qschema, "FOO", data, WithCommitEvery(2, progress))
+	if s.Error(err) {
+		s.Contains(err.Error(), "2 rows were committed")
+	}
+	s.Equal(int64(2), progress.RowsCommitted)
+	s.Equal(1, progress.ChunksCommitted)
+
+	exa.Rollback()
+	got, err := exa.FetchSlice("SELECT id FROM foo ORDER BY id")
+	if s.NoError(err) {
+		s.Equal([][]interface{}{{float64(1)}, {float64(2)}}, got)
+	}
+}
+
 func (s *testSuite) TestStreamInsert() {
 	s.execute(`CREATE TABLE foo ( id INT, val VARCHAR(10) )`)
 	numRows := 1000
@@ -221,3 +320,179 @@ func (s *testSuite) TestStreamQuery() {
 	s.Equal("2\x002\x00\n1\x001\x00\n", csv[len(csv)-10:], "End ok")
 	s.Equal(int64(4277790), rows.BytesRead)
 }
+
+func (s *testSuite) TestBulkInsertRejects() {
+	exa := s.exaConn
+	s.execute("CREATE TABLE foo ( id INT, val CHAR(1) )")
+	s.exaConn.Conf.SuppressError = true
+
+	data := bytes.NewBufferString("1,a\nx,b\n3,c\n4,dd\n5,e\n")
+	res := &BulkResult{}
+	err := exa.BulkInsert(s.qschema, "FOO", data,
+		WithErrorsInto(s.qschema+".foo_errors", "REPLACE"),
+		WithRejectLimit(5),
+		WithBulkResult(res),
+	)
+	s.Require().NoError(err)
+	s.Equal(int64(3), res.RowsImported)
+	s.Equal(int64(2), res.RowsRejected)
+	s.Len(res.Rejected, 2)
+
+	// Exceeding the reject limit fails the import
+	data = bytes.NewBufferString("x,a\ny,b\n3,c\n")
+	err = exa.BulkInsert(s.qschema, "FOO", data, WithRejectLimit(1))
+	s.Error(err)
+
+	data = bytes.NewBufferString("6,f\n")
+	err = exa.BulkInsert(s.qschema, "FOO", data,
+		WithErrorsInto(s.qschema+".foo_errors", "APPEND"),
+	)
+	if s.Error(err) {
+		s.Contains(err.Error(), "Invalid ErrorsMode")
+	}
+}
+
+func (s *testSuite) TestBulkCSVFormat() {
+	exa := s.exaConn
+	s.execute("CREATE TABLE foo ( id INT, val VARCHAR(10) )")
+
+	format := CSVFormat{
+		ColumnSeparator: ";",
+		ColumnDelimiter: "'",
+		Null:            "NULL",
+		Skip:            1,
+		Trim:            "trim",
+	}
+	data := bytes.NewBufferString("id;val\n1; 'a;b' \n2;NULL\n3;'it''s'\n")
+	err := exa.BulkInsert(s.qschema, "FOO", data, WithCSVFormat(format))
+	s.Require().NoError(err)
+
+	got, err := exa.FetchSlice("SELECT * FROM foo ORDER BY id")
+	s.Require().NoError(err)
+	s.Equal([][]interface{}{
+		{float64(1), "a;b"},
+		{float64(2), nil},
+		{float64(3), "it's"},
+	}, got)
+
+	out := &bytes.Buffer{}
+	format.Skip, format.Trim = 0, ""
+	err = exa.BulkSelect(s.qschema, "FOO", out, WithCSVFormat(format), WithColumnNames())
+	s.Require().NoError(err)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	s.Equal("ID;VAL", lines[0])
+	s.Contains(lines, "2;NULL")
+
+	// Skip would drop rows from every shard
+	err = exa.BulkInsert(s.qschema, "FOO", data, WithCSVFormat(CSVFormat{Skip: 1}), WithParallelism(2))
+	s.Error(err)
+}
+
+func (s *testSuite) TestBulkInsertColumnMapping() {
+	exa := s.exaConn
+	s.execute("CREATE TABLE foo ( id INT, day DATE, val VARCHAR(10) )")
+
+	// Reordered and skipped file columns with a format
+	data := bytes.NewBufferString("x,20200102,1\ny,20200103,2\n")
+	err := exa.BulkInsert(s.qschema, "FOO", data, WithColumnMapping(
+		ColumnMapping{FileColumn: 3, Column: "id"},
+		ColumnMapping{FileColumn: 2, Column: "day", Format: "YYYYMMDD"},
+	))
+	s.Require().NoError(err)
+
+	// Expressions
+	data = bytes.NewBufferString("3,20200104,z\n")
+	err = exa.BulkInsert(s.qschema, "FOO", data, WithColumnMapping(
+		ColumnMapping{FileColumn: 1, Column: "id", Expr: "CAST(? AS INT) * 10"},
+		ColumnMapping{FileColumn: 2, Column: "day", Expr: "TO_DATE(?, 'YYYYMMDD')"},
+		ColumnMapping{FileColumn: 3, Column: "val", Expr: "UPPER(?) || '?' /* ? */"},
+	))
+	s.Require().NoError(err)
+
+	got, err := exa.FetchSlice("SELECT id, TO_CHAR(day, 'YYYY-MM-DD'), val FROM foo ORDER BY id")
+	s.Require().NoError(err)
+	s.Equal([][]interface{}{
+		{float64(1), "2020-01-02", nil},
+		{float64(2), "2020-01-03", nil},
+		{float64(30), "2020-01-04", "Z?"},
+	}, got)
+
+	err = exa.BulkInsert(s.qschema, "FOO", data, WithColumnMapping(ColumnMapping{Column: "id"}))
+	s.Error(err)
+}
+
+func (s *testSuite) TestBulkVerify() {
+	exa := s.exaConn
+	s.execute("CREATE TABLE foo ( id INT, val VARCHAR(10) )")
+
+	data := bytes.NewBufferString("1,\"a\nb\"\n2,b\n3,c")
+	err := exa.BulkInsert(s.qschema, "FOO", data, WithVerify())
+	s.Require().NoError(err)
+
+	data = bytes.NewBufferString("4,d\n5,e\n6,f\n")
+	err = exa.BulkInsert(s.qschema, "FOO", data, WithVerify(), WithCommitEvery(2, nil))
+	s.Require().NoError(err)
+
+	out := &bytes.Buffer{}
+	err = exa.BulkSelect(s.qschema, "FOO", out, WithVerify(), WithColumnNames())
+	s.Require().NoError(err)
+	s.Equal(8, strings.Count(out.String(), "\n"), "Header, embedded newline and 6 rows")
+
+	// The counts can't be verified with rejected rows
+	err = exa.BulkInsert(s.qschema, "FOO", data, WithVerify(), WithRejectLimit(1))
+	s.Error(err)
+}
+
+func (s *testSuite) TestBulkThrottle() {
+	exa := s.exaConn
+	s.execute("CREATE TABLE foo ( id INT )")
+
+	data := &bytes.Buffer{}
+	for i := 0; i < 20; i++ {
+		fmt.Fprintf(data, "%d\n", i)
+	}
+	// A second's burst and then another second for the rest
+	start := time.Now()
+	err := exa.BulkInsert(s.qschema, "FOO", data, WithThrottle(0, 10))
+	s.Require().NoError(err)
+	s.True(time.Since(start) >= 900*time.Millisecond, "Throttled")
+
+	out := &bytes.Buffer{}
+	start = time.Now()
+	err = exa.BulkSelect(s.qschema, "FOO", out, WithThrottle(25, 0)) // 50 bytes
+	s.Require().NoError(err)
+	s.True(time.Since(start) >= 900*time.Millisecond, "Throttled")
+	s.Equal(20, strings.Count(out.String(), "\n"))
+}
+
+func (s *testSuite) TestProxyWriteStopped() {
+	client, server := net.Pipe()
+	defer server.Close()
+	p := &Proxy{conn: client, running: true, log: newDefaultLogger()}
+	go func() {
+		server.Write([]byte("PUT / HTTP/1.1\r\n\r\n"))
+		buf := make([]byte, 1024)
+		for {
+			if _, err := server.Read(buf); err != nil {
+				return
+			}
+		}
+	}()
+
+	data := make(chan []byte, 2)
+	data <- []byte("1,a\n")
+	stop := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		_, err := p.write(data, stop)
+		done <- err
+	}()
+	for len(data) > 0 {
+		time.Sleep(time.Millisecond)
+	}
+	close(stop)
+	s.Equal(errProxyStopped, <-done)
+	data <- []byte("2,b\n")
+	s.Equal("2,b\n", string(<-data), "Nothing more was read once stopped")
+	p.Shutdown()
+}
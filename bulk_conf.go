@@ -0,0 +1,176 @@
+/*
+	The Bulk* and Stream* routines accept functional options
+	which are applied to a BulkConf e.g.
+
+	    conn.BulkInsert(schema, table, data, exasol.WithGzip())
+
+
+	AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+type BulkConf struct {
+	// Compress the CSV data on the fly. This typically cuts transfer
+	// time over WAN links substantially. When you provide your own
+	// IMPORT/EXPORT SQL the FILE name must end in .gz (this is
+	// detected automatically so the option is then optional).
+	Gzip bool
+	// Sets up a proxy on up to this many cluster nodes and transfers
+	// the data through all of them in parallel. Imported data is split
+	// on record boundaries and exported data is concatenated (so the
+	// order of the exported rows is not preserved).
+	Parallelism int
+	// Splits the import into separate IMPORT statements of this many rows
+	// each committed before the next is started. See batch.go
+	CommitEvery int
+	// Optional. Updated as each chunk is committed.
+	Progress *BulkProgress
+	// Optional. Called with the progress after each chunk is committed.
+	// Returning an error aborts the import.
+	Checkpoint func(BulkProgress) error
+	// Skip the rows of the data already committed according to Progress
+	Resume bool
+	// Optional rejected rows handling for imports. ErrorsInto is a table
+	// and ErrorsMode is either REPLACE or TRUNCATE (or empty to append).
+	// RejectLimit is zero for none, negative for UNLIMITED.
+	ErrorsInto  string
+	ErrorsMode  string
+	RejectLimit int
+	// Optional. Filled in once the import is done (even if it failed).
+	Result *BulkResult
+	// The CSV dialect used by BulkInsert/StreamInsert and
+	// BulkSelect/StreamSelect. When you provide your own IMPORT/EXPORT
+	// SQL include the corresponding clauses yourself but still pass the
+	// option if the enclosure character that quotes values isn't `"`
+	// (Exasol calls it the COLUMN DELIMITER hence CSVFormat.ColumnDelimiter)
+	// so that the data is split correctly for Parallelism and CommitEvery.
+	CSV CSVFormat
+	// Write a header row when exporting
+	WithColumnNames bool
+	// Compare the number of rows the client sent or received to the
+	// row count reported by the server failing with a *CountMismatchError
+	// if they differ. See verify.go
+	Verify bool
+	// Optional limits for the transfer. See throttle.go
+	BytesPerSec int64
+	RowsPerSec  int64
+	// Which CSV columns are imported into which of the table's columns
+	// for BulkInsert/StreamInsert. Defaults to all of them in order.
+	Mapping []ColumnMapping
+
+	bytesLimiter *rateLimiter // Shared by all the chunks of the transfer
+	rowsLimiter  *rateLimiter
+}
+
+type ColumnMapping struct {
+	FileColumn int    // 1-based
+	Column     string // The table column
+	// Optional format of a date/time/number value e.g. YYYYMMDD
+	Format string
+	// Optional SQL expression with a ? placeholder for the file's value
+	// e.g. TO_DATE(?, 'YYYYMMDD'). A ? within quotes or a comment is
+	// left as is. This imports via a subselect so it
+	// can't be combined with ErrorsInto or RejectLimit.
+	Expr string
+}
+
+type BulkResult struct {
+	RowsImported int64
+	// The number of rows added to the ErrorsInto table by the import
+	// and a sample of them. Only populated when ErrorsInto is given.
+	RowsRejected int64
+	Rejected     [][]interface{}
+}
+
+type BulkOption func(*BulkConf)
+
+func WithGzip() BulkOption {
+	return func(bc *BulkConf) { bc.Gzip = true }
+}
+
+func WithParallelism(n int) BulkOption {
+	return func(bc *BulkConf) { bc.Parallelism = n }
+}
+
+// Commits every n rows reporting the progress (which may be nil)
+func WithCommitEvery(n int, progress *BulkProgress) BulkOption {
+	return func(bc *BulkConf) {
+		bc.CommitEvery = n
+		bc.Progress = progress
+	}
+}
+
+// Calls fn after each chunk is committed (see WithCommitEvery)
+// e.g. to persist the progress for resuming
+func WithCheckpoint(fn func(BulkProgress) error) BulkOption {
+	return func(bc *BulkConf) { bc.Checkpoint = fn }
+}
+
+// Resumes an interrupted WithCommitEvery import by skipping
+// the rows already committed according to its progress
+func WithResume() BulkOption {
+	return func(bc *BulkConf) { bc.Resume = true }
+}
+
+// Rejected rows are written to the table (see BulkConf)
+func WithErrorsInto(table, mode string) BulkOption {
+	return func(bc *BulkConf) {
+		bc.ErrorsInto = table
+		bc.ErrorsMode = mode
+	}
+}
+
+// The import fails once more than n rows are rejected.
+// Negative means UNLIMITED.
+func WithRejectLimit(n int) BulkOption {
+	return func(bc *BulkConf) { bc.RejectLimit = n }
+}
+
+func WithBulkResult(res *BulkResult) BulkOption {
+	return func(bc *BulkConf) { bc.Result = res }
+}
+
+func WithCSVFormat(f CSVFormat) BulkOption {
+	return func(bc *BulkConf) { bc.CSV = f }
+}
+
+func WithColumnNames() BulkOption {
+	return func(bc *BulkConf) { bc.WithColumnNames = true }
+}
+
+// Zero means no limit
+func WithThrottle(bytesPerSec, rowsPerSec int64) BulkOption {
+	return func(bc *BulkConf) {
+		bc.BytesPerSec = bytesPerSec
+		bc.RowsPerSec = rowsPerSec
+	}
+}
+
+func WithVerify() BulkOption {
+	return func(bc *BulkConf) { bc.Verify = true }
+}
+
+func WithColumnMapping(mapping ...ColumnMapping) BulkOption {
+	return func(bc *BulkConf) { bc.Mapping = mapping }
+}
+
+/*--- Private Routines ---*/
+
+func newBulkConf(opts []BulkOption) *BulkConf {
+	bc := &BulkConf{}
+	for _, opt := range opts {
+		opt(bc)
+	}
+	bc.bytesLimiter = newRateLimiter(bc.BytesPerSec)
+	bc.rowsLimiter = newRateLimiter(bc.RowsPerSec)
+	return bc
+}
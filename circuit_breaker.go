@@ -0,0 +1,124 @@
+/*
+	An optional circuit breaker which, once a host has had Threshold
+	consecutive connection failures (including failed executes that
+	aren't errors reported by the server), fails fast with ErrCircuitOpen
+	for the CoolDown period. After that a single trial is let through
+	which either closes the circuit again or re-trips it.
+
+	Share one CircuitBreaker between all the connections to a cluster
+	so that they all benefit e.g. during maintenance windows:
+
+	    breaker := &exasol.CircuitBreaker{Threshold: 3, CoolDown: time.Minute}
+	    conf.CircuitBreaker = breaker
+
+
+	AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var ErrCircuitOpen = errors.New("Circuit breaker is open")
+
+type CircuitBreaker struct {
+	Threshold int           // Consecutive failures to trip. Defaults to 5.
+	CoolDown  time.Duration // How long to fail fast for. Defaults to 30s.
+
+	mux   sync.Mutex
+	hosts map[string]*circuit
+}
+
+// Whether the host's circuit is currently open
+func (cb *CircuitBreaker) IsOpen(host string) bool {
+	cb.mux.Lock()
+	defer cb.mux.Unlock()
+	h := cb.hosts[host]
+	return h != nil && time.Now().Before(h.openUntil)
+}
+
+/*--- Private Routines ---*/
+
+type circuit struct {
+	failures  int
+	openUntil time.Time
+	trial     bool // A trial request is in progress after the cool-down
+}
+
+func (cb *CircuitBreaker) allow(host string) error {
+	if cb == nil {
+		return nil
+	}
+	cb.mux.Lock()
+	defer cb.mux.Unlock()
+	h := cb.hosts[host]
+	if h == nil || h.openUntil.IsZero() {
+		return nil
+	}
+	if time.Now().Before(h.openUntil) || h.trial {
+		return fmt.Errorf("%w for %s", ErrCircuitOpen, host)
+	}
+	h.trial = true
+	return nil
+}
+
+func (cb *CircuitBreaker) record(host string, err error) {
+	if cb == nil || errors.Is(err, ErrCircuitOpen) {
+		return
+	}
+	cb.mux.Lock()
+	defer cb.mux.Unlock()
+	if cb.hosts == nil {
+		cb.hosts = map[string]*circuit{}
+	}
+	h := cb.hosts[host]
+	if h == nil {
+		h = &circuit{}
+		cb.hosts[host] = h
+	}
+	if !isCircuitFailure(err) {
+		*h = circuit{}
+		return
+	}
+	h.failures++
+	threshold := cb.Threshold
+	if threshold <= 0 {
+		threshold = 5
+	}
+	if h.trial || h.failures >= threshold {
+		coolDown := cb.CoolDown
+		if coolDown <= 0 {
+			coolDown = 30 * time.Second
+		}
+		h.openUntil = time.Now().Add(coolDown)
+		h.trial = false
+	}
+}
+
+// Errors reported by the server (e.g. SQL errors) show that it's up.
+// As do errors caused by the caller.
+func isCircuitFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	var exaErr *ExaError
+	var roErr *ReadOnlyError
+	return !errors.As(err, &exaErr) &&
+		!errors.As(err, &roErr) &&
+		!errors.Is(err, context.Canceled) &&
+		!errors.Is(err, ErrShutdown)
+}
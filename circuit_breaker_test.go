@@ -0,0 +1,36 @@
+package exasol
+
+import (
+	"errors"
+	"time"
+)
+
+func (s *testSuite) TestCircuitBreaker() {
+	breaker := &CircuitBreaker{Threshold: 2, CoolDown: time.Hour}
+	conf := s.connConf()
+	conf.SuppressError = true
+	conf.Host = "127.0.0.1"
+	conf.Port = 1 // Nothing listening
+	conf.CircuitBreaker = breaker
+
+	for i := 0; i < 2; i++ {
+		_, err := Connect(conf)
+		s.Error(err)
+		s.False(errors.Is(err, ErrCircuitOpen), "Not tripped yet")
+	}
+	s.True(breaker.IsOpen("127.0.0.1"))
+	_, err := Connect(conf)
+	s.True(errors.Is(err, ErrCircuitOpen), "Fails fast once tripped")
+
+	// Server errors don't count as failures
+	conf = s.connConf()
+	conf.SuppressError = true
+	conf.CircuitBreaker = &CircuitBreaker{Threshold: 1}
+	c, err := Connect(conf)
+	s.Require().NoError(err)
+	defer c.Disconnect()
+	_, err = c.Execute("ASDF")
+	s.Error(err)
+	_, err = c.Execute("SELECT 1")
+	s.NoError(err)
+}
@@ -22,19 +22,13 @@ package exasol
 
 import (
 	"context"
-	"crypto/rand"
-	"crypto/rsa"
 	"crypto/tls"
-	"encoding/base64"
-	"encoding/hex"
-	"fmt"
-	"math/big"
+	"errors"
+	"math"
+	"net/http"
 	"net/url"
-	"os/user"
-	"regexp"
-	"runtime"
-	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -48,18 +42,141 @@ type ConnConf struct {
 	Port           uint16
 	Username       string
 	Password       string
-	ClientName     string
+	Credentials    CredentialProvider // Optional alternative to Username/Password
+	ClientName     string             // Defaults to the main module path and version
 	ClientVersion  string
+	Schema         string // Optional default schema opened at login
 	ConnectTimeout time.Duration
 	QueryTimeout   time.Duration
+	MaxRows        uint64 // Optional server-side cap on the rows queries return
 	TLSConfig      *tls.Config
-	SuppressError  bool // Server errors are logged to Error by default
+	SuppressError  bool // Deprecated - Use OnServerError instead
 	// TODO try compressionEnabled: true
 	Logger         Logger    // Optional for better control over logging
 	WSHandler      WSHandler // Optional for intercepting websocket traffic
+	Codec          Codec     // Optional faster JSON encoding. See codec.go
 	CachePrepStmts bool
+	// The most statement handles that may be open at once (default 1000).
+	// Least recently used cached ones are closed to make room.
+	MaxOpenStmts int
+	// How many times a prepared statement whose handle the server no
+	// longer recognises (ErrStmtHandleNotFound) is re-prepared and
+	// retried. Defaults to once. Negative disables retrying.
+	StaleStmtRetries int
+
+	// Logs a warning (including the SQL) for each result set or uncached
+	// prepared statement that is still open when Disconnect is called.
+	WarnOnLeaks bool
+	// If a FetchChan consumer doesn't read anything for this long it's
+	// assumed to have stopped ranging early. A warning is logged and the
+	// result set is closed. Zero (the default) waits indefinitely.
+	// (This is based on inactivity because channels can't have finalizers
+	// and the fetching goroutine keeps the channel reachable anyway.)
+	AbandonedFetchTimeout time.Duration
+
+	// Optional callback for interactive tools which is called if no
+	// credentials were given or they were rejected by the server.
+	// The returned credentials are reused when reconnecting if KeepPassword is set.
+	PromptCredentials func(ctx context.Context) (user, pass string, err error)
+	// By default the password is cleared from the Conf once logged in
+	// so Reconnect then requires a CredentialProvider or PromptCredentials.
+	// (Go strings can't be zeroed so this only drops our reference to it.)
+	KeepPassword bool
+
+	// Optional context-aware alternative to WSHandler. Takes precedence.
+	WSHandlerV2 WSHandlerV2
+
+	// Defaults to ExasolAPIVersion. If the server rejects it progressively
+	// older versions are tried down to MinProtocolVersion (default 1).
+	// The version negotiated is available via Conn.ProtocolVersion.
+	ProtocolVersion    uint16
+	MinProtocolVersion uint16
+	// Sends the credentials as-is over the TLS channel rather than
+	// RSA encrypting the password. Requires TLSConfig and ProtocolVersion >= 3
+	PlainLogin bool
+	// Refuses to connect unless TLS is configured and the server certificate
+	// is verified either via the TLSConfig or the TLSFingerprint.
+	// This will default to true in a future major version.
+	RequireTLS bool
+	// Optional hex SHA256 fingerprint of the server certificate.
+	// If specified it is used instead of verifying the certificate chain.
+	TLSFingerprint string
+
+	// These control how the websocket URL is constructed e.g. when connecting
+	// via a TLS-terminating load balancer or a path-rewriting ingress.
+	// By default the scheme is wss if TLSConfig is set otherwise ws.
+	URLScheme string
+	URLPath   string
+	URLQuery  url.Values
+
+	// Options for the default websocket handler. By default there is
+	// no limit on the size of messages received from the server.
+	MaxMessageSize  int64
+	ReadBufferSize  int
+	WriteBufferSize int
+	// Extra HTTP headers (e.g. Authorization for an authenticating proxy)
+	// and websocket subprotocols sent with the opening handshake.
+	Headers      http.Header
+	Subprotocols []string
+	// The TCP keepalive probe interval which detects half-open connections
+	// (e.g. dropped by stateful firewalls) while no traffic flows.
+	// Zero uses Go's default (currently 15s) and negative disables them.
+	KeepAlive time.Duration
 
 	FetchReqSize int
+	// Decodes fetched blocks on this many goroutines. See decode.go
+	DecodeWorkers int
+
+	// If set, FetchChan fetches result blocks ahead of the consumer
+	// holding up to SpillBudget bytes in memory and spilling the rest
+	// to temporary files in SpillDir (defaults to os.TempDir).
+	// See spill.go
+	SpillBudget int64
+	SpillDir    string
+	// Detects misuse of WithPooledRows rows at the expense of not
+	// actually recycling them. See rowpool.go
+	DebugRowPool bool
+
+	// Optional client-side network timeouts for each kind of command.
+	// Unlike the QueryTimeout these are enforced by the client so
+	// reaching one leaves the connection unusable until you Reconnect.
+	CommandTimeouts CommandTimeouts
+
+	// Optional and typically shared by all connections to a cluster.
+	// See circuit_breaker.go
+	CircuitBreaker *CircuitBreaker
+	// Optional throttling of statements. See stmt_limiter.go
+	StmtLimiter *StmtLimiter
+	// Opt-in retrying of Transaction closures which fail due to
+	// transaction conflicts. See transaction.go
+	TxRetry TxRetryPolicy
+
+	// Have Reconnect re-apply the old session's attributes (and session
+	// parameters) and re-prepare its cached statements. See replay.go
+	ReplaySession   bool
+	ReplayPrepStmts bool
+
+	// Optional reporting and aborting of long-running statements.
+	// See watchdog.go
+	Watchdog *Watchdog
+
+	// Server errors are logged to Error by default. This lets you
+	// decide per error whether to log, rewrite, suppress or escalate it.
+	// See server_error.go
+	OnServerError func(ExaError) ErrorAction
+
+	// Reported to the server (e.g. in EXA_DBA_SESSIONS) in place of
+	// the OS user. Useful in containers e.g. to name the service account.
+	ClientOSUsername string
+
+	// Optionally logs bind values (at Debug level) with redaction.
+	// See bind_log.go
+	LogBinds *BindLogConf
+
+	// Rejects (client-side) anything other than queries and keeps
+	// autocommit disabled. A safety belt for reporting tools that
+	// are pointed at production. See readonly.go
+	ReadOnly bool
 
 	Timeout uint32 // Deprecated - Use Query/ConnectTimeout instead
 }
@@ -83,23 +200,82 @@ type WSHandler interface {
 	Close()
 }
 
+// The context-aware version of WSHandler. The context passed to Connect
+// carries the ConnectTimeout as its deadline and the contexts passed to
+// Write/ReadJSON should abort the operation when they're done.
+// Existing WSHandler implementations are wrapped via AdaptWSHandler.
+type WSHandlerV2 interface {
+	Connect(context.Context, url.URL, *tls.Config) error
+	EnableCompression(bool)
+	WriteJSON(context.Context, interface{}) error
+	ReadJSON(context.Context, interface{}) error
+	Close() error
+}
+
 type Conn struct {
+	cmdID    uint64 // First for 64-bit alignment as it's used atomically
+	stmtWait int64  // Nanoseconds waited on the Conf.StmtLimiter (atomically)
+
 	Conf      ConnConf
 	SessionID uint64
-	Stats     map[string]int
-	Metadata  *AuthData
+	// Counters and gauges e.g. StmtCacheLen, StmtCacheHit, StmtCacheMiss,
+	// StmtCacheEvict and StmtHandlesOpen. If the Conn is used concurrently
+	// read them via DebugState which returns a copy.
+	Stats    map[string]int
+	Metadata *AuthData
 
 	log           Logger
-	wsh           WSHandler
-	prepStmtCache map[string]*prepStmt
+	wsh           WSHandlerV2
+	prepStmtCache map[prepStmtKey]*prepStmt
+	warmStmts     map[prepStmtKey]bool         // From PrepareAll
+	altered       map[SessionParam]interface{} // By AlterSession
+	stmtMux       sync.Mutex                   // Guards prepStmtCache, warmStmts and Stats
+	stmtsBusy     int                          // Statements being prepared or executed (stmtMux)
+	stmtFreed     *sync.Cond                   // Broadcast when stmtsBusy drops (see prep_stmt.go)
 	mux           sync.Mutex
+	queue         cmdQueue // Held for each request/response round trip
 	ctx           context.Context
-	fetchReqSize  int
+	fetchReqSize  int           // Conf.FetchReqSize after defaulting
+	queryTimeout  time.Duration // Conf.QueryTimeout after defaulting to send at login
+	tlsConfig     *tls.Config
+	host          string // The host actually connected to
+	closing       int32  // Set (atomically) by Shutdown
+	closed        int32  // Set (atomically) by Disconnect
+	disconnecting int32  // Set (atomically) when Disconnect starts
+	protoVersion  uint16 // After any fallback at login
+	fetches       sync.WaitGroup
+	fetchMux      sync.Mutex // Guards adding to fetches (see startFetch)
+	fetchCtx      context.Context
+	cancelFetches context.CancelFunc
+	handles       map[handleKey]*OpenHandle
+	handleMux     sync.Mutex
+	attrs         Attributes // Our view of the session's attributes
+	attrMux       sync.Mutex
+	overrideMux   sync.RWMutex // Held exclusively by statements overriding attributes
+	prompted      *Credentials // From PromptCredentials
+	forgotPass    bool
+	watchConn     *Conn         // The Watchdog's session
+	watchMux      sync.Mutex    // Guards watchConn
+	disconnected  chan struct{} // Closed by Disconnect if watching the context
+}
+
+type ResultInfo struct {
+	NumRows uint64 // The total number of rows in the result set
+	Columns []Column
+	// The server-side result set handle. Zero if all the rows were
+	// returned with the initial response (i.e. small result sets)
+	Handle int
 }
 
 type FetchResult struct {
 	Data  []interface{}
 	Error error
+	// The result set's column metadata (shared by all rows).
+	// See fetch_result.go for accessors that make use of it.
+	Columns []Column
+
+	pooled *pooledRow // See rowpool.go
+	gen    uint32
 }
 
 func Connect(conf ConnConf) (*Conn, error) {
@@ -107,59 +283,112 @@ func Connect(conf ConnConf) (*Conn, error) {
 
 }
 
+// The connection lasts until the context is done (if ever) at which point
+// in-flight commands are aborted and it's closed as per Disconnect.
 func ConnectContext(conf ConnConf, ctx context.Context) (*Conn, error) {
 	c := &Conn{
 		Conf:          conf,
 		Stats:         map[string]int{},
 		log:           conf.Logger,
-		wsh:           conf.WSHandler,
-		prepStmtCache: map[string]*prepStmt{},
+		prepStmtCache: map[prepStmtKey]*prepStmt{},
+		warmStmts:     map[prepStmtKey]bool{},
 		ctx:           ctx,
 		fetchReqSize:  conf.FetchReqSize,
+		queryTimeout:  conf.QueryTimeout,
 	}
+	c.fetchCtx, c.cancelFetches = context.WithCancel(ctx)
 
-	if c.Conf.FetchReqSize <= 0 || c.Conf.FetchReqSize > 64*1024*1024 {
-		c.Conf.FetchReqSize = 64 * 1024 * 1024
+	if c.log == nil {
+		c.log = newDefaultLogger()
 	}
 
-	if c.Conf.Timeout > 0 {
-		c.log.Warning("exasol.ConnConf.Timeout option is deprecated. Use QueryTimeout instead.")
-		c.Conf.QueryTimeout = time.Duration(c.Conf.Timeout) * time.Second
+	if c.fetchReqSize <= 0 || c.fetchReqSize > 64*1024*1024 {
+		c.fetchReqSize = 64 * 1024 * 1024
 	}
 
-	if c.log == nil {
-		c.log = newDefaultLogger()
+	if c.Conf.Timeout > 0 {
+		c.log.Warning("exasol.ConnConf.Timeout option is deprecated. Use QueryTimeout instead.")
+		c.queryTimeout = time.Duration(c.Conf.Timeout) * time.Second
 	}
 
-	if c.wsh == nil {
-		c.wsh = newDefaultWSHandler()
-	}
+	c.wsh = c.newWSHandler()
 
-	err := c.wsConnect()
+	err := c.initTLS()
 	if err != nil {
 		return nil, c.errorf("Unable to connect to Exasol: %w", err)
 	}
 
-	err = c.login()
+	err = c.open()
 	if err != nil {
-		return nil, c.errorf("Unable to login to Exasol: %s", err)
+		return nil, err
 	}
+	c.watchContext()
 
 	return c, nil
 }
 
+// Closes the current session (if any) and opens a new one using the same
+// ConnConf. If a CredentialProvider is configured fresh credentials are
+// obtained. Prepared statements and result sets of the old session are
+// discarded. See replay.go for carrying over the old session's state.
+func (c *Conn) Reconnect() error {
+	if err := c.checkOpen(); err != nil {
+		return err
+	}
+	c.log.Info("Reconnecting SessionID:", c.SessionID)
+	err := c.send(&request{Command: "disconnect"}, &response{})
+	if err != nil {
+		c.log.Warning("Unable to disconnect from Exasol: ", err)
+	}
+	c.wsh.Close()
+	st := c.saveSessionState()
+	c.resetSession()
+	err = c.open()
+	if err != nil {
+		// The old session is gone so the connection is unusable
+		atomic.StoreInt32(&c.closed, 1)
+		c.wsh.Close()
+		return err
+	}
+	c.rewarmPrepStmts()
+	return c.replaySessionState(st)
+}
+
 func (c *Conn) Disconnect() {
+	if !atomic.CompareAndSwapInt32(&c.disconnecting, 0, 1) {
+		return // e.g. already done because the context was cancelled
+	}
 	c.log.Info("Disconnecting SessionID:", c.SessionID)
+	if c.Conf.WarnOnLeaks {
+		c.warnOfLeaks()
+	}
 
+	c.stmtMux.Lock()
+	var sths []int
 	for _, ps := range c.prepStmtCache {
-		c.closePrepStmt(ps.sth)
+		sths = append(sths, c.retirePrepStmt(ps)...)
 	}
+	c.stmtMux.Unlock()
+	c.closeStmtHandles(sths)
 	err := c.send(&request{Command: "disconnect"}, &response{})
 	if err != nil {
 		c.log.Warning("Unable to disconnect from Exasol: ", err)
 	}
-	c.wsh.Close()
-	c.wsh = nil
+	c.closeWebsocket()
+}
+
+// Returns a copy of the ConnConf with any defaults applied that the
+// connection actually uses e.g. the FetchReqSize and the Logger.
+// The Conf field itself is left as the caller specified it.
+// The Password is omitted so that the result is safe to log.
+func (c *Conn) EffectiveConfig() ConnConf {
+	conf := c.Conf
+	conf.FetchReqSize = c.fetchReqSize
+	conf.QueryTimeout = c.QueryTimeout()
+	conf.Timeout = 0
+	conf.Logger = c.log
+	conf.Password = ""
+	return conf
 }
 
 func (c *Conn) GetSessionAttr() (*Attributes, error) {
@@ -167,60 +396,67 @@ func (c *Conn) GetSessionAttr() (*Attributes, error) {
 	res := &response{}
 	err := c.send(req, res)
 	if err != nil {
-		return nil, c.errorf("Unable to get session attributes: %s", err)
+		return nil, c.errorf("Unable to get session attributes: %w", err)
 	}
 	return res.Attributes, nil
 }
 
+// Whether autocommit is enabled, as tracked from the server's responses
+func (c *Conn) Autocommit() bool {
+	return c.trackedAttributes().Autocommit
+}
+
+// Enabling or disabling autocommit when it's already in
+// that state is a no-op which doesn't contact the server.
 func (c *Conn) EnableAutoCommit() error {
+	if c.Autocommit() {
+		return nil
+	}
+	if c.Conf.ReadOnly {
+		return c.errorf("Unable to enable autocommit: %w", &ReadOnlyError{SQL: "autocommit on"})
+	}
 	c.log.Info("Enabling AutoCommit")
-	err := c.send(&request{
-		Command:    "setAttributes",
-		Attributes: &Attributes{Autocommit: true},
-	}, &response{})
+	err := c.setAttributes(&Attributes{Autocommit: true})
 	if err != nil {
-		return c.errorf("Unable to enable autocommit: %s", err)
+		return c.errorf("Unable to enable autocommit: %w", err)
 	}
 	return nil
 }
 
 func (c *Conn) DisableAutoCommit() error {
+	if !c.Autocommit() {
+		return nil
+	}
 	c.log.Info("Disabling AutoCommit")
-	// We have to roll our own map because Attributes
-	// needs to have AutoCommit set to omitempty which
-	// causes autocommit=false not to be sent :-(
-	err := c.send(map[string]interface{}{
-		"command": "setAttributes",
-		"attributes": map[string]interface{}{
-			"autocommit": false,
-		},
-	}, &response{})
+	err := c.setAttributes((&Attributes{}).Include("autocommit"))
 	if err != nil {
-		return c.errorf("Unable to disable autocommit: %s", err)
+		return c.errorf("Unable to disable autocommit: %w", err)
 	}
 	return nil
 }
 
 func (c *Conn) Rollback() error {
 	c.log.Info("Rolling back transaction")
-	_, err := c.execute("ROLLBACK", nil, "", nil, false)
+	_, err := c.execute("ROLLBACK", &ExecConf{})
 	if err != nil {
-		return c.errorf("Unable to rollback: %s", err)
+		return c.errorf("Unable to rollback: %w", err)
 	}
 	return nil
 }
 
 func (c *Conn) Commit() error {
 	c.log.Info("Committing transaction")
-	_, err := c.execute("COMMIT", nil, "", nil, false)
+	_, err := c.execute("COMMIT", &ExecConf{})
 	if err != nil {
-		return c.errorf("Unable to commit: %s", err)
+		return c.errorf("Unable to commit: %w", err)
 	}
 	return nil
 }
 
-// TODO change optional args into an ExecConf struct
-// Optional args are binds, default schema, colDefs, isColumnar flag
+// Optional args are ExecOptions e.g. WithBinds, WithSchema, WithDataTypes,
+// WithColumnar and WithTimeout. See exec_conf.go for details.
+//
+// Deprecated positional optional args are binds, default schema, colDefs, isColumnar flag
 // 1) The binds are data bindings for statements containing placeholders.
 //    You can either specify it as []interface{} if there's only one row
 //    or as [][]interface{} if there are multiple rows.
@@ -232,99 +468,73 @@ func (c *Conn) Commit() error {
 // 4) The isColumnar boolean indicates whether the binds specified in the
 //    first optional arg are in columnar format (By default the are in row format.)
 func (c *Conn) Execute(sql string, args ...interface{}) (rowsAffected int64, err error) {
-	var binds [][]interface{}
-	if len(args) > 0 && args[0] != nil {
-		switch b := args[0].(type) {
-		case [][]interface{}:
-			binds = b
-		case []interface{}:
-			binds = append(binds, b)
-		default:
-			return 0, c.error("Execute's 2nd param (binds) must be []interface{} or [][]interface{}")
-		}
+	ec, err := c.execArgsConf(args)
+	if err != nil {
+		return 0, err
 	}
-	var schema string
-	if len(args) > 1 && args[1] != nil {
-		switch s := args[1].(type) {
-		case string:
-			schema = s
-		default:
-			return 0, c.error("Execute's 3nd param (schema) must be a string")
-		}
+	if ec.ChunkSize > 0 && len(ec.Binds) > 0 {
+		return c.executeChunked(sql, ec)
 	}
-	var dataTypes []DataType
-	if len(args) > 2 && args[2] != nil {
-		switch d := args[2].(type) {
-		case []DataType:
-			dataTypes = d
-		default:
-			return 0, c.error("Execute's 4th param (data types) must be a []DataType")
-		}
+
+	res, err := c.execute(sql, ec)
+	if err != nil {
+		return 0, c.errorf("Unable to Execute: %w", err)
 	}
-	isColumnar := false // Whether or not the passed-in binds are columnar
-	if len(args) > 3 && args[3] != nil {
-		switch ic := args[3].(type) {
-		case bool:
-			isColumnar = ic
-		default:
-			return 0, c.error("Execute's 5th param (isColumnar) must be a boolean")
+	// Use ExecuteResult to get at these
+	for _, r := range res.ResponseData.Results {
+		if r.ResultSet != nil {
+			c.discardResultSet(r.ResultSet)
 		}
 	}
-
-	res, err := c.execute(sql, binds, schema, dataTypes, isColumnar)
-	if err != nil {
-		return 0, c.errorf("Unable to Execute: %s", err)
-	} else if res.ResponseData.NumResults > 0 {
+	if res.ResponseData.NumResults > 0 {
 		return res.ResponseData.Results[0].RowCount, nil
 	}
 	return 0, nil
 }
 
-// Optional args are binds, and default schema
+// Optional args are ExecOptions e.g. WithBinds, WithSchema and WithTimeout.
+//
+// Deprecated positional optional args are binds, and default schema
 // 1) The binds are data bindings for queries containing placeholders.
 //    You can specify it []interface{}
 // 2) Specifying the default schema allows you to use non-schema-qualified
 //    table identifiers in the statement even when you have no schema currently open.
 func (c *Conn) FetchChan(sql string, args ...interface{}) (<-chan FetchResult, error) {
-	var binds []interface{}
-	if len(args) > 0 && args[0] != nil {
-		switch b := args[0].(type) {
-		case []interface{}:
-			binds = b
-		default:
-			return nil, c.error("Fetch's 2nd param (binds) must be []interface{}")
-		}
-	}
-	var schema string
-	if len(args) > 1 && args[1] != nil {
-		switch s := args[1].(type) {
-		case string:
-			schema = s
-		default:
-			return nil, c.error("Fetch's 3nd param (schema) must be a string")
-		}
-	}
+	ch, _, err := c.FetchChanInfo(sql, args...)
+	return ch, err
+}
 
-	resp, err := c.execute(sql, [][]interface{}{binds}, schema, nil, false)
+// Same as FetchChan but also returns information about the result set
+// e.g. so that consumers can pre-allocate or display progress.
+func (c *Conn) FetchChanInfo(sql string, args ...interface{}) (<-chan FetchResult, *ResultInfo, error) {
+	ec, err := c.fetchArgsConf(args)
 	if err != nil {
-		return nil, c.errorf("Unable to Fetch: %s", err)
-	}
-	respData := resp.ResponseData
-	if respData.NumResults != 1 {
-		return nil, c.errorf("Unexpected numResults: %v", respData.NumResults)
+		return nil, nil, err
 	}
-	result := respData.Results[0]
-	if result.ResultType != resultSetType {
-		return nil, c.errorf("Unexpected result type: %v", result.ResultType)
+
+	rs, err := c.executeQuery(sql, ec)
+	if err != nil {
+		return nil, nil, err
 	}
-	if result.ResultSet == nil {
-		return nil, c.error("Missing websocket API resultset")
+	info := &ResultInfo{
+		NumRows: rs.NumRows,
+		Columns: rs.Columns,
+		Handle:  rs.ResultSetHandle,
 	}
 
+	err = c.startFetch()
+	if err != nil {
+		return nil, nil, err
+	}
 	ch := make(chan FetchResult, 1000)
-	go c.resultsToChan(result.ResultSet, ch)
+	go func() {
+		defer c.fetches.Done()
+		ctx, cancel := c.callContext(ec.Context)
+		defer cancel()
+		c.resultsToChan(ctx, sql, rs, ch, c.rowPool(ec))
+	}()
 
-	return ch, nil
+	return ch, info, nil
 }
 
 // For large datasets use FetchChan to avoid buffering all the data in memory
@@ -339,214 +549,504 @@ func (c *Conn) FetchSlice(sql string, args ...interface{}) (res [][]interface{},
 	return res, nil
 }
 
-func (c *Conn) SetTimeout(timeout uint32) error {
-	err := c.send(&request{
-		Command:    "setAttributes",
-		Attributes: &Attributes{QueryTimeout: timeout},
-	}, &response{})
+// Returns at most n rows (e.g. for previews) without fetching the rest
+// of the result set. Takes the same optional args as FetchSlice.
+func (c *Conn) FetchSliceN(sql string, n int, args ...interface{}) ([][]interface{}, error) {
+	if n <= 0 {
+		return nil, c.errorf("FetchSliceN requires a positive row limit not %d", n)
+	}
+	ec, err := c.fetchArgsConf(args)
+	if err != nil {
+		return nil, err
+	}
+	if ec.MaxRows == 0 || ec.MaxRows > uint64(n) {
+		ec.MaxRows = uint64(n) // So the server needn't materialize the rest
+	}
+	rs, err := c.executeQuery(sql, ec)
 	if err != nil {
-		return c.errorf("Unable to set timeout: %s", err)
+		return nil, err
 	}
-	return nil
+
+	res := [][]interface{}{}
+	add := func(data [][]interface{}) error {
+		if len(data) == 0 {
+			return nil
+		}
+		for _, row := range Transpose(data) {
+			if len(res) == n {
+				return errFetchDone
+			}
+			res = append(res, row)
+		}
+		return nil
+	}
+	if rs.ResultSetHandle <= 0 {
+		add(rs.Data)
+		return res, nil
+	}
+	ctx, cancel := c.callContext(ec.Context)
+	defer cancel()
+	err = c.fetchBlocks(ctx, rs, add)
+	if err != nil && err != errFetchDone {
+		return nil, c.errorf("Unable to Fetch: %w", err)
+	}
+	return res, nil
 }
 
-// Gets a sync.Mutext lock on the handle.
-// Allows coordinating use of the handle across multiple Go routines
-func (c *Conn) Lock()   { c.mux.Lock() }
-func (c *Conn) Unlock() { c.mux.Unlock() }
+// Scans the first row of the result set into dest (see FetchResult.Scan)
+// returning ErrNoRows if there aren't any rows. binds may be nil.
+func (c *Conn) FetchRow(sql string, binds []interface{}, dest ...interface{}) error {
+	ec := &ExecConf{MaxRows: 1}
+	if binds != nil {
+		ec.Binds = [][]interface{}{binds}
+	}
+	rs, err := c.executeQuery(sql, ec)
+	if err != nil {
+		return err
+	}
+	if rs.NumRows == 0 {
+		return ErrNoRows
+	}
 
-/*--- Private Routines ---*/
+	data := rs.Data
+	if rs.ResultSetHandle > 0 {
+		err = c.fetchBlocks(c.ctx, rs, func(block [][]interface{}) error {
+			data = block
+			return errFetchDone
+		})
+		if err != nil && err != errFetchDone {
+			return c.errorf("Unable to Fetch: %w", err)
+		}
+	}
+	row := FetchResult{Data: make([]interface{}, len(data)), Columns: rs.Columns}
+	for col := range data {
+		row.Data[col] = data[col][0]
+	}
+	return row.Scan(dest...)
+}
 
-func (c *Conn) login() error {
-	loginReq := &loginReq{
-		Command:         "login",
-		ProtocolVersion: ExasolAPIVersion,
+// Calls cb for each row of the result set. This avoids FetchChan's channel
+// and per-row allocations: the row slice is reused for every call so cb
+// must copy it if it's needed afterwards. If cb returns an error the rest
+// of the result set is skipped and that error is returned as-is.
+// Takes the same optional args as FetchChan.
+func (c *Conn) ForEachRow(sql string, cb func(row []interface{}) error, args ...interface{}) error {
+	ec, err := c.fetchArgsConf(args)
+	if err != nil {
+		return err
 	}
-	loginRes := &loginRes{}
-	err := c.send(loginReq, loginRes)
+	rs, err := c.executeQuery(sql, ec)
 	if err != nil {
 		return err
 	}
 
-	pubKeyMod, _ := hex.DecodeString(loginRes.ResponseData.PublicKeyModulus)
-	var modulus big.Int
-	modulus.SetBytes(pubKeyMod)
+	var row []interface{}
+	var cbErr error
+	each := func(data [][]interface{}) error {
+		if len(data) == 0 {
+			return nil
+		}
+		if row == nil {
+			row = make([]interface{}, len(data))
+		}
+		for i := range data[0] {
+			for col := range data {
+				row[col] = data[col][i]
+			}
+			cbErr = cb(row)
+			if cbErr != nil {
+				return cbErr
+			}
+		}
+		return nil
+	}
+	if rs.ResultSetHandle <= 0 {
+		each(rs.Data)
+		return cbErr
+	}
+	ctx, cancel := c.callContext(ec.Context)
+	defer cancel()
+	err = c.fetchBlocks(ctx, rs, each)
+	if cbErr != nil {
+		return cbErr
+	} else if err != nil {
+		return c.errorf("Unable to Fetch: %w", err)
+	}
+	return nil
+}
 
-	pubKeyExp, _ := strconv.ParseUint(loginRes.ResponseData.PublicKeyExponent, 16, 32)
+// Deprecated - Use SetQueryTimeout instead
+func (c *Conn) SetTimeout(timeout uint32) error {
+	return c.SetQueryTimeout(time.Duration(timeout) * time.Second)
+}
 
-	pubKey := rsa.PublicKey{
-		N: &modulus,
-		E: int(pubKeyExp),
+// Sets the session's query timeout. Exasol only supports whole seconds
+// so the timeout must be a multiple of a second. Zero means no timeout.
+func (c *Conn) SetQueryTimeout(timeout time.Duration) error {
+	if timeout < 0 || timeout%time.Second != 0 ||
+		timeout/time.Second > math.MaxUint32 {
+		return c.errorf("Invalid query timeout %s: it must be a whole number of seconds", timeout)
 	}
-	password := []byte(c.Conf.Password)
-	encPass, err := rsa.EncryptPKCS1v15(rand.Reader, &pubKey, password)
+	err := c.setAttributes((&Attributes{
+		QueryTimeout: uint32(timeout / time.Second),
+	}).Include("queryTimeout"))
 	if err != nil {
-		return fmt.Errorf("Password encryption error: %s", err)
+		return c.errorf("Unable to set timeout: %w", err)
 	}
-	b64Pass := base64.StdEncoding.EncodeToString(encPass)
+	return nil
+}
+
+// The session's query timeout. Zero means no timeout.
+func (c *Conn) QueryTimeout() time.Duration {
+	return time.Duration(c.trackedAttributes().QueryTimeout) * time.Second
+}
+
+// Caps the number of rows returned by queries server-side.
+// Zero means no limit.
+func (c *Conn) SetResultSetMaxRows(maxRows uint64) error {
+	err := c.setAttributes((&Attributes{ResultSetMaxRows: maxRows}).Include("resultSetMaxRows"))
+	if err != nil {
+		return c.errorf("Unable to set resultSetMaxRows: %w", err)
+	}
+	return nil
+}
+
+// Gets a sync.Mutext lock on the handle.
+// Allows coordinating use of the handle across multiple Go routines
+// e.g. to run several statements without others interleaving.
+// Individual commands are queued automatically (see queue.go).
+func (c *Conn) Lock()   { c.mux.Lock() }
+func (c *Conn) Unlock() { c.mux.Unlock() }
 
-	osUser, _ := user.Current()
+/*--- Private Routines ---*/
+
+func (c *Conn) newWSHandler() WSHandlerV2 {
+	if c.Conf.WSHandlerV2 != nil {
+		return c.Conf.WSHandlerV2
+	} else if c.Conf.WSHandler != nil {
+		return AdaptWSHandler(c.Conf.WSHandler)
+	}
+	return newDefaultWSHandler(c.Conf)
+}
 
-	authReq := &authReq{
-		Username:         c.Conf.Username,
-		Password:         b64Pass,
-		UseCompression:   false, // TODO: See if we can get compression working
-		ClientName:       c.Conf.ClientName,
-		ClientVersion:    c.Conf.ClientVersion, // The version of the calling application
-		DriverName:       "go-exasol-client v" + DriverVersion,
-		ClientOs:         runtime.GOOS,
-		ClientOsUsername: osUser.Username,
-		ClientRuntime:    runtime.Version(),
-		Attributes:       &Attributes{Autocommit: true}, // Default AutoCommit to on
+// Connects the websocket and logs in. If credentials from a provider
+// are rejected it tries once more in case they were just rotated.
+// If they're from a prompt the user gets a few attempts.
+func (c *Conn) open() error {
+	attempts := 1
+	if c.Conf.PromptCredentials != nil {
+		attempts = 3
+	} else if c.Conf.Credentials != nil {
+		attempts = 2
+	}
+	rejected := false
+	for attempt := 1; ; attempt++ {
+		err := c.wsConnect()
+		if err != nil {
+			return c.errorf("Unable to connect to Exasol: %w", err)
+		}
+		err = c.login(rejected)
+		if err == nil {
+			c.openWatchConn()
+			if !c.Conf.KeepPassword {
+				c.forgetPassword()
+			}
+			if c.Conf.ReadOnly {
+				return c.DisableAutoCommit()
+			}
+			return nil
+		}
+		if isProtocolError(err) && c.protocolVersion() > c.minProtocolVersion() {
+			c.log.Warningf("Protocol version %d was rejected. Falling back.", c.protocolVersion())
+			c.wsh.Close()
+			c.protoVersion = c.protocolVersion() - 1
+			attempt--
+			continue
+		}
+		if attempt < attempts && isAuthError(err) {
+			c.log.Warning("Credentials were rejected. Retrying with fresh ones.")
+			c.wsh.Close()
+			rejected = true
+			continue
+		}
+		return c.errorf("Unable to login to Exasol: %w", err)
 	}
+}
+
+// Forgets the server-side state of the previous session
+func (c *Conn) resetSession() {
+	c.SessionID = 0
+	c.Metadata = nil
+	c.altered = nil
+	c.stmtMux.Lock()
+	c.prepStmtCache = map[prepStmtKey]*prepStmt{}
+	c.Stats["StmtCacheLen"] = 0
+	c.Stats["StmtHandlesOpen"] = 0
+	c.stmtMux.Unlock()
+	c.handleMux.Lock()
+	c.handles = nil
+	c.handleMux.Unlock()
+	c.updateAttributes(func(a *Attributes) { *a = Attributes{} })
+}
 
-	if c.Conf.QueryTimeout.Seconds() > 0 {
-		authReq.Attributes.QueryTimeout = uint32(c.Conf.QueryTimeout.Seconds())
+func (c *Conn) execute(sql string, ec *ExecConf) (*execRes, error) {
+	if err := c.checkOpen(); err != nil {
+		return nil, err
+	}
+	if err := c.checkReadOnly(sql); err != nil {
+		return nil, err
+	}
+	if err := c.checkReadOnlyAttrs(ec.Attributes); err != nil {
+		return nil, err
 	}
 
-	authResp := &authResp{}
-	err = c.send(authReq, authResp)
+	ctx, cancel := c.callContext(ec.Context)
+	defer cancel()
+	// Before the circuit breaker as a trial it allows must be recorded
+	release, err := c.Conf.StmtLimiter.acquire(ctx, &c.stmtWait)
 	if err != nil {
-		return fmt.Errorf("Unable to authenticate: %s", err)
+		return nil, c.errorf("Unable to execute statement: %w", err)
+	}
+	defer release()
+	if err := c.Conf.CircuitBreaker.allow(c.host); err != nil {
+		return nil, err
 	}
 
-	c.SessionID = authResp.ResponseData.SessionID
-	c.Metadata = authResp.ResponseData
-	c.log.Info("Connected SessionID:", c.SessionID)
-	c.wsh.EnableCompression(false)
+	// Statements overriding session attributes run exclusively so that
+	// concurrent statements neither see the override nor undo it.
+	if ec.Timeout > 0 || ec.MaxRows > 0 || ec.Attributes != nil {
+		c.overrideMux.Lock()
+		defer c.overrideMux.Unlock()
+	} else {
+		c.overrideMux.RLock()
+		defer c.overrideMux.RUnlock()
+	}
 
-	return nil
-}
+	attrs := &Attributes{CurrentSchema: ec.Schema}
+	if ec.Timeout > 0 {
+		attrs.QueryTimeout = ec.timeoutSeconds()
+		defer c.restoreAttribute("queryTimeout", c.trackedAttributes().QueryTimeout, attrs.QueryTimeout)
+	}
+	if ec.MaxRows > 0 {
+		attrs.ResultSetMaxRows = ec.MaxRows
+		defer c.restoreAttribute("resultSetMaxRows", c.trackedAttributes().ResultSetMaxRows, attrs.ResultSetMaxRows)
+	}
+	if ec.Attributes != nil {
+		session := c.trackedAttributes()
+		for key := range ec.Attributes.keys() {
+			val := ec.Attributes.field(key)
+			attrs.setField(key, val)
+			if key == "currentSchema" && session.CurrentSchema == "" {
+				continue // There's no way to close the schema again
+			}
+			defer c.restoreAttribute(key, session.field(key), val)
+		}
+	}
 
-func (c *Conn) execute(
-	sql string,
-	binds [][]interface{},
-	schema string,
-	dataTypes []DataType,
-	isColumnar bool,
-) (*execRes, error) {
 	// Just a simple execute (no prepare) if there are no binds
+	var res *execRes
+	binds := ec.Binds
 	if binds == nil || len(binds) == 0 ||
 		binds[0] == nil || len(binds[0]) == 0 {
 		c.log.Debug("Execute: ", sql)
 		req := &execReq{
 			Command:    "execute",
-			Attributes: &Attributes{CurrentSchema: schema},
+			Attributes: attrs,
 			SqlText:    sql,
 		}
-		res := &execRes{}
-		err := c.send(req, res)
-		return res, err
+		res = &execRes{}
+		err = c.sendContext(ctx, req, res)
 	} else {
-		return c.executePrepStmt(sql, binds, schema, dataTypes, isColumnar)
+		res, err = c.executePrepStmt(ctx, sql, ec, attrs)
 	}
+	c.Conf.CircuitBreaker.record(c.host, err)
+	if err != nil {
+		return res, c.stmtError(sql, err)
+	}
+	c.trackResultSets(sql, res)
+	return res, nil
 }
 
-func (c *Conn) executePrepStmt(
-	sql string,
-	binds [][]interface{},
-	schema string,
-	dataTypes []DataType,
-	isColumnar bool,
-) (*execRes, error) {
+func (c *Conn) executePrepStmt(ctx context.Context, sql string, ec *ExecConf, attrs *Attributes) (*execRes, error) {
 	// There are binds so we need to send data so do a prepare + execute
+	schema := ec.Schema
 	ps, err := c.getPrepStmt(schema, sql)
 	if err != nil {
 		return nil, err
 	}
 
 	// This is to workaround this bug: https://www.exasol.com/support/browse/EXASOL-2138
-	if dataTypes != nil {
-		for i, dt := range dataTypes {
-			ps.columns[i].DataType = dt
+	// (Copied as cached statements are shared by concurrent Executes.)
+	columns := ps.columns
+	if ec.DataTypes != nil {
+		columns = append([]Column(nil), columns...)
+		for i, dt := range ec.DataTypes {
+			columns[i].DataType = dt
 		}
 	}
 
-	if !isColumnar {
+	binds := ec.Binds
+	if !ec.IsColumnar {
 		binds = Transpose(binds)
 	}
 	numCols := len(binds)
 	numRows := len(binds[0])
 
 	c.log.Debugf("Executing %d x %d stmt", numCols, numRows)
+	c.logBinds(columns, binds)
 	req := &execPrepStmt{
 		Command:         "executePreparedStatement",
+		Attributes:      attrs,
 		StatementHandle: int(ps.sth),
 		NumColumns:      numCols,
 		NumRows:         numRows,
-		Columns:         ps.columns,
+		Columns:         columns,
 		Data:            binds,
 	}
 	res := &execRes{}
-	err = c.send(req, res)
-
-	if err != nil &&
-		regexp.MustCompile("Statement handle not found").MatchString(err.Error()) {
-		// Not sure what causes this but I've seen it happen. So just try again.
-		c.log.Warning("Statement handle not found:", ps.sth)
-		delete(c.prepStmtCache, sql)
-		ps, err := c.getPrepStmt(schema, sql)
+	err = c.sendContext(ctx, req, res)
+
+	// Not sure what causes this but I've seen it happen. So just re-prepare and try again.
+	for retry := 0; errors.Is(err, ErrStmtHandleNotFound) && retry < c.staleStmtRetries(); retry++ {
+		c.log.Warningf("Statement handle %d not found (command %d)", ps.sth, c.lastCommandID())
+		c.forgetPrepStmt(schema, sql, ps)
+		ps, err = c.getPrepStmt(schema, sql)
 		if err != nil {
 			return nil, err
 		}
 		c.log.Warning("Retrying with:", ps.sth)
 		req.StatementHandle = int(ps.sth)
-		err = c.send(req, res)
+		res = &execRes{}
+		err = c.sendContext(ctx, req, res)
 	}
-	if !c.Conf.CachePrepStmts {
-		c.closePrepStmt(ps.sth)
+	if errors.Is(err, ErrStmtHandleNotFound) {
+		c.forgetPrepStmt(schema, sql, ps)
+	} else {
+		c.releasePrepStmt(ps)
 	}
 	return res, err
 }
 
-func (c *Conn) resultsToChan(rs *resultSet, ch chan<- FetchResult) {
+// Per-statement attributes (e.g. timeouts) are sent as session attributes
+// so afterwards we need to put back the session's value.
+func (c *Conn) restoreAttribute(key string, sessionVal, stmtVal interface{}) {
+	if stmtVal == sessionVal {
+		return
+	}
+	attrs := &Attributes{}
+	attrs.setField(key, sessionVal)
+	err := c.setAttributes(attrs)
+	if err != nil {
+		c.log.Warningf("Unable to restore %s: %s", key, err)
+	}
+}
+
+// Executes a statement that's expected to return a single result set
+func (c *Conn) executeQuery(sql string, ec *ExecConf) (*resultSet, error) {
+	resp, err := c.execute(sql, ec)
+	if err != nil {
+		return nil, c.errorf("Unable to Fetch: %w", err)
+	}
+	respData := resp.ResponseData
+	if respData.NumResults != 1 {
+		return nil, c.errorf("Unexpected numResults: %v", respData.NumResults)
+	}
+	result := respData.Results[0]
+	if result.ResultType != resultSetType {
+		return nil, c.errorf("Unexpected result type: %v", result.ResultType)
+	}
+	if result.ResultSet == nil {
+		return nil, c.error("Missing websocket API resultset")
+	}
+	return result.ResultSet, nil
+}
+
+func (c *Conn) resultsToChan(ctx context.Context, sql string, rs *resultSet, ch chan<- FetchResult, pool *rowPool) {
 	defer func() {
 		close(ch)
 	}()
 
 	if rs.NumRows == 0 {
 		// Do nothing
+	} else if rs.ResultSetHandle > 0 && c.Conf.SpillBudget > 0 {
+		c.spillResultsToChan(ctx, sql, rs, ch, pool)
 	} else if rs.ResultSetHandle > 0 {
-		for i := uint64(0); i < rs.NumRows; {
-			fetchReq := &fetchReq{
-				Command:         "fetch",
-				ResultSetHandle: rs.ResultSetHandle,
-				StartPosition:   i,
-				NumBytes:        c.Conf.FetchReqSize,
-			}
-			fetchRes := &fetchRes{}
-			err := c.send(fetchReq, fetchRes)
-			if err != nil {
-				ch <- FetchResult{Error: err}
-				return
-			}
-			i += fetchRes.ResponseData.NumRows
-			err = transposeToChan(c.ctx, ch, fetchRes.ResponseData.Data)
+		err := c.fetchBlocks(ctx, rs, func(data [][]interface{}) error {
+			err := transposeToChan(c.fetchCtx, ch, rs.Columns, data, c.Conf.AbandonedFetchTimeout, pool)
 			if err != nil {
-				ch <- FetchResult{
-					Error: err,
-				}
 				c.log.Warning("Error send to result channel:", err)
-				return
 			}
+			return err
+		})
+		if err != nil {
+			c.sendFetchError(ch, sql, err)
+		}
+	} else {
+		err := transposeToChan(c.fetchCtx, ch, rs.Columns, rs.Data, c.Conf.AbandonedFetchTimeout, pool)
+		if err != nil {
+			c.sendFetchError(ch, sql, err)
+			c.log.Warning("Error send to result channel:", err)
+			return
 		}
+	}
+}
 
-		closeRSReq := &closeResultSet{
-			Command:          "closeResultSet",
-			ResultSetHandles: []int{rs.ResultSetHandle},
+// Fetches each block of the result set passing it to the callback
+// and then closes the result set.
+func (c *Conn) fetchBlocks(ctx context.Context, rs *resultSet, cb func([][]interface{}) error) error {
+	return c.fetchBlocksFrom(ctx, rs, 0, false, cb)
+}
+
+// Starts fetching at the given row and optionally leaves the result set
+// open afterwards so that it can be fetched again (see result_set.go).
+func (c *Conn) fetchBlocksFrom(ctx context.Context, rs *resultSet, start uint64, keepOpen bool, cb func([][]interface{}) error) error {
+	if c.Conf.DecodeWorkers > 1 {
+		return c.fetchBlocksParallel(ctx, rs, start, keepOpen, cb)
+	}
+	for i := start; i < rs.NumRows; {
+		if !c.isTracked(ResultSetHandle, rs.ResultSetHandle) {
+			return ErrResultSetClosed
 		}
-		err := c.send(closeRSReq, &response{})
+		fetchReq := &fetchReq{
+			Command:         "fetch",
+			ResultSetHandle: rs.ResultSetHandle,
+			StartPosition:   i,
+			NumBytes:        c.fetchReqSize,
+		}
+		fetchRes := &fetchRes{}
+		err := c.sendContext(ctx, fetchReq, fetchRes)
 		if err != nil {
-			c.log.Warning("Unable to close result set:", err)
+			return err
 		}
-	} else {
-		err := transposeToChan(c.ctx, ch, rs.Data)
+		i += fetchRes.ResponseData.NumRows
+		err = cb(fetchRes.ResponseData.Data)
 		if err != nil {
-			ch <- FetchResult{
-				Error: err,
+			if !keepOpen {
+				c.closeResultSet(rs)
 			}
-			c.log.Warning("Error send to result channel:", err)
-			return
+			return err
 		}
 	}
+	if !keepOpen {
+		c.closeResultSet(rs)
+	}
+	return nil
+}
+
+func (c *Conn) closeResultSet(rs *resultSet) {
+	err := c.closeResultSetHandles(rs.ResultSetHandle)
+	if err != nil {
+		c.log.Warning("Unable to close result set:", err)
+	}
+}
+
+func (c *Conn) closeResultSetHandles(handles ...int) error {
+	for _, h := range handles {
+		c.untrackHandle(ResultSetHandle, h)
+	}
+	closeRSReq := &closeResultSet{
+		Command:          "closeResultSet",
+		ResultSetHandles: handles,
+	}
+	return c.send(closeRSReq, &response{})
 }
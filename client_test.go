@@ -2,13 +2,19 @@ package exasol
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
 	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/url"
 	"os"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -30,6 +36,62 @@ func (s *testSuite) TestConnClientName() {
 	`)
 	s.Equal("MyTester 123", got[0][0].(string), "Correctly set client name/version")
 	c.Disconnect()
+
+	_, version := buildInfoClient()
+	c = &Conn{Conf: ConnConf{ClientName: "MyTester"}}
+	req := c.newAuthReq("user", "pass")
+	s.Equal("MyTester", req.ClientName, "Configured name kept")
+	s.Equal(version, req.ClientVersion, "Version defaulted")
+	c = &Conn{Conf: ConnConf{ClientVersion: "123"}}
+	req = c.newAuthReq("user", "pass")
+	s.Equal("123", req.ClientVersion, "Configured version kept")
+}
+
+func (s *testSuite) TestConnDefaultClientName() {
+	c, err := Connect(s.connConf())
+	s.Require().NoError(err)
+	defer c.Disconnect()
+
+	got, err := c.FetchSlice(`
+		SELECT client, driver
+		FROM exa_user_sessions
+		WHERE session_id = CURRENT_SESSION
+	`)
+	s.Require().NoError(err)
+	name, _ := buildInfoClient()
+	s.Contains(got[0][0].(string), name, "Client name from build info")
+	s.Contains(got[0][1].(string), "go-exasol-client", "Driver name")
+}
+
+func (s *testSuite) TestConnClientOSUsername() {
+	conf := s.connConf()
+	conf.ClientOSUsername = "svc-reporting"
+	c, err := Connect(conf)
+	s.Require().NoError(err)
+	defer c.Disconnect()
+
+	got, err := c.FetchSlice(`
+		SELECT os_user
+		FROM exa_user_sessions
+		WHERE session_id = CURRENT_SESSION
+	`)
+	s.Require().NoError(err)
+	s.Equal("svc-reporting", got[0][0].(string))
+}
+
+func (s *testSuite) TestConnSchema() {
+	s.execute("CREATE TABLE foo ( id INT )", "INSERT INTO foo VALUES (1)")
+	s.exaConn.Commit()
+
+	conf := s.connConf()
+	conf.Schema = s.schema
+	c, err := Connect(conf)
+	s.Nil(err, "No connection errors")
+
+	got, err := c.FetchSlice("SELECT id FROM foo")
+	s.Nil(err, "Unqualified table found in the default schema")
+	s.Equal(float64(1), got[0][0].(float64))
+	c.Disconnect()
 }
 
 func (s *testSuite) TestQueryTimeout() {
@@ -57,12 +119,60 @@ func (s *testSuite) TestQueryTimeout() {
 	got, err = c.Execute(`EXECUTE SCRIPT sleep(10)`)
 	if s.Error(err) {
 		s.Contains(err.Error(), "Server terminated statement", "Got error")
+		s.True(errors.Is(err, ErrQueryTimeout), "Is ErrQueryTimeout")
 	}
 	s.Equal(int64(0), got, "Timed out")
 
 	// No need to disconnect because the server killed the connection
 }
 
+func (s *testSuite) TestWithContext() {
+	conf := s.connConf()
+	conf.SuppressError = true
+	c, err := Connect(conf)
+	s.Require().NoError(err)
+	defer c.Disconnect()
+	c.Execute("OPEN SCHEMA " + s.qschema)
+	c.Execute(`
+		CREATE SCRIPT sleep(sec) AS
+		local ntime = os.time() + sec
+		repeat until os.time() > ntime
+		exit({rows_affected=123})
+	`)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	timeIn := time.Now()
+	_, err = c.Execute(`EXECUTE SCRIPT sleep(10)`, WithContext(ctx))
+	s.True(errors.Is(err, context.DeadlineExceeded), "Per-call deadline applied")
+	s.True(time.Since(timeIn) < 5*time.Second, "Didn't wait for the statement")
+}
+
+func (s *testSuite) TestCommandTimeouts() {
+	ct := CommandTimeouts{Login: 1, Execute: 2, Fetch: 3, Disconnect: 4, Attributes: 5}
+	s.Equal(time.Duration(1), ct.forRequest(&authReq{}))
+	s.Equal(time.Duration(2), ct.forRequest(&execPrepStmt{Command: "executePreparedStatement"}))
+	s.Equal(time.Duration(3), ct.forRequest(&fetchReq{Command: "fetch"}))
+	s.Equal(time.Duration(4), ct.forRequest(&request{Command: "disconnect"}))
+	s.Equal(time.Duration(5), ct.forRequest(&request{Command: "getAttributes"}))
+
+	conf := s.connConf()
+	conf.SuppressError = true
+	conf.CommandTimeouts = CommandTimeouts{Execute: time.Second}
+	c, err := Connect(conf)
+	s.Require().NoError(err)
+	defer c.Disconnect()
+	c.Execute("OPEN SCHEMA " + s.qschema)
+	c.Execute(`
+		CREATE SCRIPT sleep(sec) AS
+		local ntime = os.time() + sec
+		repeat until os.time() > ntime
+		exit({rows_affected=123})
+	`)
+	_, err = c.Execute(`EXECUTE SCRIPT sleep(10)`)
+	s.True(errors.Is(err, context.DeadlineExceeded), "Execute timeout applied")
+}
+
 func (s *testSuite) TestConnectTimeout() {
 	conf := s.connConf()
 	conf.SuppressError = true
@@ -118,6 +228,44 @@ func (s *testSuite) TestConnSuppressError() {
 	c.Disconnect()
 }
 
+func (s *testSuite) TestConnOnServerError() {
+	conf := s.connConf()
+	output := &bytes.Buffer{}
+	logger := customTestLogger("error")
+	logger.SetOutput(output)
+	conf.Logger = logger
+
+	var seen []ExaError
+	conf.OnServerError = func(e ExaError) ErrorAction {
+		seen = append(seen, e)
+		if strings.Contains(e.Text, "syntax error") {
+			return ErrorAction{Suppress: true}
+		}
+		return ErrorAction{Rewrite: "Rewritten: " + e.Text}
+	}
+	c, err := Connect(conf)
+	s.Require().NoError(err)
+	defer c.Disconnect()
+
+	_, err = c.Execute("ASDF")
+	s.Contains(err.Error(), "syntax error", "Error still returned")
+	s.Equal(output.String(), "", "But not logged")
+	s.Require().Len(seen, 1, "Callback called once")
+	s.NotEmpty(seen[0].SQLCode)
+
+	_, err = c.Execute("SELECT * FROM no_such_table")
+	s.Contains(err.Error(), "Rewritten: ", "Error rewritten")
+	s.Contains(output.String(), "Rewritten: ", "Rewritten error logged")
+	s.Len(seen, 2)
+
+	// Sentinels still match the server's text
+	exaErr := newExaError("Query terminated because timeout has been reached", "")
+	c.serverErrorAction([]interface{}{exaErr})
+	s.Contains(exaErr.Error(), "Rewritten: ")
+	s.True(errors.Is(exaErr, ErrQueryTimeout))
+	s.Equal("Query terminated because timeout has been reached", exaErr.Text)
+}
+
 func (s *testSuite) TestConnLogger() {
 	conf := s.connConf()
 
@@ -164,6 +312,48 @@ func (s *testSuite) TestConnLogger() {
 	c.Disconnect()
 }
 
+func (s *testSuite) TestCommandIDs() {
+	conf := s.connConf()
+	output := &bytes.Buffer{}
+	logger := customTestLogger("debug")
+	logger.SetOutput(output)
+	conf.Logger = logger
+	c, err := Connect(conf)
+	s.Require().NoError(err)
+	defer c.Disconnect()
+
+	output.Reset()
+	_, err = c.Execute("SELECT 1")
+	s.Require().NoError(err)
+	id := c.lastCommandID()
+	prefix := fmt.Sprintf("Command %d (session %d): ", id, c.SessionID)
+	s.Contains(output.String(), prefix+"Sending execute")
+	s.Contains(output.String(), prefix+"Received ok")
+}
+
+func (s *testSuite) TestConcurrentStatements() {
+	c, err := Connect(s.connConf())
+	s.Require().NoError(err)
+	defer c.Disconnect()
+
+	// No Lock/Unlock needed
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		go func(i int) {
+			got, err := c.FetchSlice(fmt.Sprintf("SELECT %d FROM dual", i))
+			if err == nil && got[0][0].(float64) != float64(i) {
+				err = fmt.Errorf("Got %v for query %d", got[0][0], i)
+			}
+			errs <- err
+		}(i)
+	}
+	for i := 0; i < 20; i++ {
+		s.NoError(<-errs)
+	}
+	s.Equal(0, c.QueueDepth())
+	s.True(c.DebugState().Stats["QueuePeak"] >= 1)
+}
+
 func (s *testSuite) TestConnCachePrepStmt() {
 	conf := s.connConf()
 
@@ -191,10 +381,199 @@ func (s *testSuite) TestConnCachePrepStmt() {
 	s.Equal(got[0][0].(float64), float64(123), "Everything OK")
 	s.Equal(c.Stats["StmtCacheLen"], 1, "Cache is not empty")
 	s.Equal(c.Stats["StmtCacheMiss"], 1, "Cache miss not recorded")
+	s.Equal(c.Stats["StmtCacheHit"], 1, "Cache hit recorded")
+	s.Equal(c.Stats["StmtHandlesOpen"], 1, "Cached handle is still open")
 
 	c.Disconnect()
 }
 
+func (s *testSuite) TestInvalidatePrepStmt() {
+	conf := s.connConf()
+	conf.CachePrepStmts = true
+	c, _ := Connect(conf)
+
+	sql1 := "SELECT 123 FROM dual WHERE true = ?"
+	sql2 := "SELECT 456 FROM dual WHERE true = ?"
+	c.FetchSlice(sql1, []interface{}{true})
+	c.FetchSlice(sql2, []interface{}{true})
+	s.Equal(2, c.Stats["StmtCacheLen"], "Both stmts cached")
+
+	s.Nil(c.InvalidatePrepStmt(sql1))
+	s.Equal(1, c.Stats["StmtCacheLen"], "One stmt invalidated")
+	s.Nil(c.InvalidatePrepStmt(sql1), "Invalidating twice is harmless")
+
+	got, _ := c.FetchSlice(sql1, []interface{}{true})
+	s.Equal(float64(123), got[0][0].(float64), "Re-prepared")
+	s.Equal(3, c.Stats["StmtCacheMiss"], "Cache miss recorded")
+
+	s.Nil(c.ClearPrepStmtCache())
+	s.Equal(0, c.Stats["StmtCacheLen"], "Cache is empty")
+
+	c.Disconnect()
+}
+
+func (s *testSuite) TestPrepStmtCacheSchemas() {
+	s.execute(
+		"DROP SCHEMA IF EXISTS test2 CASCADE",
+		"CREATE SCHEMA test2",
+		"CREATE TABLE test2.foo ( id INT )",
+		"CREATE TABLE test.foo ( id INT )",
+	)
+	defer s.execute("DROP SCHEMA IF EXISTS test2 CASCADE")
+
+	conf := s.connConf()
+	conf.CachePrepStmts = true
+	c, err := Connect(conf)
+	s.Require().NoError(err)
+	defer c.Disconnect()
+
+	insert := "INSERT INTO foo VALUES (?)"
+	_, err = c.Execute("OPEN SCHEMA test")
+	s.NoError(err)
+	_, err = c.Execute(insert, WithBinds([]interface{}{1}))
+	s.NoError(err)
+	_, err = c.Execute("OPEN SCHEMA test2")
+	s.NoError(err)
+	_, err = c.Execute(insert, WithBinds([]interface{}{2}))
+	s.NoError(err)
+	_, err = c.Execute(insert, WithBinds([]interface{}{3}), WithSchema("TEST"))
+	s.NoError(err)
+
+	s.Equal(2, c.Stats["StmtCacheLen"], "Cached per schema")
+	s.Equal(1, c.Stats["StmtCacheHit"])
+	s.Equal(
+		[][]interface{}{{float64(1)}, {float64(3)}},
+		s.fetch("SELECT id FROM test.foo ORDER BY id"),
+	)
+	s.Equal([][]interface{}{{float64(2)}}, s.fetch("SELECT id FROM test2.foo"))
+
+	s.NoError(c.InvalidatePrepStmt(insert))
+	s.Equal(0, c.Stats["StmtCacheLen"], "Invalidated in every schema")
+}
+
+func (s *testSuite) TestPrepareAll() {
+	s.execute("CREATE TABLE foo ( id INT )")
+	conf := s.connConf()
+	conf.CachePrepStmts = true
+	conf.Schema = s.schema
+	c, err := Connect(conf)
+	s.Require().NoError(err)
+	defer c.Disconnect()
+
+	sqls := []string{
+		"INSERT INTO foo VALUES (?)",
+		"SELECT id FROM foo WHERE id = ?",
+	}
+	s.NoError(c.PrepareAll(sqls))
+	s.Equal(2, c.Stats["StmtCacheLen"])
+	s.Equal(2, c.Stats["StmtCacheMiss"])
+
+	_, err = c.Execute(sqls[0], WithBinds([]interface{}{1}))
+	s.NoError(err)
+	s.Equal(1, c.Stats["StmtCacheHit"], "Warmed up")
+
+	s.NoError(c.Reconnect())
+	s.Equal(2, c.Stats["StmtCacheLen"], "Re-prepared after Reconnect")
+	s.Equal(2, c.Stats["StmtHandlesOpen"])
+
+	c.Conf.SuppressError = true
+	s.Error(c.PrepareAll([]string{"SELECT * FROM no_such_table"}))
+	c.Conf.CachePrepStmts = false
+	s.Error(c.PrepareAll(sqls))
+}
+
+func (s *testSuite) TestMaxOpenStmts() {
+	conf := s.connConf()
+	conf.CachePrepStmts = true
+	conf.MaxOpenStmts = 2
+	c, err := Connect(conf)
+	s.Require().NoError(err)
+	defer c.Disconnect()
+
+	for i := 1; i <= 3; i++ {
+		_, err = c.FetchSlice(fmt.Sprintf("SELECT %d FROM dual WHERE true = ?", i), []interface{}{true})
+		s.NoError(err)
+	}
+	s.Equal(2, c.Stats["StmtHandlesOpen"], "Capped")
+	s.Equal(2, c.Stats["StmtCacheLen"])
+	s.Equal(1, c.Stats["StmtCacheEvict"], "Least recently used was closed")
+
+	// Simulate handles that aren't ours to close
+	c.Stats["StmtHandlesOpen"] += 2
+	c.Conf.SuppressError = true
+	_, err = c.FetchSlice("SELECT 4 FROM dual WHERE true = ?", []interface{}{true})
+	s.True(errors.Is(err, ErrTooManyStmts))
+	c.Stats["StmtHandlesOpen"] -= 2
+}
+
+func (s *testSuite) TestStaleStmtRetry() {
+	conf := s.connConf()
+	conf.CachePrepStmts = true
+	c, err := Connect(conf)
+	s.Require().NoError(err)
+	defer c.Disconnect()
+
+	sql := "SELECT ? FROM dual"
+	got, err := c.FetchSlice(sql, []interface{}{"a"})
+	s.Require().NoError(err)
+	s.Equal([][]interface{}{{"a"}}, got)
+
+	// Close the cached handle behind the cache's back
+	staleHandle := func() {
+		ps := c.prepStmtCache[c.prepStmtKey("", sql)]
+		s.Require().NotNil(ps)
+		s.Require().NoError(c.send(&closePrepStmt{
+			Command:         "closePreparedStatement",
+			StatementHandle: ps.sth,
+		}, &response{}))
+	}
+	staleHandle()
+	got, err = c.FetchSlice(sql, []interface{}{"b"})
+	s.NoError(err, "Re-prepared and retried")
+	s.Equal([][]interface{}{{"b"}}, got)
+	s.Equal(1, c.Stats["StmtHandlesOpen"])
+
+	c.Conf.StaleStmtRetries = -1
+	c.Conf.SuppressError = true
+	staleHandle()
+	_, err = c.FetchSlice(sql, []interface{}{"c"})
+	s.True(errors.Is(err, ErrStmtHandleNotFound), "Not retried")
+	s.Equal(0, c.Stats["StmtHandlesOpen"], "Stale handle forgotten")
+	s.Equal(0, c.Stats["StmtCacheLen"])
+}
+
+func (s *testSuite) TestPrepStmtCacheConcurrent() {
+	conf := s.connConf()
+	conf.CachePrepStmts = true
+	conf.MaxOpenStmts = 3
+	c, err := Connect(conf)
+	s.Require().NoError(err)
+	defer c.Disconnect()
+	s.execute("CREATE TABLE " + s.qschema + ".concurrent (g INT, i INT)")
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 10; i++ {
+				// Varied so that statements are evicted while others use them
+				sql := fmt.Sprintf("INSERT INTO %s.concurrent VALUES (?, ?) -- %d", s.qschema, (g+i)%5)
+				_, err := c.Execute(sql, []interface{}{g, i})
+				s.NoError(err)
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	got := s.fetch("SELECT COUNT(*) FROM " + s.qschema + ".concurrent")
+	s.Equal([][]interface{}{{float64(80)}}, got)
+	stats := c.DebugState().Stats
+	s.True(stats["StmtHandlesOpen"] <= 3, "Capped")
+	s.Equal(stats["StmtCacheLen"], stats["StmtHandlesOpen"], "Nothing leaked or closed twice")
+	s.Equal(stats["StmtHandlesOpen"], len(c.OpenHandles()))
+}
+
 func (s *testSuite) TestConnEncryption() {
 	conf := s.connConf()
 
@@ -225,6 +604,63 @@ func (s *testSuite) TestConnEncryption() {
 	c.Disconnect()
 }
 
+func (s *testSuite) TestPlainLogin() {
+	conf := s.connConf()
+	conf.SuppressError = true
+	conf.PlainLogin = true
+	conf.ProtocolVersion = 3
+
+	_, err := Connect(conf)
+	if s.Error(err) {
+		s.Contains(err.Error(), "requires a TLSConfig", "TLS is mandatory")
+	}
+
+	conf.TLSConfig = &tls.Config{InsecureSkipVerify: true}
+	c, err := Connect(conf)
+	s.Nil(err, "No connection errors")
+	got, _ := c.FetchSlice(`
+		SELECT encrypted
+		FROM exa_user_sessions
+		WHERE session_id = CURRENT_SESSION
+	`)
+	s.Equal(true, got[0][0].(bool), "Connection is encrypted")
+	c.Disconnect()
+}
+
+func (s *testSuite) TestRequireTLS() {
+	conf := s.connConf()
+	conf.SuppressError = true
+	conf.RequireTLS = true
+
+	_, err := Connect(conf)
+	if s.Error(err) {
+		s.Contains(err.Error(), "no TLSConfig", "TLS is required")
+	}
+
+	conf.TLSConfig = &tls.Config{InsecureSkipVerify: true}
+	_, err = Connect(conf)
+	if s.Error(err) {
+		s.Contains(err.Error(), "not verified", "Verification is required")
+	}
+
+	conf.TLSFingerprint = "00:11:22"
+	_, err = Connect(conf)
+	if s.Error(err) {
+		s.Contains(err.Error(), "does not match", "Wrong fingerprint")
+	}
+
+	// Now work out the real fingerprint
+	tc, err := tls.Dial("tcp", fmt.Sprintf("%s:%d", conf.Host, conf.Port), conf.TLSConfig)
+	if s.NoError(err) {
+		sum := sha256.Sum256(tc.ConnectionState().PeerCertificates[0].Raw)
+		tc.Close()
+		conf.TLSFingerprint = hex.EncodeToString(sum[:])
+		c, err := Connect(conf)
+		s.Nil(err, "Fingerprint matches")
+		c.Disconnect()
+	}
+}
+
 func (s *testSuite) TestHostRanges() {
 	conf := s.connConf()
 	conf.SuppressError = true // Set to false to see the random output
@@ -239,6 +675,23 @@ func (s *testSuite) TestHostRanges() {
 	}
 }
 
+func (s *testSuite) TestDNSHosts() {
+	if *testHost != "127.0.0.1" {
+		s.T().Skip("Requires a local Exasol")
+	}
+	conf := s.connConf()
+	// localhost often resolves to both ::1 and 127.0.0.1
+	// in which case each is tried until one works
+	conf.Host = "localhost"
+	c, err := Connect(conf)
+	s.Require().NoError(err)
+	defer c.Disconnect()
+	if len(c.resolveHost("localhost")) > 1 {
+		s.NotEqual("localhost", c.host, "Connected via a resolved IP")
+	}
+	s.Nil(c.resolveHost("127.0.0.1"), "IPs aren't resolved")
+}
+
 func (s *testSuite) TestConnErrors() {
 	// Connection error
 	conf := s.connConf()
@@ -285,6 +738,61 @@ func (s *testSuite) TestAutoCommit() {
 	s.Equal(true, got.Autocommit, "Autocommit still enabled")
 }
 
+func (s *testSuite) TestAutoCommitIdempotent() {
+	conf := s.connConf()
+	wsh := &testV2WSHandler{WSHandlerV2: newDefaultWSHandler(conf)}
+	conf.WSHandlerV2 = wsh
+	c, err := Connect(conf)
+	s.Require().NoError(err)
+	defer c.Disconnect()
+
+	s.True(c.Autocommit())
+	reads := wsh.reads
+	s.NoError(c.EnableAutoCommit())
+	s.Equal(reads, wsh.reads, "Already enabled so nothing sent")
+
+	s.NoError(c.DisableAutoCommit())
+	s.False(c.Autocommit())
+	reads = wsh.reads
+	s.NoError(c.DisableAutoCommit())
+	s.Equal(reads, wsh.reads, "Already disabled so nothing sent")
+
+	got, err := c.GetSessionAttr()
+	s.NoError(err)
+	s.False(got.Autocommit)
+}
+
+func (s *testSuite) TestReadOnly() {
+	s.exaConn.Execute("CREATE TABLE foo ( id INT )")
+	s.exaConn.Commit()
+
+	conf := s.connConf()
+	conf.ReadOnly = true
+	conf.SuppressError = true
+	c, err := Connect(conf)
+	s.Require().NoError(err)
+	defer c.Disconnect()
+	s.False(c.Autocommit(), "Autocommit disabled")
+	s.False(c.newAuthReq("user", "pass").Attributes.Autocommit, "Even at login")
+
+	_, err = c.FetchSlice("-- comment\n(SELECT * FROM foo)")
+	s.NoError(err, "Queries are allowed")
+
+	var roErr *ReadOnlyError
+	_, err = c.Execute("INSERT INTO foo VALUES (1)")
+	s.True(errors.As(err, &roErr), "Writes are rejected")
+	err = c.EnableAutoCommit()
+	s.True(errors.As(err, &roErr), "Autocommit is rejected")
+	err = c.BulkInsert(s.schema, "foo", bytes.NewBufferString("1\n"))
+	s.True(errors.As(err, &roErr), "Imports are rejected")
+	_, err = c.Execute("SELECT * INTO TABLE " + s.qschema + ".bar FROM foo")
+	s.True(errors.As(err, &roErr), "SELECT INTO TABLE is rejected")
+	_, err = c.FetchSlice("SELECT 'into table' FROM foo")
+	s.NoError(err, "Unless it's quoted")
+	_, err = c.FetchSlice("SELECT * FROM foo", WithAttributes(&Attributes{Autocommit: true}))
+	s.True(errors.As(err, &roErr), "Autocommit per statement is rejected")
+}
+
 func (s *testSuite) TestCommitAndRollback() {
 	exa := s.exaConn
 	exa.DisableAutoCommit()
@@ -386,6 +894,48 @@ func (s *testSuite) TestExecute() {
 	s.Equal(int64(3), got)
 }
 
+func (s *testSuite) TestExecuteOptions() {
+	exa := s.exaConn
+	exa.Conf.SuppressError = true
+	exa.Execute("CREATE TABLE foo ( id INT, val CHAR(1) )")
+
+	got, err := exa.Execute(
+		"INSERT INTO foo VALUES (?,?)",
+		WithBinds([]interface{}{1, "a"}, []interface{}{2, "b"}),
+	)
+	s.Nil(err)
+	s.Equal(int64(2), got)
+
+	got, err = exa.Execute(
+		"INSERT INTO foo VALUES (?,?)",
+		WithBinds([]interface{}{3, 4}, []interface{}{"c", "d"}),
+		WithColumnar(),
+		WithDataTypes([]DataType{
+			{Type: "DECIMAL", Precision: 10},
+			{Type: "CHAR", Size: 1},
+		}),
+	)
+	s.Nil(err)
+	s.Equal(int64(2), got)
+
+	exa.Execute("OPEN SCHEMA sys")
+	got, err = exa.Execute("DELETE FROM foo WHERE id = 4", WithSchema(s.schema))
+	s.Nil(err)
+	s.Equal(int64(1), got)
+
+	rows, err := exa.FetchSlice(
+		"SELECT val FROM foo WHERE id < ? ORDER BY id",
+		WithBinds([]interface{}{3}), WithSchema(s.schema), WithTimeout(10*time.Second),
+	)
+	s.Nil(err)
+	s.Equal([][]interface{}{{"a"}, {"b"}}, rows)
+
+	_, err = exa.Execute("SELECT 1", WithSchema(s.schema), "oops")
+	if s.Error(err) {
+		s.Contains(err.Error(), "must be an ExecOption")
+	}
+}
+
 func (s *testSuite) TestFetchChan() {
 	exa := s.exaConn
 	exa.Conf.SuppressError = true
@@ -475,6 +1025,123 @@ func (s *testSuite) TestFetchSlice() {
 	}
 }
 
+func (s *testSuite) TestFetchSliceN() {
+	exa := s.exaConn
+	exa.Execute("CREATE TABLE foo ( id INT )")
+	exa.Execute("INSERT INTO foo SELECT level FROM dual CONNECT BY level <= 10000")
+
+	got, err := exa.FetchSliceN("SELECT id FROM foo ORDER BY id", 3)
+	if s.NoError(err) {
+		s.Equal([][]interface{}{{float64(1)}, {float64(2)}, {float64(3)}}, got)
+	}
+	s.Empty(exa.OpenHandles(), "Result set closed")
+
+	got, err = exa.FetchSliceN("SELECT id FROM foo WHERE id > ?", 2000, WithBinds([]interface{}{9000}))
+	s.NoError(err)
+	s.Len(got, 1000, "Fewer rows than the limit")
+
+	exa.Conf.SuppressError = true
+	_, err = exa.FetchSliceN("SELECT id FROM foo", 0)
+	s.Error(err)
+}
+
+func (s *testSuite) TestForEachRow() {
+	exa := s.exaConn
+	exa.Execute("CREATE TABLE foo ( id INT, val CHAR(1) )")
+	exa.Execute("INSERT INTO foo SELECT level, 'x' FROM dual CONNECT BY level <= 10000")
+
+	var sum float64
+	var count int
+	var prev []interface{}
+	err := exa.ForEachRow("SELECT id, val FROM foo", func(row []interface{}) error {
+		if prev != nil {
+			s.Equal(&prev[0], &row[0], "Row buffer is reused")
+		}
+		prev = row
+		sum += row[0].(float64)
+		count++
+		return nil
+	})
+	s.NoError(err)
+	s.Equal(10000, count)
+	s.Equal(float64(10000*10001/2), sum)
+	s.Empty(exa.OpenHandles(), "Result set closed")
+
+	stop := errors.New("stop")
+	count = 0
+	err = exa.ForEachRow("SELECT id FROM foo WHERE id > ?", func(row []interface{}) error {
+		count++
+		if count == 5 {
+			return stop
+		}
+		return nil
+	}, WithBinds([]interface{}{100}))
+	s.Equal(stop, err)
+	s.Equal(5, count)
+	s.Empty(exa.OpenHandles(), "Result set closed after stopping early")
+}
+
+func (s *testSuite) TestFetchChanInfo() {
+	exa := s.exaConn
+	exa.Execute("CREATE TABLE foo ( id INT, val CHAR(1) )")
+	exa.Execute(
+		"INSERT INTO foo VALUES (?,?)",
+		WithBinds([]interface{}{1, 2, 3}, []interface{}{"a", "b", "c"}),
+		WithColumnar(),
+	)
+
+	got, info, err := exa.FetchChanInfo("SELECT * FROM foo ORDER BY id")
+	if s.NoError(err) {
+		s.Equal(uint64(3), info.NumRows)
+		if s.Len(info.Columns, 2) {
+			s.Equal("ID", info.Columns[0].Name)
+			s.Equal("DECIMAL", info.Columns[0].DataType.Type)
+			s.Equal("VAL", info.Columns[1].Name)
+			s.Equal("CHAR", info.Columns[1].DataType.Type)
+		}
+		s.Equal(0, info.Handle, "Small result sets don't need a handle")
+		n := 0
+		for range got {
+			n++
+		}
+		s.Equal(3, n)
+	}
+
+	got, info, err = exa.FetchChanInfo("SELECT level FROM dual CONNECT BY level <= 5000")
+	if s.NoError(err) {
+		s.Equal(uint64(5000), info.NumRows)
+		s.True(info.Handle > 0, "Large result sets have a handle")
+		for range got {
+		}
+	}
+}
+
+func (s *testSuite) TestMaxRows() {
+	conf := s.connConf()
+	conf.MaxRows = 4
+	c, err := Connect(conf)
+	s.Require().NoError(err)
+	defer c.Disconnect()
+
+	sql := "SELECT level FROM dual CONNECT BY level <= 5"
+	got, err := c.FetchSlice(sql)
+	s.NoError(err)
+	s.Len(got, 4, "Capped by the connection")
+
+	got, err = c.FetchSlice(sql, WithMaxRows(2))
+	s.NoError(err)
+	s.Len(got, 2, "Capped by the statement")
+
+	got, err = c.FetchSlice(sql)
+	s.NoError(err)
+	s.Len(got, 4, "Connection cap restored")
+
+	s.NoError(c.SetResultSetMaxRows(0))
+	got, err = c.FetchSlice(sql)
+	s.NoError(err)
+	s.Len(got, 5, "Uncapped")
+}
+
 func (s *testSuite) TestSetTimeout() {
 	conf := s.connConf()
 	conf.QueryTimeout = 5 * time.Second
@@ -491,6 +1158,27 @@ func (s *testSuite) TestSetTimeout() {
 	s.Equal(uint32(10), attr.QueryTimeout)
 }
 
+func (s *testSuite) TestSetQueryTimeout() {
+	c, err := Connect(s.connConf())
+	s.Require().NoError(err)
+	defer c.Disconnect()
+
+	s.NoError(c.SetQueryTimeout(30 * time.Second))
+	s.Equal(30*time.Second, c.QueryTimeout())
+	s.Equal(30*time.Second, c.EffectiveConfig().QueryTimeout)
+	attr, err := c.GetSessionAttr()
+	s.NoError(err)
+	s.Equal(uint32(30), attr.QueryTimeout)
+
+	c.Conf.SuppressError = true
+	s.Error(c.SetQueryTimeout(1500 * time.Millisecond))
+	s.Error(c.SetQueryTimeout(-time.Second))
+	s.Equal(30*time.Second, c.QueryTimeout(), "Unchanged by invalid timeouts")
+
+	s.NoError(c.SetQueryTimeout(0))
+	s.Equal(time.Duration(0), c.QueryTimeout())
+}
+
 type testWSHandler struct{}
 
 func (wsh *testWSHandler) Connect(u url.URL, s *tls.Config, t time.Duration) error {
@@ -501,6 +1189,80 @@ func (wsh *testWSHandler) ReadJSON(resp interface{}) error { return nil }
 func (wsh *testWSHandler) EnableCompression(e bool)        {}
 func (wsh *testWSHandler) Close()                          {}
 
+type testURLWSHandler struct {
+	testWSHandler
+	url url.URL
+}
+
+func (wsh *testURLWSHandler) Connect(u url.URL, s *tls.Config, t time.Duration) error {
+	wsh.url = u
+	return fmt.Errorf("Connecting in test handler")
+}
+
+func (s *testSuite) TestWSURL() {
+	conf := s.connConf()
+	conf.SuppressError = true
+	wsh := &testURLWSHandler{}
+	conf.WSHandler = wsh
+	conf.Host = "exasol.example.com"
+	conf.Port = 443
+	conf.URLScheme = "wss"
+	conf.URLPath = "/exasol/ws"
+	conf.URLQuery = url.Values{"cluster": []string{"prod"}}
+	Connect(conf)
+	s.Equal("wss://exasol.example.com:443/exasol/ws?cluster=prod", wsh.url.String())
+
+	conf.URLScheme = "http"
+	_, err := Connect(conf)
+	if s.Error(err) {
+		s.Contains(err.Error(), "Invalid websocket URL scheme")
+	}
+
+	conf.URLScheme = "ws"
+	conf.TLSConfig = &tls.Config{ServerName: "exasol.example.com"}
+	wsh.url = url.URL{}
+	_, err = Connect(conf)
+	if s.Error(err) {
+		s.Contains(err.Error(), "can't be used with TLS")
+	}
+	s.Empty(wsh.url.Host, "Never connected in plaintext")
+}
+
+func (s *testSuite) TestMaxMessageSize() {
+	conf := s.connConf()
+	conf.SuppressError = true
+	conf.MaxMessageSize = 10 * 1024
+	c, err := Connect(conf)
+	s.Nil(err, "No connection errors")
+
+	_, err = c.FetchSlice("SELECT LPAD('x', 50000, 'x')")
+	if s.Error(err) {
+		s.Contains(err.Error(), "exceeds the 10240 byte limit")
+	}
+
+	got, err := c.FetchSlice("SELECT 123")
+	s.Nil(err, "Connection is still usable")
+	s.Equal(float64(123), got[0][0].(float64))
+	c.Disconnect()
+}
+
+func (s *testSuite) TestKeepAlive() {
+	conf := s.connConf()
+	s.Nil(newDefaultWSHandler(conf).dialer.NetDialContext, "Uses the default dialer")
+
+	for _, ka := range []time.Duration{-1, 5 * time.Second} {
+		conf.KeepAlive = ka
+		s.NotNil(newDefaultWSHandler(conf).dialer.NetDialContext)
+		c, err := Connect(conf)
+		if s.NoError(err) {
+			got, err := c.FetchSlice("SELECT 123")
+			s.Nil(err)
+			s.Equal(float64(123), got[0][0].(float64))
+			c.Disconnect()
+		}
+	}
+}
+
 func (s *testSuite) TestWSHandler() {
 	conf := s.connConf()
 	conf.SuppressError = true
@@ -510,3 +1272,84 @@ func (s *testSuite) TestWSHandler() {
 		s.Contains(err.Error(), "Connecting in test handler", "Got error")
 	}
 }
+
+type testV2WSHandler struct {
+	WSHandlerV2
+	reads int
+}
+
+func (wsh *testV2WSHandler) ReadJSON(ctx context.Context, resp interface{}) error {
+	wsh.reads++
+	return wsh.WSHandlerV2.ReadJSON(ctx, resp)
+}
+
+// Emulates an older server that rejects newer protocol versions
+type oldServerWSHandler struct {
+	WSHandlerV2
+	maxVersion uint16
+	rejected   []uint16
+	reject     bool
+}
+
+func (wsh *oldServerWSHandler) WriteJSON(ctx context.Context, req interface{}) error {
+	if l, ok := req.(*loginReq); ok && l.ProtocolVersion > wsh.maxVersion {
+		wsh.rejected = append(wsh.rejected, l.ProtocolVersion)
+		wsh.reject = true
+		return nil
+	}
+	return wsh.WSHandlerV2.WriteJSON(ctx, req)
+}
+
+func (wsh *oldServerWSHandler) ReadJSON(ctx context.Context, resp interface{}) error {
+	if wsh.reject {
+		wsh.reject = false
+		return json.Unmarshal([]byte(`{"status":"error","exception":{
+			"text":"Requested protocol version is not supported","sqlcode":"08004"
+		}}`), resp)
+	}
+	return wsh.WSHandlerV2.ReadJSON(ctx, resp)
+}
+
+func (s *testSuite) TestProtocolFallback() {
+	conf := s.connConf()
+	conf.SuppressError = true
+	conf.ProtocolVersion = 3
+	wsh := &oldServerWSHandler{WSHandlerV2: newDefaultWSHandler(conf), maxVersion: 1}
+	conf.WSHandlerV2 = wsh
+	c, err := Connect(conf)
+	s.Require().NoError(err)
+	s.Equal([]uint16{3, 2}, wsh.rejected, "Fell back")
+	s.Equal(uint16(1), c.ProtocolVersion(), "Negotiated version")
+	c.Disconnect()
+
+	conf.MinProtocolVersion = 2
+	wsh = &oldServerWSHandler{WSHandlerV2: newDefaultWSHandler(conf), maxVersion: 1}
+	conf.WSHandlerV2 = wsh
+	_, err = Connect(conf)
+	s.Error(err, "Not below MinProtocolVersion")
+	s.Equal([]uint16{3, 2}, wsh.rejected)
+}
+
+func (s *testSuite) TestWSHandlerV2() {
+	conf := s.connConf()
+	conf.SuppressError = true
+	wsh := &testV2WSHandler{WSHandlerV2: newDefaultWSHandler(conf)}
+	conf.WSHandlerV2 = wsh
+	conf.WSHandler = &testWSHandler{} // V2 takes precedence
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c, err := ConnectContext(conf, ctx)
+	s.Require().NoError(err)
+	reads := wsh.reads
+	s.True(reads > 0, "Logged in via the V2 handler")
+
+	got, err := c.FetchSlice("SELECT 123")
+	s.Nil(err)
+	s.Equal(float64(123), got[0][0].(float64))
+	s.True(wsh.reads > reads, "Fetched via the V2 handler")
+
+	// Cancelling the connection's context aborts any further requests
+	cancel()
+	_, err = c.Execute("SELECT 1")
+	s.Error(err)
+}
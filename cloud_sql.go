@@ -0,0 +1,305 @@
+/*
+	Builders for IMPORT and EXPORT statements that transfer data
+	directly between Exasol and cloud storage (S3, Azure, GCS etc)
+	or any other remote URL, e.g.
+
+	    rows, err := conn.ImportCloud(&exasol.CloudImport{
+	        Schema:     "my_schema",
+	        Table:      "my_table",
+	        Connection: "MY_S3_BUCKET", // A CONNECTION object holding the credentials
+	        Files:      []string{"exports/2021-*.csv"},
+	        CSV:        exasol.CSVFormat{Skip: 1},
+	    })
+
+
+	AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// The CSV file options. Empty fields are left to Exasol's defaults.
+// There's no escape character option as Exasol escapes the
+// ColumnDelimiter within values by doubling it.
+type CSVFormat struct {
+	ColumnSeparator string // e.g. "," or "TAB"
+	ColumnDelimiter string // The enclosure character that quotes values e.g. `"`
+	RowSeparator    string // LF, CRLF or CR
+	Encoding        string // e.g. UTF-8
+	Null            string // How NULLs are represented e.g. `\N`
+	Skip            int    // Number of header rows to skip when importing
+	Trim            string // TRIM, LTRIM or RTRIM values when importing
+}
+
+type CloudImport struct {
+	Schema  string
+	Table   string
+	Columns []string // Optional target columns
+
+	Format string // CSV (the default) or FBV
+
+	// The source is either a URL or the name of a CONNECTION object.
+	// Using a CONNECTION keeps the credentials out of the statement.
+	URL        string
+	Connection string
+	Cloud      string // Optional cloud type e.g. "AZURE BLOBSTORAGE"
+	User       string
+	Password   string
+
+	Files []string // File names or patterns relative to the URL
+	CSV   CSVFormat
+
+	// Optional rejected rows handling. ErrorsInto is a table (or CSV file)
+	// and ErrorsMode is either REPLACE or TRUNCATE.
+	ErrorsInto  string
+	ErrorsMode  string
+	RejectLimit int // Zero for none, negative for UNLIMITED
+}
+
+// Returns the number of rows imported
+func (c *Conn) ImportCloud(ci *CloudImport) (int64, error) {
+	sql, err := c.CloudImportSQL(ci)
+	if err != nil {
+		return 0, c.errorf("Unable to import: %w", err)
+	}
+	return c.Execute(sql)
+}
+
+func (c *Conn) CloudImportSQL(ci *CloudImport) (string, error) {
+	if ci.Table == "" {
+		return "", errors.New("CloudImport requires a Table")
+	}
+	if len(ci.Files) == 0 {
+		return "", errors.New("CloudImport requires at least one file")
+	}
+
+	sql := []string{"IMPORT INTO " + c.qualifiedTable(ci.Schema, ci.Table) + c.columnList(ci.Columns)}
+	src, err := c.cloudLocation(ci.Format, ci.Cloud, ci.URL, ci.Connection, ci.User, ci.Password)
+	if err != nil {
+		return "", err
+	}
+	sql = append(sql, "FROM "+src)
+	sql = append(sql, fileClauses(ci.Files)...)
+	sql = append(sql, ci.CSV.importOpts()...)
+	errClause, err := errorClause(ci.ErrorsInto, ci.ErrorsMode, ci.RejectLimit)
+	if err != nil {
+		return "", err
+	}
+	sql = append(sql, errClause...)
+
+	return strings.Join(sql, "\n"), nil
+}
+
+type CloudExport struct {
+	// The source is either a table (optionally limited to Columns)
+	// or an arbitrary Query.
+	Schema  string
+	Table   string
+	Columns []string
+	Query   string
+
+	Format     string // CSV (the default) or FBV
+	URL        string
+	Connection string
+	Cloud      string
+	User       string
+	Password   string
+
+	Files []string
+	CSV   CSVFormat
+
+	Delimit         string // ALWAYS, NEVER or AUTO
+	WithColumnNames bool   // Write a header row
+	// What to do with existing files. Either REPLACE or TRUNCATE,
+	// otherwise the export fails if a file already exists.
+	FileMode string
+}
+
+type ExportResult struct {
+	RowCount int64
+	Files    []string
+}
+
+func (c *Conn) ExportCloud(ce *CloudExport) (*ExportResult, error) {
+	sql, err := c.CloudExportSQL(ce)
+	if err != nil {
+		return nil, c.errorf("Unable to export: %w", err)
+	}
+	rowCount, err := c.Execute(sql)
+	if err != nil {
+		return nil, err
+	}
+	return &ExportResult{RowCount: rowCount, Files: ce.Files}, nil
+}
+
+func (c *Conn) CloudExportSQL(ce *CloudExport) (string, error) {
+	var src string
+	switch {
+	case ce.Table != "" && ce.Query != "":
+		return "", errors.New("CloudExport requires either a Table or a Query not both")
+	case ce.Table != "":
+		src = c.qualifiedTable(ce.Schema, ce.Table) + c.columnList(ce.Columns)
+	case ce.Query != "":
+		src = "(" + ce.Query + ")"
+	default:
+		return "", errors.New("CloudExport requires a Table or a Query")
+	}
+	if len(ce.Files) == 0 {
+		return "", errors.New("CloudExport requires at least one file")
+	}
+
+	sql := []string{"EXPORT " + src}
+	dst, err := c.cloudLocation(ce.Format, ce.Cloud, ce.URL, ce.Connection, ce.User, ce.Password)
+	if err != nil {
+		return "", err
+	}
+	sql = append(sql, "INTO "+dst)
+	sql = append(sql, fileClauses(ce.Files)...)
+	sql = append(sql, ce.CSV.commonOpts()...)
+	if ce.Delimit != "" {
+		sql = append(sql, "DELIMIT = "+strings.ToUpper(ce.Delimit))
+	}
+	if ce.WithColumnNames {
+		sql = append(sql, "WITH COLUMN NAMES")
+	}
+	switch mode := strings.ToUpper(ce.FileMode); mode {
+	case "":
+	case "REPLACE", "TRUNCATE":
+		sql = append(sql, mode)
+	default:
+		return "", fmt.Errorf("Invalid FileMode: %s", ce.FileMode)
+	}
+
+	return strings.Join(sql, "\n"), nil
+}
+
+/*--- Private Routines ---*/
+
+func (c *Conn) qualifiedTable(schema, table string) string {
+	if schema == "" {
+		return c.QuoteIdent(table)
+	}
+	return c.QuoteIdent(schema) + "." + c.QuoteIdent(table)
+}
+
+func (c *Conn) columnList(cols []string) string {
+	if len(cols) == 0 {
+		return ""
+	}
+	quoted := make([]string, len(cols))
+	for i, col := range cols {
+		quoted[i] = c.QuoteIdent(col)
+	}
+	return " (" + strings.Join(quoted, ", ") + ")"
+}
+
+func (c *Conn) cloudLocation(format, cloud, url, conn, user, pass string) (string, error) {
+	if format == "" {
+		format = "CSV"
+	}
+	format = strings.ToUpper(format)
+	if format != "CSV" && format != "FBV" {
+		return "", fmt.Errorf("Unsupported file format: %s", format)
+	}
+
+	loc := format + " AT "
+	if cloud != "" {
+		loc += "CLOUD " + cloud + " "
+	}
+	switch {
+	case url != "" && conn != "":
+		return "", errors.New("Specify either a URL or a Connection not both")
+	case url != "":
+		loc += "'" + QuoteStr(url) + "'"
+	case conn != "":
+		loc += c.QuoteIdent(conn)
+	default:
+		return "", errors.New("Either a URL or a Connection is required")
+	}
+	if user != "" || pass != "" {
+		loc += fmt.Sprintf(" USER '%s' IDENTIFIED BY '%s'", QuoteStr(user), QuoteStr(pass))
+	}
+	return loc, nil
+}
+
+func fileClauses(files []string) []string {
+	clauses := make([]string, len(files))
+	for i, f := range files {
+		clauses[i] = "FILE '" + QuoteStr(f) + "'"
+	}
+	return clauses
+}
+
+func (f CSVFormat) commonOpts() []string {
+	opts := []string{}
+	if f.Encoding != "" {
+		opts = append(opts, "ENCODING = '"+QuoteStr(f.Encoding)+"'")
+	}
+	if f.RowSeparator != "" {
+		opts = append(opts, "ROW SEPARATOR = '"+QuoteStr(f.RowSeparator)+"'")
+	}
+	if f.ColumnSeparator != "" {
+		opts = append(opts, "COLUMN SEPARATOR = '"+QuoteStr(f.ColumnSeparator)+"'")
+	}
+	if f.ColumnDelimiter != "" {
+		opts = append(opts, "COLUMN DELIMITER = '"+QuoteStr(f.ColumnDelimiter)+"'")
+	}
+	if f.Null != "" {
+		opts = append(opts, "NULL = '"+QuoteStr(f.Null)+"'")
+	}
+	return opts
+}
+
+func (f CSVFormat) importOpts() []string {
+	opts := f.commonOpts()
+	if f.Skip > 0 {
+		opts = append(opts, fmt.Sprintf("SKIP = %d", f.Skip))
+	}
+	if f.Trim != "" {
+		opts = append(opts, strings.ToUpper(f.Trim))
+	}
+	return opts
+}
+
+// The byte that quotes values so that separators within them are ignored
+func (f CSVFormat) quote() byte {
+	if f.ColumnDelimiter == "" {
+		return '"'
+	}
+	return f.ColumnDelimiter[0]
+}
+
+func errorClause(into, mode string, rejectLimit int) ([]string, error) {
+	clause := []string{}
+	switch strings.ToUpper(mode) {
+	case "", "REPLACE", "TRUNCATE":
+	default:
+		return nil, fmt.Errorf("Invalid ErrorsMode: %s", mode)
+	}
+	if into != "" {
+		errs := "ERRORS INTO " + into
+		if mode != "" {
+			errs += " " + strings.ToUpper(mode)
+		}
+		clause = append(clause, errs)
+	}
+	if rejectLimit < 0 {
+		clause = append(clause, "REJECT LIMIT UNLIMITED")
+	} else if rejectLimit > 0 {
+		clause = append(clause, fmt.Sprintf("REJECT LIMIT %d", rejectLimit))
+	}
+	return clause, nil
+}
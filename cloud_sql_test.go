@@ -0,0 +1,98 @@
+package exasol
+
+func (s *testSuite) TestCloudImportSQL() {
+	exa := s.exaConn
+	sql, err := exa.CloudImportSQL(&CloudImport{
+		Schema:      "my_schema",
+		Table:       "my_table",
+		Columns:     []string{"id", "val"},
+		Connection:  "my_bucket",
+		Files:       []string{"a.csv", "b*.csv"},
+		CSV:         CSVFormat{ColumnSeparator: ";", Encoding: "UTF-8", Skip: 1},
+		ErrorsInto:  "my_schema.errors",
+		ErrorsMode:  "replace",
+		RejectLimit: 10,
+	})
+	s.Nil(err)
+	s.Equal(`IMPORT INTO my_schema.my_table (id, val)
+FROM CSV AT my_bucket
+FILE 'a.csv'
+FILE 'b*.csv'
+ENCODING = 'UTF-8'
+COLUMN SEPARATOR = ';'
+SKIP = 1
+ERRORS INTO my_schema.errors REPLACE
+REJECT LIMIT 10`, sql)
+
+	sql, err = exa.CloudImportSQL(&CloudImport{
+		Table:    "t",
+		URL:      "https://bucket.s3.amazonaws.com",
+		User:     "key",
+		Password: "it's secret",
+		Files:    []string{"t.csv"},
+	})
+	s.Nil(err)
+	s.Equal(`IMPORT INTO t
+FROM CSV AT 'https://bucket.s3.amazonaws.com' USER 'key' IDENTIFIED BY 'it''s secret'
+FILE 't.csv'`, sql)
+
+	_, err = exa.CloudImportSQL(&CloudImport{Table: "t", Files: []string{"t.csv"}})
+	if s.Error(err) {
+		s.Contains(err.Error(), "URL or a Connection")
+	}
+	_, err = exa.CloudImportSQL(&CloudImport{Table: "t", URL: "http://x"})
+	if s.Error(err) {
+		s.Contains(err.Error(), "at least one file")
+	}
+	_, err = exa.CloudImportSQL(&CloudImport{
+		Table: "t", URL: "http://x", Files: []string{"t.csv"},
+		ErrorsInto: "errors", ErrorsMode: "append",
+	})
+	if s.Error(err) {
+		s.Contains(err.Error(), "Invalid ErrorsMode")
+	}
+}
+
+func (s *testSuite) TestCloudExportSQL() {
+	exa := s.exaConn
+	sql, err := exa.CloudExportSQL(&CloudExport{
+		Schema:          "my_schema",
+		Table:           "my_table",
+		Connection:      "my_bucket",
+		Files:           []string{"out.csv"},
+		CSV:             CSVFormat{ColumnDelimiter: `"`, Encoding: "UTF-8"},
+		Delimit:         "always",
+		WithColumnNames: true,
+		FileMode:        "truncate",
+	})
+	s.Nil(err)
+	s.Equal(`EXPORT my_schema.my_table
+INTO CSV AT my_bucket
+FILE 'out.csv'
+ENCODING = 'UTF-8'
+COLUMN DELIMITER = '"'
+DELIMIT = ALWAYS
+WITH COLUMN NAMES
+TRUNCATE`, sql)
+
+	sql, err = exa.CloudExportSQL(&CloudExport{
+		Query: "SELECT 1",
+		URL:   "https://bucket.s3.amazonaws.com",
+		Files: []string{"one.csv"},
+	})
+	s.Nil(err)
+	s.Equal(`EXPORT (SELECT 1)
+INTO CSV AT 'https://bucket.s3.amazonaws.com'
+FILE 'one.csv'`, sql)
+
+	_, err = exa.CloudExportSQL(&CloudExport{Table: "t", Query: "SELECT 1"})
+	if s.Error(err) {
+		s.Contains(err.Error(), "not both")
+	}
+	_, err = exa.CloudExportSQL(&CloudExport{
+		Table: "t", URL: "http://x", Files: []string{"t.csv"}, FileMode: "append",
+	})
+	if s.Error(err) {
+		s.Contains(err.Error(), "Invalid FileMode")
+	}
+}
@@ -0,0 +1,40 @@
+/*
+	encoding/json is the dominant cost of large fetches and imports so the
+	default websocket handler's JSON encoding can be swapped out for a
+	faster encoding/json compatible library via ConnConf.Codec e.g.
+
+	    import jsoniter "github.com/json-iterator/go"
+
+	    conf.Codec = jsoniter.ConfigCompatibleWithStandardLibrary
+
+	Most such libraries' configs satisfy Codec as-is. Any Codec must
+	honour the encoding/json struct tags and the json.Marshaler and
+	json.Unmarshaler interfaces, which some of this package's types rely on.
+
+
+	AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import "encoding/json"
+
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+/*--- Private Routines ---*/
+
+type stdCodec struct{}
+
+func (stdCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (stdCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
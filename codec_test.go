@@ -0,0 +1,36 @@
+package exasol
+
+import (
+	"encoding/json"
+	"sync/atomic"
+)
+
+type countingCodec struct {
+	marshals, unmarshals int32
+}
+
+func (cc *countingCodec) Marshal(v interface{}) ([]byte, error) {
+	atomic.AddInt32(&cc.marshals, 1)
+	return json.Marshal(v)
+}
+
+func (cc *countingCodec) Unmarshal(data []byte, v interface{}) error {
+	atomic.AddInt32(&cc.unmarshals, 1)
+	return json.Unmarshal(data, v)
+}
+
+func (s *testSuite) TestCodec() {
+	codec := &countingCodec{}
+	conf := s.connConf()
+	conf.Codec = codec
+	c, err := Connect(conf)
+	s.Require().NoError(err)
+	defer c.Disconnect()
+
+	got, err := c.FetchSlice("SELECT level FROM dual CONNECT BY level <= 3")
+	s.NoError(err)
+	s.Len(got, 3)
+	s.True(atomic.LoadInt32(&codec.marshals) > 0, "Requests encoded by the codec")
+	s.True(atomic.LoadInt32(&codec.unmarshals) > 0, "Responses decoded by the codec")
+	s.Equal(atomic.LoadInt32(&codec.marshals), atomic.LoadInt32(&codec.unmarshals))
+}
@@ -0,0 +1,97 @@
+/*
+	SendCommand is an escape hatch for websocket API commands that this
+	package doesn't wrap (yet) e.g.
+
+	    var res struct {
+	        ResponseData struct {
+	            Hosts []string `json:"nodes"`
+	        } `json:"responseData"`
+	    }
+	    err := conn.SendCommand(ctx, map[string]interface{}{
+	        "command": "getHosts",
+	        "hostIp":  "10.0.0.1",
+	    }, &res)
+
+	The request can be anything that marshals to a JSON object with a
+	"command" key. The response is unmarshalled into res (which may be
+	nil) once the status has been checked so only the fields of interest
+	need be declared. Commands are queued, timed out (see CommandTimeouts)
+	and logged like any other and server errors are returned as usual.
+
+	See https://github.com/exasol/websocket-api for the available commands.
+
+
+	AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"context"
+	"encoding/json"
+)
+
+func (c *Conn) SendCommand(ctx context.Context, req interface{}, res interface{}) error {
+	if err := c.checkOpen(); err != nil {
+		return err
+	}
+	raw, err := json.Marshal(req)
+	if err != nil {
+		return c.errorf("Unable to marshal command: %w", err)
+	}
+	var cmd struct {
+		Command string `json:"command"`
+	}
+	if err = json.Unmarshal(raw, &cmd); err != nil || cmd.Command == "" {
+		return c.error("SendCommand's request must be a JSON object with a command")
+	}
+
+	ctx, cancel := c.callContext(ctx)
+	defer cancel()
+	resp := &rawResponse{}
+	err = c.sendContext(ctx, &rawCommand{Command: cmd.Command, raw: raw}, resp)
+	if err != nil {
+		return c.errorf("Unable to %s: %w", cmd.Command, err)
+	}
+	if res == nil {
+		return nil
+	}
+	err = json.Unmarshal(resp.raw, res)
+	if err != nil {
+		return c.errorf("Unable to unmarshal %s response: %w", cmd.Command, err)
+	}
+	return nil
+}
+
+/*--- Private Routines ---*/
+
+// The Command field is what the rest of the package (e.g. the debug logs
+// and CommandTimeouts) uses to identify the request.
+type rawCommand struct {
+	Command string
+	raw     json.RawMessage
+}
+
+func (rc *rawCommand) MarshalJSON() ([]byte, error) {
+	return rc.raw, nil
+}
+
+// Keeps the raw JSON for unmarshalling into the caller's response
+// whilst still exposing the status, exception and attributes.
+type rawResponse struct {
+	response
+	raw json.RawMessage
+}
+
+func (rr *rawResponse) UnmarshalJSON(data []byte) error {
+	rr.raw = append(json.RawMessage(nil), data...)
+	return json.Unmarshal(data, &rr.response)
+}
@@ -0,0 +1,31 @@
+package exasol
+
+import (
+	"context"
+	"errors"
+)
+
+func (s *testSuite) TestSendCommand() {
+	exa := s.exaConn
+	var res struct {
+		Attributes struct {
+			CurrentSchema string `json:"currentSchema"`
+			Autocommit    bool   `json:"autocommit"`
+		} `json:"attributes"`
+	}
+	err := exa.SendCommand(context.Background(),
+		map[string]interface{}{"command": "getAttributes"}, &res)
+	s.NoError(err)
+	s.Equal(exa.Attributes().CurrentSchema, res.Attributes.CurrentSchema)
+	s.Equal(exa.Attributes().Autocommit, res.Attributes.Autocommit)
+
+	err = exa.SendCommand(context.Background(), &request{Command: "getAttributes"}, nil)
+	s.NoError(err)
+
+	exa.Conf.SuppressError = true
+	err = exa.SendCommand(context.Background(), map[string]int{"foo": 1}, nil)
+	s.EqualError(err, "SendCommand's request must be a JSON object with a command")
+	err = exa.SendCommand(context.Background(), map[string]string{"command": "noSuchCommand"}, nil)
+	var exaErr *ExaError
+	s.True(errors.As(err, &exaErr), "Server errors are returned")
+}
@@ -0,0 +1,124 @@
+/*
+	A functional options alternative to populating ConnConf directly.
+	New options can be added without callers having to wade through
+	the ever-growing ConnConf struct.
+
+	    conf := exasol.NewConfig("exasol.example.com", 8563,
+	        exasol.WithCredentials("user", "pass"),
+	        exasol.WithTLSFingerprint("AB:CD:..."),
+	        exasol.WithLogger(logger),
+	    )
+	    conn, err := exasol.Connect(conf)
+
+	The result is an ordinary ConnConf so anything without an option
+	can still be set on it before connecting.
+
+
+	AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"crypto/tls"
+	"net/http"
+	"time"
+)
+
+type ConfOption func(*ConnConf)
+
+func NewConfig(host string, port uint16, opts ...ConfOption) ConnConf {
+	conf := ConnConf{
+		Host: host,
+		Port: port,
+	}
+	for _, opt := range opts {
+		opt(&conf)
+	}
+	return conf
+}
+
+func WithCredentials(user, pass string) ConfOption {
+	return func(cc *ConnConf) {
+		cc.Username = user
+		cc.Password = pass
+	}
+}
+
+func WithCredentialProvider(provider CredentialProvider) ConfOption {
+	return func(cc *ConnConf) { cc.Credentials = provider }
+}
+
+func WithTLS(cfg *tls.Config) ConfOption {
+	return func(cc *ConnConf) { cc.TLSConfig = cfg }
+}
+
+// Verifies the server certificate by its SHA256 fingerprint rather than
+// its chain. Enables TLS (with a default config) if it isn't already.
+func WithTLSFingerprint(fingerprint string) ConfOption {
+	return func(cc *ConnConf) {
+		cc.TLSFingerprint = fingerprint
+		if cc.TLSConfig == nil {
+			cc.TLSConfig = &tls.Config{}
+		}
+	}
+}
+
+func WithRequireTLS() ConfOption {
+	return func(cc *ConnConf) { cc.RequireTLS = true }
+}
+
+func WithLogger(logger Logger) ConfOption {
+	return func(cc *ConnConf) { cc.Logger = logger }
+}
+
+// The schema opened at login. (Not to be confused with the
+// WithSchema ExecOption which applies to a single statement.)
+func WithDefaultSchema(schema string) ConfOption {
+	return func(cc *ConnConf) { cc.Schema = schema }
+}
+
+func WithClientName(name, version string) ConfOption {
+	return func(cc *ConnConf) {
+		cc.ClientName = name
+		cc.ClientVersion = version
+	}
+}
+
+func WithConnectTimeout(timeout time.Duration) ConfOption {
+	return func(cc *ConnConf) { cc.ConnectTimeout = timeout }
+}
+
+func WithQueryTimeout(timeout time.Duration) ConfOption {
+	return func(cc *ConnConf) { cc.QueryTimeout = timeout }
+}
+
+func WithCachePrepStmts() ConfOption {
+	return func(cc *ConnConf) { cc.CachePrepStmts = true }
+}
+
+func WithWSHandler(handler WSHandlerV2) ConfOption {
+	return func(cc *ConnConf) { cc.WSHandlerV2 = handler }
+}
+
+// Adds a header to the websocket handshake (e.g. for an authenticating proxy)
+func WithHeader(key, value string) ConfOption {
+	return func(cc *ConnConf) {
+		if cc.Headers == nil {
+			cc.Headers = http.Header{}
+		}
+		cc.Headers.Add(key, value)
+	}
+}
+
+func WithSubprotocols(protocols ...string) ConfOption {
+	return func(cc *ConnConf) { cc.Subprotocols = protocols }
+}
@@ -0,0 +1,48 @@
+package exasol
+
+import "time"
+
+func (s *testSuite) TestNewConfig() {
+	conf := NewConfig(*testHost, uint16(*testPort),
+		WithCredentials("SYS", *testPass),
+		WithLogger(s.log),
+		WithDefaultSchema(s.schema),
+		WithQueryTimeout(30*time.Second),
+		WithCachePrepStmts(),
+	)
+	s.Equal("SYS", conf.Username)
+	s.Equal(30*time.Second, conf.QueryTimeout)
+	s.True(conf.CachePrepStmts)
+	s.Nil(conf.TLSConfig)
+
+	c, err := Connect(conf)
+	s.Require().NoError(err)
+	defer c.Disconnect()
+	got, err := c.FetchSlice("SELECT CURRENT_SCHEMA FROM dual")
+	s.NoError(err)
+	s.Equal([][]interface{}{{"TEST"}}, got)
+
+	conf = NewConfig("localhost", 8563, WithTLSFingerprint("ab:cd"))
+	s.NotNil(conf.TLSConfig, "Fingerprint enables TLS")
+	s.Equal("ab:cd", conf.TLSFingerprint)
+}
+
+func (s *testSuite) TestEffectiveConfig() {
+	conf := s.connConf()
+	conf.Timeout = 15
+	conf.KeepPassword = true
+	c, err := Connect(conf)
+	s.Require().NoError(err)
+	defer c.Disconnect()
+
+	eff := c.EffectiveConfig()
+	s.Equal(64*1024*1024, eff.FetchReqSize)
+	s.Equal(15*time.Second, eff.QueryTimeout)
+	s.Equal(uint32(0), eff.Timeout)
+	s.Empty(eff.Password)
+	s.NotNil(eff.Logger)
+
+	s.Equal(0, c.Conf.FetchReqSize, "Conf isn't modified")
+	s.Equal(time.Duration(0), c.Conf.QueryTimeout)
+	s.Equal(*testPass, c.Conf.Password)
+}
@@ -0,0 +1,274 @@
+/*
+	Streams the result of a query into a table in another database via
+	database/sql e.g. for Exasol to Postgres sync jobs:
+
+	    pg, err := sql.Open("postgres", dsn)
+	    n, err := conn.CopyTo(ctx, pg, "public.orders", "SELECT * FROM orders",
+	        exasol.WithDollarPlaceholders())
+
+	Rows are inserted in batches of multi-row INSERTs within a single
+	transaction on the destination so a failed copy leaves nothing behind.
+	The table (optionally schema qualified) and column names are quoted
+	with QuoteIdent. Names which are already quoted are left as is e.g.
+	to use double quotes for Postgres.
+	Integral DECIMALs are mapped to int64, DOUBLEs to float64 and DATEs
+	and TIMESTAMPs to time.Time. Everything else is passed as is.
+
+
+	AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// Drivers typically limit the number of placeholders in a statement
+const maxCopyPlaceholders = 65535
+
+type CopyConf struct {
+	// Rows per INSERT. Defaults to 1000.
+	BatchSize int
+	// Renders the n-th (1-based) placeholder. Defaults to "?".
+	Placeholder func(n int) string
+	// The destination columns. Defaults to the query's column names.
+	Columns []string
+	// Binds for the query
+	Binds []interface{}
+}
+
+type CopyOption func(*CopyConf)
+
+func WithCopyBatchSize(n int) CopyOption {
+	return func(cc *CopyConf) { cc.BatchSize = n }
+}
+
+// For destinations using $1, $2 ... such as Postgres
+func WithDollarPlaceholders() CopyOption {
+	return func(cc *CopyConf) {
+		cc.Placeholder = func(n int) string { return fmt.Sprintf("$%d", n) }
+	}
+}
+
+func WithCopyColumns(cols ...string) CopyOption {
+	return func(cc *CopyConf) { cc.Columns = cols }
+}
+
+func WithCopyBinds(binds ...interface{}) CopyOption {
+	return func(cc *CopyConf) { cc.Binds = binds }
+}
+
+// Returns the number of rows copied
+func (c *Conn) CopyTo(ctx context.Context, dst *sql.DB, dstTable string, sql string, opts ...CopyOption) (int64, error) {
+	cc := newCopyConf(opts)
+	ec := &ExecConf{Context: ctx}
+	if len(cc.Binds) > 0 {
+		ec.Binds = [][]interface{}{cc.Binds}
+	}
+	rs, err := c.executeQuery(sql, ec)
+	if err != nil {
+		return 0, err
+	}
+
+	cols := cc.Columns
+	if len(cols) == 0 {
+		for _, col := range rs.Columns {
+			cols = append(cols, col.Name)
+		}
+	} else if len(cols) != len(rs.Columns) {
+		if rs.ResultSetHandle > 0 {
+			c.closeResultSet(rs)
+		}
+		return 0, c.errorf("CopyTo has %d destination columns for %d query columns", len(cols), len(rs.Columns))
+	}
+	batchSize := cc.BatchSize
+	if batchSize*len(cols) > maxCopyPlaceholders {
+		batchSize = maxCopyPlaceholders / len(cols)
+	}
+	if batchSize < 1 {
+		batchSize = 1 // Too many columns for the limit but try anyway
+	}
+	quotedCols := make([]string, len(cols))
+	for i, col := range cols {
+		quotedCols[i] = c.QuoteIdent(col)
+	}
+	table := c.qualifiedTable("", dstTable)
+	if i := strings.Index(dstTable, "."); i > 0 {
+		table = c.qualifiedTable(dstTable[:i], dstTable[i+1:])
+	}
+
+	tx, err := dst.BeginTx(ctx, nil)
+	if err != nil {
+		if rs.ResultSetHandle > 0 {
+			c.closeResultSet(rs)
+		}
+		return 0, c.errorf("Unable to begin CopyTo transaction: %w", err)
+	}
+	cp := &copier{
+		ctx:       ctx,
+		tx:        tx,
+		cc:        cc,
+		table:     table,
+		cols:      quotedCols,
+		srcCols:   rs.Columns,
+		batchSize: batchSize,
+	}
+	defer cp.close()
+
+	if rs.ResultSetHandle <= 0 {
+		err = cp.add(rs.Data)
+	} else {
+		fetchCtx, cancel := c.callContext(ctx)
+		defer cancel()
+		err = c.fetchBlocks(fetchCtx, rs, cp.add)
+	}
+	if err == nil {
+		err = cp.flush()
+	}
+	if err != nil {
+		tx.Rollback()
+		return 0, c.errorf("Unable to copy to %s: %w", dstTable, err)
+	}
+	err = tx.Commit()
+	if err != nil {
+		return 0, c.errorf("Unable to commit copy to %s: %w", dstTable, err)
+	}
+	return cp.copied, nil
+}
+
+/*--- Private Routines ---*/
+
+func newCopyConf(opts []CopyOption) *CopyConf {
+	cc := &CopyConf{}
+	for _, opt := range opts {
+		opt(cc)
+	}
+	if cc.BatchSize <= 0 {
+		cc.BatchSize = 1000
+	}
+	if cc.Placeholder == nil {
+		cc.Placeholder = func(int) string { return "?" }
+	}
+	return cc
+}
+
+type copier struct {
+	ctx       context.Context
+	tx        *sql.Tx
+	cc        *CopyConf
+	table     string
+	cols      []string
+	srcCols   []Column
+	batchSize int
+	stmt      *sql.Stmt // For full batches
+	pending   []interface{}
+	copied    int64
+}
+
+// Takes columnar data as fetched
+func (cp *copier) add(data [][]interface{}) error {
+	if len(data) == 0 {
+		return nil
+	}
+	for _, row := range Transpose(data) {
+		r := FetchResult{Data: row, Columns: cp.srcCols}
+		for i := range row {
+			v, err := copyValue(r, i)
+			if err != nil {
+				return err
+			}
+			cp.pending = append(cp.pending, v)
+		}
+		if len(cp.pending) == cp.batchSize*len(cp.cols) {
+			err := cp.flush()
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (cp *copier) flush() error {
+	rows := len(cp.pending) / len(cp.cols)
+	if rows == 0 {
+		return nil
+	}
+	var err error
+	if rows == cp.batchSize {
+		if cp.stmt == nil {
+			cp.stmt, err = cp.tx.PrepareContext(cp.ctx, cp.insertSQL(rows))
+			if err != nil {
+				return err
+			}
+		}
+		_, err = cp.stmt.ExecContext(cp.ctx, cp.pending...)
+	} else {
+		_, err = cp.tx.ExecContext(cp.ctx, cp.insertSQL(rows), cp.pending...)
+	}
+	if err != nil {
+		return err
+	}
+	cp.copied += int64(rows)
+	cp.pending = cp.pending[:0]
+	return nil
+}
+
+func (cp *copier) insertSQL(rows int) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "INSERT INTO %s (%s) VALUES ", cp.table, strings.Join(cp.cols, ", "))
+	n := 0
+	for r := 0; r < rows; r++ {
+		if r > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString("(")
+		for i := range cp.cols {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			n++
+			sb.WriteString(cp.cc.Placeholder(n))
+		}
+		sb.WriteString(")")
+	}
+	return sb.String()
+}
+
+func (cp *copier) close() {
+	if cp.stmt != nil {
+		cp.stmt.Close()
+	}
+}
+
+func copyValue(r FetchResult, i int) (interface{}, error) {
+	if r.IsNull(i) {
+		return nil, nil
+	}
+	dt := r.Columns[i].DataType
+	switch dt.Type {
+	case "DECIMAL":
+		if dt.Scale == 0 {
+			if n, err := r.Int64(i); err == nil {
+				return n, nil
+			}
+		}
+	case "DOUBLE":
+		return r.Float64(i)
+	case "DATE", "TIMESTAMP":
+		return r.Time(i)
+	}
+	return r.Data[i], nil
+}
@@ -0,0 +1,110 @@
+package exasol
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A database/sql driver which records the inserted rows
+type recordingDriver struct {
+	mux       sync.Mutex
+	inserts   []string
+	rows      [][]driver.Value
+	committed bool
+	failAfter int
+}
+
+func (d *recordingDriver) Open(string) (driver.Conn, error) { return &recordingConn{d}, nil }
+
+type recordingConn struct{ d *recordingDriver }
+
+func (c *recordingConn) Prepare(query string) (driver.Stmt, error) {
+	return &recordingStmt{c.d, query}, nil
+}
+func (c *recordingConn) Close() error              { return nil }
+func (c *recordingConn) Begin() (driver.Tx, error) { return &recordingTx{c.d}, nil }
+
+type recordingTx struct{ d *recordingDriver }
+
+func (t *recordingTx) Commit() error {
+	t.d.mux.Lock()
+	defer t.d.mux.Unlock()
+	t.d.committed = true
+	return nil
+}
+func (t *recordingTx) Rollback() error { return nil }
+
+type recordingStmt struct {
+	d     *recordingDriver
+	query string
+}
+
+func (s *recordingStmt) Close() error  { return nil }
+func (s *recordingStmt) NumInput() int { return strings.Count(s.query, "?") }
+func (s *recordingStmt) Query([]driver.Value) (driver.Rows, error) {
+	return nil, errors.New("Not supported")
+}
+func (s *recordingStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.d.mux.Lock()
+	defer s.d.mux.Unlock()
+	if s.d.failAfter > 0 && len(s.d.rows) >= s.d.failAfter {
+		return nil, errors.New("Insert failed")
+	}
+	s.d.inserts = append(s.d.inserts, s.query)
+	cols := strings.Count(s.query[:strings.Index(s.query, "VALUES")], ",") + 1
+	for i := 0; i < len(args); i += cols {
+		s.d.rows = append(s.d.rows, args[i:i+cols])
+	}
+	return driver.RowsAffected(len(args) / cols), nil
+}
+
+func (s *testSuite) TestCopyTo() {
+	drv := &recordingDriver{}
+	sql.Register("exasol-copy-test", drv)
+	dst, err := sql.Open("exasol-copy-test", "")
+	s.Require().NoError(err)
+	defer dst.Close()
+
+	query := `SELECT level AS id, 'x' || level AS name, CAST(level AS DOUBLE) / 2 AS half,
+	                 ADD_DAYS(DATE '2020-01-01', level) AS day
+	          FROM dual CONNECT BY level <= 2500`
+	n, err := s.exaConn.CopyTo(context.Background(), dst, "target", query, WithCopyBatchSize(1000))
+	s.Require().NoError(err)
+	s.Equal(int64(2500), n)
+	s.True(drv.committed)
+	s.Require().Len(drv.inserts, 3)
+	s.True(strings.HasPrefix(drv.inserts[0], "INSERT INTO target (ID, NAME, HALF, "+s.exaConn.QuoteIdent("DAY")+") VALUES (?, ?, ?, ?), "))
+	s.Require().Len(drv.rows, 2500)
+	s.Equal([]driver.Value{
+		int64(1), "x1", 0.5, time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+	}, drv.rows[0])
+
+	// A failure on the destination rolls back
+	fail := &recordingDriver{failAfter: 1000}
+	sql.Register("exasol-copy-test-fail", fail)
+	dst, err = sql.Open("exasol-copy-test-fail", "")
+	s.Require().NoError(err)
+	defer dst.Close()
+	_, err = s.exaConn.CopyTo(context.Background(), dst, "target", query,
+		WithCopyBatchSize(1000), WithCopyColumns("a", "b", "c", "d"))
+	s.Error(err)
+	s.False(fail.committed)
+	s.Empty(s.exaConn.OpenHandles(), "Result set was closed")
+
+	// Names are quoted unless they already are
+	quoted := &recordingDriver{}
+	sql.Register("exasol-copy-test-quoted", quoted)
+	dst, err = sql.Open("exasol-copy-test-quoted", "")
+	s.Require().NoError(err)
+	defer dst.Close()
+	_, err = s.exaConn.CopyTo(context.Background(), dst, `"public".target`, "SELECT 1 AS a, 2 AS b",
+		WithCopyColumns("my id", `"Val"`))
+	s.Require().NoError(err)
+	s.Require().Len(quoted.inserts, 1)
+	s.Equal(`INSERT INTO "public".target ([MY ID], "Val") VALUES (?, ?)`, quoted.inserts[0])
+}
@@ -0,0 +1,270 @@
+/*
+	Credential providers allow the credentials to be looked up when
+	connecting rather than being pinned in the ConnConf at startup e.g.
+
+	    conf.Credentials = &exasol.VaultCredentials{
+	        Addr:  "https://vault.example.com:8200",
+	        Token: os.Getenv("VAULT_TOKEN"),
+	        Path:  "secret/data/exasol/etl",
+	    }
+
+	The provider is called on every Connect and Reconnect so rotated
+	secrets are picked up. If the server rejects the credentials it's
+	called once more (in case the secret was rotated in between).
+
+
+	AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Either a Username and Password or an OpenID AccessToken or
+// RefreshToken (which require ProtocolVersion 3+).
+type Credentials struct {
+	Username     string
+	Password     string
+	AccessToken  string
+	RefreshToken string
+}
+
+type CredentialProvider interface {
+	Credentials(context.Context) (*Credentials, error)
+}
+
+// Reads the credentials from environment variables
+type EnvCredentials struct {
+	UsernameVar string // Defaults to EXA_USER
+	PasswordVar string // Defaults to EXA_PASSWORD
+	TokenVar    string // Optional access token variable
+}
+
+func (e *EnvCredentials) Credentials(ctx context.Context) (*Credentials, error) {
+	userVar, passVar := e.UsernameVar, e.PasswordVar
+	if userVar == "" {
+		userVar = "EXA_USER"
+	}
+	if passVar == "" {
+		passVar = "EXA_PASSWORD"
+	}
+	creds := &Credentials{
+		Username: os.Getenv(userVar),
+		Password: os.Getenv(passVar),
+	}
+	if e.TokenVar != "" {
+		creds.AccessToken = os.Getenv(e.TokenVar)
+	}
+	if creds.AccessToken == "" && (creds.Username == "" || creds.Password == "") {
+		return nil, fmt.Errorf("%s and %s must be set", userVar, passVar)
+	}
+	return creds, nil
+}
+
+// Reads the credentials from a JSON file each time they're needed e.g.
+//
+//	{"username": "etl", "password": "..."}
+//
+// This suits secrets mounted into containers which are rotated in place.
+type FileCredentials struct {
+	Path string
+}
+
+func (f *FileCredentials) Credentials(ctx context.Context) (*Credentials, error) {
+	data, err := ioutil.ReadFile(f.Path)
+	if err != nil {
+		return nil, err
+	}
+	return parseSecret(data)
+}
+
+// Reads the credentials from a HashiCorp Vault KV secret
+// (either version 1 or 2 of the secrets engine).
+type VaultCredentials struct {
+	Addr   string // e.g. https://vault.example.com:8200
+	Token  string
+	Path   string       // e.g. secret/data/exasol for KV v2
+	Client *http.Client // Optional
+}
+
+func (v *VaultCredentials) Credentials(ctx context.Context) (*Credentials, error) {
+	url := strings.TrimRight(v.Addr, "/") + "/v1/" + strings.TrimLeft(v.Path, "/")
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", v.Token)
+	client := v.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Vault returned %s", resp.Status)
+	}
+
+	secret := struct {
+		Data json.RawMessage `json:"data"`
+	}{}
+	err = json.Unmarshal(body, &secret)
+	if err != nil {
+		return nil, err
+	}
+	// KV v2 nests the secret inside data.data
+	v2 := struct {
+		Data     json.RawMessage `json:"data"`
+		Metadata json.RawMessage `json:"metadata"`
+	}{}
+	if json.Unmarshal(secret.Data, &v2) == nil && v2.Data != nil && v2.Metadata != nil {
+		return parseSecret(v2.Data)
+	}
+	return parseSecret(secret.Data)
+}
+
+// Reads the credentials from an AWS Secrets Manager secret in the
+// standard {"username": ..., "password": ...} format.
+// To avoid depending on the AWS SDK you supply the lookup e.g.
+//
+//	GetSecretString: func(ctx context.Context, id string) (string, error) {
+//	    out, err := smClient.GetSecretValue(ctx,
+//	        &secretsmanager.GetSecretValueInput{SecretId: &id})
+//	    if err != nil {
+//	        return "", err
+//	    }
+//	    return *out.SecretString, nil
+//	},
+type SecretsManagerCredentials struct {
+	SecretID        string
+	GetSecretString func(ctx context.Context, secretID string) (string, error)
+}
+
+func (s *SecretsManagerCredentials) Credentials(ctx context.Context) (*Credentials, error) {
+	if s.GetSecretString == nil {
+		return nil, errors.New("SecretsManagerCredentials requires GetSecretString")
+	}
+	secret, err := s.GetSecretString(ctx, s.SecretID)
+	if err != nil {
+		return nil, err
+	}
+	return parseSecret([]byte(secret))
+}
+
+/*--- Private Routines ---*/
+
+// The previous credentials having been rejected means
+// the user should be prompted (if that's an option).
+func (c *Conn) credentials(rejected bool) (*Credentials, error) {
+	var creds *Credentials
+	if c.Conf.Credentials != nil && !(rejected && c.Conf.PromptCredentials != nil) {
+		var err error
+		creds, err = c.Conf.Credentials.Credentials(c.ctx)
+		if err != nil && c.Conf.PromptCredentials == nil {
+			return nil, fmt.Errorf("Unable to get credentials: %w", err)
+		} else if err != nil {
+			c.log.Warning("Unable to get credentials: ", err)
+		}
+	} else if c.prompted != nil && !rejected {
+		creds = c.prompted
+	} else if !rejected {
+		creds = &Credentials{
+			Username: c.Conf.Username,
+			Password: c.Conf.Password,
+		}
+	}
+
+	missing := creds == nil ||
+		creds.AccessToken == "" && creds.RefreshToken == "" &&
+			(creds.Username == "" || creds.Password == "")
+	if missing && c.Conf.PromptCredentials != nil {
+		user, pass, err := c.Conf.PromptCredentials(c.ctx)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to prompt for credentials: %w", err)
+		}
+		c.prompted = &Credentials{Username: user, Password: pass}
+		return c.prompted, nil
+	}
+	if creds == nil {
+		return nil, errors.New("No credentials")
+	}
+	if missing && c.forgotPass {
+		return nil, errors.New(
+			"The password was cleared after login. " +
+				"Set ConnConf.KeepPassword or use a CredentialProvider to reconnect",
+		)
+	}
+	return creds, nil
+}
+
+func (c *Conn) forgetPassword() {
+	if c.Conf.Password != "" || c.prompted != nil {
+		c.forgotPass = true
+	}
+	c.Conf.Password = ""
+	c.prompted = nil
+}
+
+func zeroBytes(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+func parseSecret(data []byte) (*Credentials, error) {
+	secret := struct {
+		Username     string `json:"username"`
+		User         string `json:"user"`
+		Password     string `json:"password"`
+		AccessToken  string `json:"accessToken"`
+		RefreshToken string `json:"refreshToken"`
+	}{}
+	err := json.Unmarshal(data, &secret)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to parse secret: %w", err)
+	}
+	creds := &Credentials{
+		Username:     secret.Username,
+		Password:     secret.Password,
+		AccessToken:  secret.AccessToken,
+		RefreshToken: secret.RefreshToken,
+	}
+	if creds.Username == "" {
+		creds.Username = secret.User
+	}
+	if creds.AccessToken == "" && creds.RefreshToken == "" &&
+		(creds.Username == "" || creds.Password == "") {
+		return nil, errors.New("Secret doesn't contain a username and password")
+	}
+	return creds, nil
+}
+
+var authErrorRE = regexp.MustCompile(`(?i)authentication failed|invalid (user|password)`)
+
+func isAuthError(err error) bool {
+	return errors.Is(err, ErrAuthFailed) || authErrorRE.MatchString(err.Error())
+}
@@ -0,0 +1,189 @@
+package exasol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+)
+
+type testCredentials struct {
+	creds []*Credentials
+	calls int
+}
+
+func (t *testCredentials) Credentials(ctx context.Context) (*Credentials, error) {
+	c := t.creds[t.calls%len(t.creds)]
+	t.calls++
+	return c, nil
+}
+
+func (s *testSuite) TestCredentialProvider() {
+	conf := s.connConf()
+	conf.SuppressError = true
+	conf.Username = ""
+	conf.Password = ""
+
+	// Rejected credentials are refreshed once
+	good := &Credentials{Username: "SYS", Password: *testPass}
+	bad := &Credentials{Username: "SYS", Password: "wrong"}
+	provider := &testCredentials{creds: []*Credentials{bad, good}}
+	conf.Credentials = provider
+	c, err := Connect(conf)
+	if s.NoError(err) {
+		s.Equal(2, provider.calls)
+		sessionID := c.SessionID
+		s.NoError(c.Reconnect())
+		s.Equal(3, provider.calls, "Reconnect gets fresh credentials")
+		s.NotEqual(sessionID, c.SessionID)
+		got, err := c.FetchSlice("SELECT 123")
+		s.NoError(err)
+		s.Equal(float64(123), got[0][0].(float64))
+		c.Disconnect()
+	}
+
+	provider = &testCredentials{creds: []*Credentials{bad}}
+	conf.Credentials = provider
+	_, err = Connect(conf)
+	s.Error(err)
+	s.Equal(2, provider.calls, "Only retried once")
+
+	// File
+	f, err := ioutil.TempFile("", "exasol-creds")
+	s.Require().NoError(err)
+	defer os.Remove(f.Name())
+	fmt.Fprintf(f, `{"username": "SYS", "password": %q}`, *testPass)
+	f.Close()
+	conf.Credentials = &FileCredentials{Path: f.Name()}
+	c, err = Connect(conf)
+	if s.NoError(err) {
+		c.Disconnect()
+	}
+
+	// Env
+	os.Setenv("TEST_EXA_USER", "SYS")
+	os.Setenv("TEST_EXA_PASS", *testPass)
+	defer os.Unsetenv("TEST_EXA_USER")
+	defer os.Unsetenv("TEST_EXA_PASS")
+	conf.Credentials = &EnvCredentials{UsernameVar: "TEST_EXA_USER", PasswordVar: "TEST_EXA_PASS"}
+	c, err = Connect(conf)
+	if s.NoError(err) {
+		c.Disconnect()
+	}
+}
+
+func (s *testSuite) TestForgetPassword() {
+	conf := s.connConf()
+	conf.SuppressError = true
+	c, err := Connect(conf)
+	s.Require().NoError(err)
+	s.Empty(c.Conf.Password, "Cleared after login")
+	err = c.Reconnect()
+	if s.Error(err) {
+		s.Contains(err.Error(), "KeepPassword")
+	}
+	_, err = c.Execute("SELECT 1")
+	s.True(errors.Is(err, ErrConnClosed), "Closed after the failed Reconnect")
+	c.Disconnect()
+
+	conf.KeepPassword = true
+	c, err = Connect(conf)
+	s.Require().NoError(err)
+	s.Equal(*testPass, c.Conf.Password)
+	s.NoError(c.Reconnect())
+	c.Disconnect()
+}
+
+func (s *testSuite) TestVaultCredentials() {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "tok" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/exasol":
+			fmt.Fprint(w, `{"data": {"data": {"username": "u2", "password": "p2"}, "metadata": {}}}`)
+		case "/v1/kv/exasol":
+			fmt.Fprint(w, `{"data": {"username": "u1", "password": "p1"}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	v := &VaultCredentials{Addr: srv.URL, Token: "tok", Path: "secret/data/exasol"}
+	creds, err := v.Credentials(context.Background())
+	if s.NoError(err) {
+		s.Equal(&Credentials{Username: "u2", Password: "p2"}, creds)
+	}
+	v.Path = "kv/exasol"
+	creds, err = v.Credentials(context.Background())
+	if s.NoError(err) {
+		s.Equal(&Credentials{Username: "u1", Password: "p1"}, creds)
+	}
+	v.Token = "nope"
+	_, err = v.Credentials(context.Background())
+	s.Error(err)
+
+	sm := &SecretsManagerCredentials{
+		SecretID: "exasol",
+		GetSecretString: func(ctx context.Context, id string) (string, error) {
+			return `{"username": "u3", "password": "p3", "engine": "exasol"}`, nil
+		},
+	}
+	creds, err = sm.Credentials(context.Background())
+	if s.NoError(err) {
+		s.Equal(&Credentials{Username: "u3", Password: "p3"}, creds)
+	}
+}
+
+func (s *testSuite) TestPromptCredentials() {
+	conf := s.connConf()
+	conf.SuppressError = true
+	prompts := 0
+	conf.PromptCredentials = func(ctx context.Context) (string, string, error) {
+		prompts++
+		return "SYS", *testPass, nil
+	}
+
+	// Missing
+	conf.Password = ""
+	c, err := Connect(conf)
+	if s.NoError(err) {
+		s.Equal(1, prompts)
+		s.NoError(c.Reconnect())
+		s.Equal(2, prompts, "Prompted credentials aren't kept")
+		c.Disconnect()
+	}
+	conf.KeepPassword = true
+	prompts = 0
+	c, err = Connect(conf)
+	if s.NoError(err) {
+		s.NoError(c.Reconnect())
+		s.Equal(1, prompts, "Prompted credentials are reused")
+		c.Disconnect()
+	}
+	conf.KeepPassword = false
+
+	// Rejected
+	prompts = 0
+	conf.Password = "wrong"
+	c, err = Connect(conf)
+	if s.NoError(err) {
+		s.Equal(1, prompts)
+		c.Disconnect()
+	}
+
+	// Aborted by the user
+	conf.PromptCredentials = func(ctx context.Context) (string, string, error) {
+		return "", "", fmt.Errorf("Cancelled")
+	}
+	conf.Password = ""
+	_, err = Connect(conf)
+	if s.Error(err) {
+		s.Contains(err.Error(), "Cancelled")
+	}
+}
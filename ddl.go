@@ -0,0 +1,231 @@
+/*
+	Reconstructs the DDL of a schema from the system catalogs e.g. for
+	backups or for diffing environments:
+
+	    ddl, err := conn.DumpSchemaDDL("my_schema")
+
+	The schema name is matched exactly or failing that uppercased,
+	as unquoted identifiers are. The dump creates and opens the schema and then creates its tables
+	(with their primary keys and distribution keys), views (in the order
+	they were created so that views on views work) and scripts. Scripts
+	are terminated by a "/" line, as EXAplus expects, because their
+	bodies contain semicolons.
+
+	Connections aren't schema objects so they're only included if asked
+	for with WithConnections, in which case all of those visible to the
+	user are (which requires access to EXA_DBA_CONNECTIONS). Their
+	passwords can't be read so they're created with empty ones for you
+	to fill in. They aren't created OR REPLACE so that replaying the dump
+	against a database with them can't wipe their real passwords.
+
+
+	AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type DumpOption func(*dumpConf)
+
+type dumpConf struct {
+	connections bool
+}
+
+// Includes the CREATE CONNECTION statements of all the connections
+func WithConnections() DumpOption {
+	return func(dc *dumpConf) { dc.connections = true }
+}
+
+func (c *Conn) DumpSchemaDDL(schema string, opts ...DumpOption) (string, error) {
+	dc := &dumpConf{}
+	for _, opt := range opts {
+		opt(dc)
+	}
+	name, err := c.catalogSchema(schema)
+	if err != nil {
+		return "", c.errorf("Unable to dump DDL for schema %s: %w", schema, err)
+	}
+	schema = name
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "CREATE SCHEMA IF NOT EXISTS %s;\n", quoteName(schema))
+	fmt.Fprintf(&sb, "OPEN SCHEMA %s;\n", quoteName(schema))
+
+	dumps := []func(*strings.Builder, string) error{
+		c.dumpTables, c.dumpViews, c.dumpScripts,
+	}
+	if dc.connections {
+		dumps = append(dumps, c.dumpConnections)
+	}
+	for _, dump := range dumps {
+		err := dump(&sb, schema)
+		if err != nil {
+			return "", c.errorf("Unable to dump DDL for schema %s: %w", schema, err)
+		}
+	}
+	return sb.String(), nil
+}
+
+/*--- Private Routines ---*/
+
+// Catalog names are exact so they're always quoted
+func quoteName(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// The schema's name as stored in the catalog
+func (c *Conn) catalogSchema(schema string) (string, error) {
+	for _, name := range []string{schema, strings.ToUpper(schema)} {
+		var found string
+		err := c.FetchRow(
+			"SELECT schema_name FROM exa_schemas WHERE schema_name = ?",
+			[]interface{}{name}, &found,
+		)
+		if err == nil {
+			return found, nil
+		} else if !errors.Is(err, ErrNoRows) {
+			return "", err
+		}
+	}
+	return "", fmt.Errorf("Schema %s not found", schema)
+}
+
+func (c *Conn) dumpTables(sb *strings.Builder, schema string) error {
+	cols, err := c.FetchSlice(`
+		SELECT column_table, column_name, column_type, column_default,
+		       column_is_nullable, column_identity, column_is_distribution_key
+		FROM exa_all_columns
+		WHERE column_schema = ? AND column_object_type = 'TABLE'
+		ORDER BY column_table, column_ordinal_position
+	`, []interface{}{schema})
+	if err != nil {
+		return err
+	}
+	pks, err := c.FetchSlice(`
+		SELECT constraint_table, constraint_name, column_name
+		FROM exa_all_constraint_columns
+		WHERE constraint_schema = ? AND constraint_type = 'PRIMARY KEY'
+		ORDER BY constraint_table, ordinal_position
+	`, []interface{}{schema})
+	if err != nil {
+		return err
+	}
+	pkName := map[string]string{}
+	pkCols := map[string][]string{}
+	for _, pk := range pks {
+		table := pk[0].(string)
+		pkName[table] = pk[1].(string)
+		pkCols[table] = append(pkCols[table], quoteName(pk[2].(string)))
+	}
+
+	var table string
+	var defs, distKeys []string
+	flush := func() {
+		if table == "" {
+			return
+		}
+		if len(pkCols[table]) > 0 {
+			defs = append(defs, fmt.Sprintf("CONSTRAINT %s PRIMARY KEY (%s)",
+				quoteName(pkName[table]), strings.Join(pkCols[table], ", ")))
+		}
+		if len(distKeys) > 0 {
+			defs = append(defs, fmt.Sprintf("DISTRIBUTE BY %s", strings.Join(distKeys, ", ")))
+		}
+		fmt.Fprintf(sb, "\nCREATE TABLE %s (\n    %s\n);\n",
+			quoteName(table), strings.Join(defs, ",\n    "))
+	}
+	for _, col := range cols {
+		if col[0].(string) != table {
+			flush()
+			table = col[0].(string)
+			defs, distKeys = nil, nil
+		}
+		def := quoteName(col[1].(string)) + " " + col[2].(string)
+		if col[5] != nil {
+			def += " IDENTITY"
+		} else if col[3] != nil {
+			def += " DEFAULT " + col[3].(string)
+		}
+		if nullable, _ := col[4].(bool); !nullable {
+			def += " NOT NULL"
+		}
+		if dist, _ := col[6].(bool); dist {
+			distKeys = append(distKeys, quoteName(col[1].(string)))
+		}
+		defs = append(defs, def)
+	}
+	flush()
+	return nil
+}
+
+func (c *Conn) dumpViews(sb *strings.Builder, schema string) error {
+	views, err := c.FetchSlice(`
+		SELECT v.view_text
+		FROM exa_all_views v
+		JOIN exa_all_objects o
+		  ON o.root_name = v.view_schema AND o.object_name = v.view_name
+		 AND o.object_type = 'VIEW'
+		WHERE v.view_schema = ?
+		ORDER BY o.created, v.view_name
+	`, []interface{}{schema})
+	if err != nil {
+		return err
+	}
+	for _, v := range views {
+		text := strings.TrimRight(strings.TrimSpace(v[0].(string)), ";")
+		fmt.Fprintf(sb, "\n%s;\n", text)
+	}
+	return nil
+}
+
+func (c *Conn) dumpScripts(sb *strings.Builder, schema string) error {
+	scripts, err := c.FetchSlice(`
+		SELECT script_text
+		FROM exa_all_scripts
+		WHERE script_schema = ?
+		ORDER BY script_name
+	`, []interface{}{schema})
+	if err != nil {
+		return err
+	}
+	for _, s := range scripts {
+		fmt.Fprintf(sb, "\n%s\n/\n", strings.TrimSpace(s[0].(string)))
+	}
+	return nil
+}
+
+func (c *Conn) dumpConnections(sb *strings.Builder, schema string) error {
+	conns, err := c.FetchSlice(`
+		SELECT connection_name, connection_string, user_name
+		FROM exa_dba_connections
+		ORDER BY connection_name
+	`)
+	if err != nil {
+		// Typically a lack of privileges which shouldn't fail the whole dump
+		c.log.Warning("Unable to dump connections: ", err)
+		fmt.Fprintf(sb, "\n-- Connections omitted: %s\n", err)
+		return nil
+	}
+	for _, conn := range conns {
+		fmt.Fprintf(sb, "\nCREATE CONNECTION %s TO '%s'",
+			quoteName(conn[0].(string)), QuoteStr(conn[1].(string)))
+		if conn[2] != nil {
+			fmt.Fprintf(sb, " USER '%s' IDENTIFIED BY ''", QuoteStr(conn[2].(string)))
+		}
+		sb.WriteString("; -- Password not included\n")
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package exasol
+
+import "strings"
+
+func (s *testSuite) TestDumpSchemaDDL() {
+	s.execute(`CREATE TABLE foo (
+		id INT IDENTITY,
+		name VARCHAR(100) DEFAULT 'bar' NOT NULL,
+		CONSTRAINT foo_pk PRIMARY KEY (id),
+		DISTRIBUTE BY id
+	)`)
+	s.execute(`CREATE VIEW foo_v AS SELECT name FROM foo`)
+	s.execute(`CREATE VIEW foo_vv AS SELECT name FROM foo_v`)
+	s.execute(`CREATE SCRIPT foo_s AS
+		local x = 1;
+		return x`)
+
+	ddl, err := s.exaConn.DumpSchemaDDL(s.schema)
+	s.Require().NoError(err)
+	s.Contains(ddl, `OPEN SCHEMA "test";`)
+	s.Contains(ddl, `CREATE TABLE "FOO" (`)
+	s.Contains(ddl, `"ID" DECIMAL(18,0) IDENTITY NOT NULL`)
+	s.Contains(ddl, `"NAME" VARCHAR(100) UTF8 DEFAULT 'bar' NOT NULL`)
+	s.Contains(ddl, `CONSTRAINT "FOO_PK" PRIMARY KEY ("ID")`)
+	s.Contains(ddl, `DISTRIBUTE BY "ID"`)
+	s.True(strings.Index(ddl, "foo_v ") < strings.Index(ddl, "foo_vv "), "Views in creation order")
+	s.Contains(ddl, "return x\n/\n")
+}
+
+func (s *testSuite) TestDumpSchemaDDLOptions() {
+	s.execute("CREATE OR REPLACE CONNECTION dump_conn TO 'ftp://example.com' USER 'me' IDENTIFIED BY 'secret'")
+	defer s.execute("DROP CONNECTION IF EXISTS dump_conn")
+	s.execute("CREATE SCHEMA dump_upper", "OPEN SCHEMA "+s.qschema)
+	defer s.execute("DROP SCHEMA IF EXISTS dump_upper CASCADE")
+
+	ddl, err := s.exaConn.DumpSchemaDDL("dump_upper")
+	s.Require().NoError(err)
+	s.Contains(ddl, `OPEN SCHEMA "DUMP_UPPER";`, "Unquoted names are uppercased")
+	s.NotContains(ddl, "CONNECTION", "Connections only if asked for")
+
+	ddl, err = s.exaConn.DumpSchemaDDL(s.schema, WithConnections())
+	s.Require().NoError(err)
+	s.Contains(ddl, `OPEN SCHEMA "test";`, "Exact names are preferred")
+	s.Contains(ddl, `CREATE CONNECTION "DUMP_CONN" TO 'ftp://example.com' USER 'me' IDENTIFIED BY ''`)
+	s.NotContains(ddl, "OR REPLACE CONNECTION")
+
+	s.exaConn.Conf.SuppressError = true
+	defer func() { s.exaConn.Conf.SuppressError = false }()
+	_, err = s.exaConn.DumpSchemaDDL("no_such_schema")
+	s.Error(err)
+}
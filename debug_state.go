@@ -0,0 +1,102 @@
+/*
+    AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// A snapshot of the connection's state for attaching to bug reports.
+// It marshals to JSON and doesn't include credentials. As SQL may contain
+// sensitive literals only a hash of it is included (the same hash is used
+// for open handles and the statement cache so they can be correlated).
+type DebugState struct {
+	SessionID       uint64
+	Host            string
+	ProtocolVersion float64 // As negotiated at login
+	ReleaseVersion  string
+	Attributes      *Attributes
+	AttributesError string `json:",omitempty"`
+	OpenHandles     []OpenHandle
+	PrepStmtCache   []CachedPrepStmt
+	Stats           map[string]int
+	ShuttingDown    bool
+	Time            time.Time
+}
+
+type CachedPrepStmt struct {
+	SQLHash  string // Truncated hex SHA256 of the SQL
+	Schema   string // The schema it was prepared in
+	Handle   int
+	LastUsed time.Time
+}
+
+// The current attributes are fetched from the server.
+// If that fails the error is recorded in AttributesError.
+func (c *Conn) DebugState() *DebugState {
+	ds := &DebugState{
+		SessionID:    c.SessionID,
+		Host:         c.host,
+		OpenHandles:  c.OpenHandles(),
+		Stats:        map[string]int{},
+		ShuttingDown: c.checkOpen() != nil,
+		Time:         time.Now(),
+	}
+	if c.Metadata != nil {
+		ds.ProtocolVersion = c.Metadata.ProtocolVersion
+		ds.ReleaseVersion = c.Metadata.ReleaseVersion
+	}
+	for i := range ds.OpenHandles {
+		ds.OpenHandles[i].SQL = hashSQL(ds.OpenHandles[i].SQL)
+	}
+	c.stmtMux.Lock()
+	for k, v := range c.Stats {
+		ds.Stats[k] = v
+	}
+	ds.Stats["QueueDepth"] = c.QueueDepth()
+	ds.Stats["QueuePeak"] = int(atomic.LoadInt32(&c.queue.peak))
+	ds.Stats["StmtLimitWaitMs"] = int(c.StmtLimitWait() / time.Millisecond)
+	for key, ps := range c.prepStmtCache {
+		ds.PrepStmtCache = append(ds.PrepStmtCache, CachedPrepStmt{
+			SQLHash:  hashSQL(key.sql),
+			Schema:   key.schema,
+			Handle:   ps.sth,
+			LastUsed: ps.lastUsed,
+		})
+	}
+	c.stmtMux.Unlock()
+	sort.Slice(ds.PrepStmtCache, func(i, j int) bool {
+		return ds.PrepStmtCache[i].LastUsed.Before(ds.PrepStmtCache[j].LastUsed)
+	})
+
+	if !ds.ShuttingDown {
+		attrs, err := c.GetSessionAttr()
+		if err != nil {
+			ds.AttributesError = err.Error()
+		} else {
+			ds.Attributes = attrs
+		}
+	}
+	return ds
+}
+
+/*--- Private Routines ---*/
+
+func hashSQL(sql string) string {
+	sum := sha256.Sum256([]byte(sql))
+	return hex.EncodeToString(sum[:8])
+}
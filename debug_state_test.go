@@ -0,0 +1,36 @@
+package exasol
+
+import "encoding/json"
+
+func (s *testSuite) TestDebugState() {
+	conf := s.connConf()
+	conf.CachePrepStmts = true
+	c, err := Connect(conf)
+	s.Require().NoError(err)
+	defer c.Disconnect()
+
+	sql := "SELECT 123 FROM dual WHERE true = ?"
+	_, err = c.FetchSlice(sql, []interface{}{true})
+	s.NoError(err)
+
+	ds := c.DebugState()
+	s.Equal(c.SessionID, ds.SessionID)
+	s.True(ds.ProtocolVersion >= 1)
+	s.NotEmpty(ds.Host)
+	s.Empty(ds.AttributesError)
+	if s.NotNil(ds.Attributes) {
+		s.True(ds.Attributes.Autocommit)
+	}
+	if s.Len(ds.PrepStmtCache, 1) {
+		s.Equal(hashSQL(sql), ds.PrepStmtCache[0].SQLHash)
+	}
+	if s.Len(ds.OpenHandles, 1) {
+		s.Equal(hashSQL(sql), ds.OpenHandles[0].SQL)
+	}
+	s.Equal(1, ds.Stats["StmtCacheMiss"])
+
+	js, err := json.Marshal(ds)
+	s.NoError(err)
+	s.NotContains(string(js), "WHERE true", "SQL is hashed")
+	s.NotContains(string(js), conf.Password)
+}
@@ -0,0 +1,206 @@
+/*
+	Decoding the JSON of large fetch blocks is CPU bound and by default
+	happens in the single goroutine doing the fetching. With
+	ConnConf.DecodeWorkers set the raw blocks are instead handed to that
+	many goroutines to decode while the next block is being fetched.
+	The blocks are still delivered in order.
+
+	Each worker may be holding a raw block (of up to FetchReqSize bytes)
+	and its decoded data so memory use grows accordingly.
+
+
+	AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+)
+
+/*--- Private Routines ---*/
+
+type decodedBlock struct {
+	data     [][]interface{}
+	err      error
+	fetchErr bool // Whether the error was from fetching rather than decoding
+}
+
+func (c *Conn) fetchBlocksParallel(ctx context.Context, rs *resultSet, start uint64, keepOpen bool, cb func([][]interface{}) error) error {
+	workers := c.Conf.DecodeWorkers
+	order := make(chan chan decodedBlock, workers)
+	slots := make(chan struct{}, workers)
+	stop := make(chan struct{})
+
+	// In-flight fetches aren't cancelled when the consumer stops early
+	// as that would leave the websocket unusable. Instead the producer
+	// finishes its current fetch and then notices the stop.
+	go func() {
+		defer close(order)
+		push := func(b decodedBlock) {
+			job := make(chan decodedBlock, 1)
+			job <- b
+			select {
+			case order <- job:
+			case <-stop:
+			}
+		}
+		for i := start; i < rs.NumRows; {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			if !c.isTracked(ResultSetHandle, rs.ResultSetHandle) {
+				push(decodedBlock{err: ErrResultSetClosed, fetchErr: true})
+				return
+			}
+			res := &rawResponse{}
+			err := c.sendContext(ctx, &fetchReq{
+				Command:         "fetch",
+				ResultSetHandle: rs.ResultSetHandle,
+				StartPosition:   i,
+				NumBytes:        c.fetchReqSize,
+			}, res)
+			if err != nil {
+				push(decodedBlock{err: err, fetchErr: true})
+				return
+			}
+			numRows, err := peekNumRows(res.raw)
+			if err == nil && numRows == 0 {
+				err = errors.New("Fetch returned no rows")
+			}
+			if err != nil {
+				push(decodedBlock{err: err})
+				return
+			}
+			i += numRows
+
+			select {
+			case slots <- struct{}{}:
+			case <-stop:
+				return
+			}
+			job := make(chan decodedBlock, 1)
+			go func(raw []byte) {
+				defer func() { <-slots }()
+				fetchRes := &fetchRes{}
+				err := c.codec().Unmarshal(raw, fetchRes)
+				if err != nil {
+					job <- decodedBlock{err: err}
+					return
+				}
+				job <- decodedBlock{data: fetchRes.ResponseData.Data}
+			}(res.raw)
+			select {
+			case order <- job:
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	var err error
+	fetchErr := false
+	for job := range order {
+		b := <-job
+		if b.err != nil {
+			err, fetchErr = b.err, b.fetchErr
+			break
+		}
+		if err = cb(b.data); err != nil {
+			break
+		}
+	}
+	close(stop)
+	for range order {
+		// Wait for the producer so that the result set can be closed
+	}
+
+	if !keepOpen && !fetchErr {
+		c.closeResultSet(rs)
+	}
+	return err
+}
+
+func (c *Conn) codec() Codec {
+	if c.Conf.Codec != nil {
+		return c.Conf.Codec
+	}
+	return stdCodec{}
+}
+
+// Finds the responseData's numRows without decoding the data (unless
+// it comes first in which case it's skipped over).
+func peekNumRows(raw []byte) (uint64, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	errMissing := errors.New("Fetch response has no numRows")
+
+	inObject := func() error {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if tok != json.Delim('{') {
+			return errMissing
+		}
+		return nil
+	}
+	// Calls found for each key of the current object until it returns true
+	eachKey := func(found func(key string) (bool, error)) error {
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			key, _ := tok.(string)
+			done, err := found(key)
+			if err != nil || done {
+				return err
+			}
+		}
+		return errMissing
+	}
+	skip := func() error {
+		var skipped json.RawMessage
+		return dec.Decode(&skipped)
+	}
+
+	var numRows uint64
+	if err := inObject(); err != nil {
+		return 0, err
+	}
+	err := eachKey(func(key string) (bool, error) {
+		if key != "responseData" {
+			return false, skip()
+		}
+		if err := inObject(); err != nil {
+			return false, err
+		}
+		return true, eachKey(func(key string) (bool, error) {
+			if key != "numRows" {
+				return false, skip()
+			}
+			var n json.Number
+			if err := dec.Decode(&n); err != nil {
+				return false, err
+			}
+			i, err := n.Int64()
+			numRows = uint64(i)
+			return true, err
+		})
+	})
+	return numRows, err
+}
@@ -0,0 +1,29 @@
+package exasol
+
+func (s *testSuite) TestDecodeWorkers() {
+	conf := s.connConf()
+	conf.DecodeWorkers = 4
+	conf.FetchReqSize = 64 * 1024 // Lots of small blocks
+	c, err := Connect(conf)
+	s.Require().NoError(err)
+	defer c.Disconnect()
+
+	ch, err := c.FetchChan("SELECT level FROM dual CONNECT BY level <= 100000 ORDER BY 1")
+	s.Require().NoError(err)
+	i := 0
+	for row := range ch {
+		s.Require().NoError(row.Error)
+		i++
+		if row.Data[0].(float64) != float64(i) {
+			s.Failf("Out of order", "Row %d is %v", i, row.Data[0])
+			break
+		}
+	}
+	s.Equal(100000, i)
+	s.Empty(c.OpenHandles())
+
+	got, err := c.FetchSliceN("SELECT level FROM dual CONNECT BY level <= 100000", 5)
+	s.NoError(err)
+	s.Len(got, 5, "Stopping early")
+	s.Empty(c.OpenHandles())
+}
@@ -0,0 +1,91 @@
+/*
+	Errors are wrapped with %w throughout so callers can test for
+	these sentinels using errors.Is e.g.
+
+	    if errors.Is(err, exasol.ErrQueryTimeout) { ... }
+
+	Server errors can also be unwrapped with errors.As into an *ExaError
+	to get at the SQL code. See server_error.go
+
+
+	AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"errors"
+	"regexp"
+)
+
+var (
+	// The server rejected the credentials
+	ErrAuthFailed = errors.New("Authentication failed")
+	// The statement ran longer than the query timeout
+	ErrQueryTimeout = errors.New("Query timed out")
+	// The connection was disconnected, shut down or dropped
+	ErrConnClosed = errors.New("Connection is closed")
+	// The result set was closed (e.g. by Reconnect) before it was fully fetched
+	ErrResultSetClosed = errors.New("Result set is closed")
+	// ConnConf.MaxOpenStmts statement handles are open and none of them
+	// are cached ones that could be closed to make room
+	ErrTooManyStmts = errors.New("Too many open statement handles")
+	// The server no longer recognises a prepared statement's handle.
+	// See ConnConf.StaleStmtRetries
+	ErrStmtHandleNotFound = errors.New("Statement handle not found")
+	// FetchRow's query returned no rows
+	ErrNoRows = errors.New("No rows in result set")
+	// The transaction was rolled back due to a conflict with another
+	// session's. Retrying it will typically succeed. See transaction.go
+	ErrTxConflict = errors.New("Transaction conflict")
+	// Transaction was called with autocommit off so the session's
+	// uncommitted work would have been committed or rolled back with it
+	ErrTxInProgress = errors.New("A transaction is already in progress")
+)
+
+/*--- Private Routines ---*/
+
+// Matched against the text (or failing that the SQL code) of server
+// errors. Generic codes such as 00000, which Exasol uses for errors
+// without an SQL state, only narrow down a text match.
+var serverErrorSentinels = []struct {
+	re      *regexp.Regexp
+	code    string
+	generic bool
+	err     error
+}{
+	{authErrorRE, "", false, ErrAuthFailed},
+	{regexp.MustCompile(`(?i)timeout has been reached`), "", false, ErrQueryTimeout},
+	{regexp.MustCompile(`(?i)result ?set handle`), "", false, ErrResultSetClosed},
+	{regexp.MustCompile(`(?i)statement handle not found`), "00000", true, ErrStmtHandleNotFound},
+	{regexp.MustCompile(`(?i)GlobalTransactionRollback|transaction collision`), "40001", false, ErrTxConflict},
+}
+
+// Has errors.Is match the sentinels while keeping its own text
+type sentinelError struct {
+	text      string
+	sentinels []error
+}
+
+func newSentinelError(text string, sentinels ...error) error {
+	return &sentinelError{text, sentinels}
+}
+
+func (e *sentinelError) Error() string { return e.text }
+
+func (e *sentinelError) Is(target error) bool {
+	for _, s := range e.sentinels {
+		if target == s {
+			return true
+		}
+	}
+	return false
+}
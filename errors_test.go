@@ -0,0 +1,50 @@
+package exasol
+
+import (
+	"errors"
+)
+
+func (s *testSuite) TestSentinelErrors() {
+	conf := s.connConf()
+	conf.SuppressError = true
+	conf.Password = "wrong"
+	_, err := Connect(conf)
+	s.True(errors.Is(err, ErrAuthFailed), "Login error wraps the cause")
+
+	var exaErr *ExaError
+	_, err = s.exaConn.Execute("ASDF")
+	if s.True(errors.As(err, &exaErr)) {
+		s.NotEmpty(exaErr.SQLCode)
+	}
+
+	c, err := Connect(s.connConf())
+	s.Require().NoError(err)
+	c.Disconnect()
+	_, err = c.Execute("SELECT 1")
+	s.True(errors.Is(err, ErrConnClosed), "Disconnected")
+
+	stale := newExaError("Statement handle not found: 42", "00000")
+	s.True(errors.Is(stale, ErrStmtHandleNotFound))
+	s.True(errors.Is(newExaError("Statement handle not found: 42", ""), ErrStmtHandleNotFound))
+	s.False(errors.Is(newExaError("Statement handle not found: 42", "42000"), ErrStmtHandleNotFound),
+		"A different code takes precedence over the text")
+	s.False(errors.Is(newExaError("Something else", "00000"), ErrStmtHandleNotFound),
+		"A generic code alone doesn't match")
+}
+
+func (s *testSuite) TestStmtError() {
+	sql := "SELECT * FROM no_such_table"
+	_, err := s.exaConn.Execute(sql)
+
+	var stmtErr *StmtError
+	if s.True(errors.As(err, &stmtErr)) {
+		s.Equal(s.exaConn.SessionID, stmtErr.SessionID)
+		s.Equal(sql, stmtErr.SQL)
+		s.Equal(hashSQL(sql), stmtErr.SQLDigest)
+	}
+	var exaErr *ExaError
+	if s.True(errors.As(err, &exaErr)) {
+		s.Equal(1, exaErr.Line)
+		s.Equal(15, exaErr.Column)
+	}
+}
@@ -0,0 +1,281 @@
+/*
+	Helpers for running integration tests against Exasol. Start either
+	launches an exasol/docker-db container via the docker CLI or, if a host
+	is configured (via Conf.Host or the EXASOL_HOST environment variable),
+	uses that existing cluster instead. Each test then gets its own
+	uniquely named schema which is dropped when the test finishes.
+
+	    var cluster *containers.Cluster
+
+	    func TestMain(m *testing.M) {
+	        var err error
+	        cluster, err = containers.Start(containers.Conf{})
+	        if err != nil {
+	            log.Fatal(err)
+	        }
+	        code := m.Run()
+	        cluster.Stop()
+	        os.Exit(code)
+	    }
+
+	    func TestSomething(t *testing.T) {
+	        conn, schema := cluster.Schema(t)
+	        ...
+	    }
+
+	The docker-db image needs a privileged container and takes a minute
+	or two to boot so a single Cluster should be shared by all the tests.
+
+
+	AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package containers
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	exasol "github.com/grantstreetgroup/go-exasol-client"
+)
+
+const (
+	DefaultImage    = "exasol/docker-db:latest"
+	DefaultPassword = "exasol"
+	dbPort          = 8563
+)
+
+type Conf struct {
+	// If set (or EXASOL_HOST/EXASOL_PORT/EXASOL_PASS are) this existing
+	// cluster is used and no container is started.
+	Host     string
+	Port     uint16
+	Username string // Defaults to SYS
+	Password string // Defaults to DefaultPassword
+
+	Image          string        // Defaults to DefaultImage
+	StartupTimeout time.Duration // Defaults to 5 minutes
+	// Leaves the container running on Stop e.g. for debugging a failure.
+	KeepContainer bool
+	// Applied to every connection opened, e.g. for setting a Logger.
+	ConnConf func(*exasol.ConnConf)
+}
+
+type Cluster struct {
+	Conf        exasol.ConnConf // For connecting to the cluster
+	ContainerID string          // Empty if using an existing cluster
+	keep        bool
+	customize   func(*exasol.ConnConf)
+}
+
+func Start(conf Conf) (*Cluster, error) {
+	if conf.Host == "" {
+		conf.Host = os.Getenv("EXASOL_HOST")
+	}
+	if conf.Port == 0 {
+		if p, err := strconv.ParseUint(os.Getenv("EXASOL_PORT"), 10, 16); err == nil {
+			conf.Port = uint16(p)
+		}
+	}
+	if conf.Password == "" {
+		conf.Password = os.Getenv("EXASOL_PASS")
+	}
+	if conf.Username == "" {
+		conf.Username = "SYS"
+	}
+	if conf.Password == "" {
+		conf.Password = DefaultPassword
+	}
+	if conf.Image == "" {
+		conf.Image = DefaultImage
+	}
+	if conf.StartupTimeout == 0 {
+		conf.StartupTimeout = 5 * time.Minute
+	}
+
+	cl := &Cluster{
+		keep:      conf.KeepContainer,
+		customize: conf.ConnConf,
+		Conf: exasol.ConnConf{
+			Host:     conf.Host,
+			Port:     conf.Port,
+			Username: conf.Username,
+			Password: conf.Password,
+			// docker-db uses a self-signed certificate
+			TLSConfig:    &tls.Config{InsecureSkipVerify: true},
+			KeepPassword: true,
+		},
+	}
+	if cl.Conf.Port == 0 {
+		cl.Conf.Port = dbPort
+	}
+
+	if cl.Conf.Host == "" {
+		err := cl.run(conf.Image)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err := cl.waitUntilReady(conf.StartupTimeout)
+	if err != nil {
+		cl.Stop()
+		return nil, err
+	}
+	return cl, nil
+}
+
+// Removes the container if one was started
+func (cl *Cluster) Stop() error {
+	if cl.ContainerID == "" || cl.keep {
+		return nil
+	}
+	_, err := docker("rm", "--force", "--volumes", cl.ContainerID)
+	if err != nil {
+		return fmt.Errorf("Unable to remove container %s: %w", cl.ContainerID, err)
+	}
+	cl.ContainerID = ""
+	return nil
+}
+
+// Opens a new connection to the cluster. The caller must Disconnect it.
+func (cl *Cluster) Connect() (*exasol.Conn, error) {
+	conf := cl.Conf
+	if cl.customize != nil {
+		cl.customize(&conf)
+	}
+	return exasol.Connect(conf)
+}
+
+// Creates a uniquely named schema (which is opened in the returned
+// connection) and registers a cleanup that drops it and disconnects.
+func (cl *Cluster) Schema(t testing.TB) (*exasol.Conn, string) {
+	t.Helper()
+	conn, err := cl.Connect()
+	if err != nil {
+		t.Fatalf("Unable to connect to Exasol: %s", err)
+	}
+	schema := uniqueName("TEST_")
+	_, err = conn.Execute(fmt.Sprintf(`CREATE SCHEMA "%s"`, schema))
+	if err == nil {
+		err = conn.Commit()
+	}
+	if err == nil {
+		_, err = conn.Execute(fmt.Sprintf(`OPEN SCHEMA "%s"`, schema))
+	}
+	if err != nil {
+		conn.Disconnect()
+		t.Fatalf("Unable to create schema %s: %s", schema, err)
+	}
+
+	t.Cleanup(func() {
+		conn.Rollback()
+		_, err := conn.Execute(fmt.Sprintf(`DROP SCHEMA IF EXISTS "%s" CASCADE`, schema))
+		if err == nil {
+			err = conn.Commit()
+		}
+		if err != nil {
+			t.Errorf("Unable to drop schema %s: %s", schema, err)
+		}
+		conn.Disconnect()
+	})
+	return conn, schema
+}
+
+/*--- Private Routines ---*/
+
+func (cl *Cluster) run(image string) error {
+	out, err := docker(
+		"run", "--detach", "--privileged",
+		"--publish", fmt.Sprintf("127.0.0.1::%d", dbPort),
+		image,
+	)
+	if err != nil {
+		return fmt.Errorf("Unable to start %s container: %w", image, err)
+	}
+	cl.ContainerID = out
+
+	out, err = docker("port", cl.ContainerID, strconv.Itoa(dbPort))
+	if err != nil {
+		cl.Stop()
+		return fmt.Errorf("Unable to get container port: %w", err)
+	}
+	// There may be multiple lines e.g. for IPv4 and IPv6
+	host, port, err := net.SplitHostPort(strings.Fields(out)[0])
+	if err != nil {
+		cl.Stop()
+		return fmt.Errorf("Unable to parse container port %q: %w", out, err)
+	}
+	p, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		cl.Stop()
+		return fmt.Errorf("Unable to parse container port %q: %w", out, err)
+	}
+	cl.Conf.Host = host
+	cl.Conf.Port = uint16(p)
+	return nil
+}
+
+// The port is published long before the database accepts logins
+// so keep trying until one succeeds.
+func (cl *Cluster) waitUntilReady(timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		conf := cl.Conf
+		conf.ConnectTimeout = 10 * time.Second
+		conf.SuppressError = true
+		conn, err := exasol.Connect(conf)
+		if err == nil {
+			conn.Disconnect()
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("Exasol at %s:%d not ready after %s: %w",
+				cl.Conf.Host, cl.Conf.Port, timeout, err)
+		}
+		time.Sleep(2 * time.Second)
+	}
+}
+
+func docker(args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("docker", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg != "" {
+			return "", fmt.Errorf("docker %s: %s: %w", args[0], msg, err)
+		}
+		return "", fmt.Errorf("docker %s: %w", args[0], err)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+func uniqueName(prefix string) string {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		// Fall back to something that's still very unlikely to collide
+		return fmt.Sprintf("%s%d_%d", prefix, os.Getpid(), time.Now().UnixNano())
+	}
+	return prefix + strings.ToUpper(hex.EncodeToString(b))
+}
@@ -0,0 +1,45 @@
+package containers
+
+import (
+	"testing"
+
+	exasol "github.com/grantstreetgroup/go-exasol-client"
+)
+
+// Requires either docker or EXASOL_HOST pointing at an existing cluster
+func TestSchema(t *testing.T) {
+	cluster, err := Start(Conf{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cluster.Stop()
+
+	var schema string
+	t.Run("isolated", func(t *testing.T) {
+		var conn *exasol.Conn
+		conn, schema = cluster.Schema(t)
+		rows, err := conn.FetchSlice("SELECT CURRENT_SCHEMA FROM dual")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rows) != 1 || rows[0][0] != schema {
+			t.Errorf("Expected current schema %s got %v", schema, rows)
+		}
+	})
+
+	conn, err := cluster.Connect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Disconnect()
+	rows, err := conn.FetchSlice(
+		"SELECT schema_name FROM exa_schemas WHERE schema_name = ?",
+		[]interface{}{schema},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 0 {
+		t.Errorf("Schema %s wasn't dropped", schema)
+	}
+}
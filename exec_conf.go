@@ -0,0 +1,229 @@
+/*
+	Execute and the Fetch* routines accept functional options which
+	are applied to an ExecConf e.g.
+
+	    conn.Execute(sql, exasol.WithBinds(row1, row2), exasol.WithSchema("foo"))
+
+	The older positional style (binds, schema, dataTypes, isColumnar)
+	is still supported but is deprecated because it's easy to misuse.
+
+
+	AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+type ExecConf struct {
+	// Data bindings for statements containing placeholders.
+	// In row format unless IsColumnar is set.
+	Binds [][]interface{}
+	// Allows you to use non-schema-qualified table identifiers in the
+	// statement even when you have no schema currently open.
+	Schema string
+	// Only necessary if you are working around a bug that existed in
+	// pre-v6.0.9 of Exasol (https://www.exasol.com/support/browse/EXASOL-2138)
+	DataTypes  []DataType
+	IsColumnar bool
+	// Overrides the session's query timeout for this statement only
+	Timeout time.Duration
+	// Caps the number of rows returned for this statement only
+	MaxRows uint64
+	// Session attributes to apply for this statement only e.g.
+	// SnapshotTransactionsEnabled. Zero values must be Included.
+	// They're reverted afterwards except for currentSchema if
+	// no schema was open.
+	Attributes *Attributes
+	// Splits the binds into chunks of this many rows executing (and
+	// committing) each separately. See batch.go
+	ChunkSize int
+	// When a chunk fails, bisect it to isolate the offending rows
+	Bisect bool
+	// Only used by FetchPage
+	TotalCount bool
+	// FetchChan rows come from a pool and must be Released. See rowpool.go
+	PooledRows bool
+	// Its deadline/cancellation applies to this call's websocket operations
+	// (including fetching the results) as well as the connection's context.
+	Context context.Context
+}
+
+type ExecOption func(*ExecConf)
+
+// Each argument is one row of binds
+func WithBinds(rows ...[]interface{}) ExecOption {
+	return func(ec *ExecConf) { ec.Binds = append(ec.Binds, rows...) }
+}
+
+func WithSchema(schema string) ExecOption {
+	return func(ec *ExecConf) { ec.Schema = schema }
+}
+
+func WithDataTypes(dataTypes []DataType) ExecOption {
+	return func(ec *ExecConf) { ec.DataTypes = dataTypes }
+}
+
+// Indicates that the binds are in columnar rather than row format
+func WithColumnar() ExecOption {
+	return func(ec *ExecConf) { ec.IsColumnar = true }
+}
+
+// The timeout is rounded up to whole seconds as that's all Exasol
+// supports. e.g. 500ms is a 1s timeout rather than none at all.
+func WithTimeout(timeout time.Duration) ExecOption {
+	return func(ec *ExecConf) { ec.Timeout = timeout }
+}
+
+// Has the server return at most maxRows rows e.g. for previews
+func WithMaxRows(maxRows uint64) ExecOption {
+	return func(ec *ExecConf) { ec.MaxRows = maxRows }
+}
+
+// Overrides session attributes for this statement only
+func WithAttributes(attrs *Attributes) ExecOption {
+	return func(ec *ExecConf) { ec.Attributes = attrs }
+}
+
+// Executes the binds chunkSize rows at a time. If bisect is set, failing
+// chunks are bisected to find the offending rows and the rest are committed.
+func WithChunks(chunkSize int, bisect bool) ExecOption {
+	return func(ec *ExecConf) {
+		ec.ChunkSize = chunkSize
+		ec.Bisect = bisect
+	}
+}
+
+// Has FetchPage also calculate the total number of rows
+func WithTotalCount() ExecOption {
+	return func(ec *ExecConf) { ec.TotalCount = true }
+}
+
+// Recycles FetchChan row slices. Each row must be Released when done with.
+func WithPooledRows() ExecOption {
+	return func(ec *ExecConf) { ec.PooledRows = true }
+}
+
+// If the context is done before the call completes the websocket
+// operation is interrupted, which leaves the connection unusable
+// (see websocket_handler.go) so you'll need to Reconnect.
+func WithContext(ctx context.Context) ExecOption {
+	return func(ec *ExecConf) { ec.Context = ctx }
+}
+
+/*--- Private Routines ---*/
+
+// The Timeout in whole seconds, rounded up
+func (ec *ExecConf) timeoutSeconds() uint32 {
+	secs := (ec.Timeout + time.Second - 1) / time.Second
+	if secs > math.MaxUint32 {
+		return math.MaxUint32
+	}
+	return uint32(secs)
+}
+
+// Converts the conf back into options so that it can be passed on
+func (ec *ExecConf) options() []interface{} {
+	return []interface{}{ExecOption(func(c *ExecConf) { *c = *ec })}
+}
+
+func isExecOptions(args []interface{}) bool {
+	if len(args) == 0 {
+		return false
+	}
+	_, ok := args[0].(ExecOption)
+	return ok
+}
+
+func newExecConf(opts []interface{}) (*ExecConf, error) {
+	ec := &ExecConf{}
+	for i, o := range opts {
+		opt, ok := o.(ExecOption)
+		if !ok {
+			return nil, fmt.Errorf("Option %d must be an ExecOption not %T", i+1, o)
+		}
+		opt(ec)
+	}
+	return ec, nil
+}
+
+// Converts the deprecated positional args of Execute into an ExecConf
+func (c *Conn) execArgsConf(args []interface{}) (*ExecConf, error) {
+	if isExecOptions(args) {
+		return newExecConf(args)
+	}
+	ec := &ExecConf{}
+	if len(args) > 0 && args[0] != nil {
+		switch b := args[0].(type) {
+		case [][]interface{}:
+			ec.Binds = b
+		case []interface{}:
+			ec.Binds = append(ec.Binds, b)
+		default:
+			return nil, c.error("Execute's 2nd param (binds) must be []interface{} or [][]interface{}")
+		}
+	}
+	if len(args) > 1 && args[1] != nil {
+		switch s := args[1].(type) {
+		case string:
+			ec.Schema = s
+		default:
+			return nil, c.error("Execute's 3nd param (schema) must be a string")
+		}
+	}
+	if len(args) > 2 && args[2] != nil {
+		switch d := args[2].(type) {
+		case []DataType:
+			ec.DataTypes = d
+		default:
+			return nil, c.error("Execute's 4th param (data types) must be a []DataType")
+		}
+	}
+	if len(args) > 3 && args[3] != nil {
+		switch ic := args[3].(type) {
+		case bool:
+			ec.IsColumnar = ic
+		default:
+			return nil, c.error("Execute's 5th param (isColumnar) must be a boolean")
+		}
+	}
+	return ec, nil
+}
+
+// Converts the deprecated positional args of FetchChan into an ExecConf
+func (c *Conn) fetchArgsConf(args []interface{}) (*ExecConf, error) {
+	if isExecOptions(args) {
+		return newExecConf(args)
+	}
+	ec := &ExecConf{}
+	if len(args) > 0 && args[0] != nil {
+		switch b := args[0].(type) {
+		case []interface{}:
+			ec.Binds = append(ec.Binds, b)
+		default:
+			return nil, c.error("Fetch's 2nd param (binds) must be []interface{}")
+		}
+	}
+	if len(args) > 1 && args[1] != nil {
+		switch s := args[1].(type) {
+		case string:
+			ec.Schema = s
+		default:
+			return nil, c.error("Fetch's 3nd param (schema) must be a string")
+		}
+	}
+	return ec, nil
+}
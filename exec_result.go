@@ -0,0 +1,123 @@
+/*
+	Execute only returns the number of rows affected so any result set
+	the statement returns (e.g. a SELECT, or a script that returns rows)
+	is discarded. ExecuteResult returns them instead:
+
+	    res, err := conn.ExecuteResult("EXECUTE SCRIPT my_script()")
+	    if res.HasResultSet() {
+	        rows, info, err := res.FetchChan()
+	        for row := range rows { ... }
+	    }
+
+	The result set must be consumed via FetchChan or released via Close
+	so that the server-side handle (if any) doesn't leak.
+
+
+	AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import "errors"
+
+type ExecResult struct {
+	// The row count of the first result if it's a row count
+	RowsAffected int64
+
+	conn *Conn
+	sql  string
+	rs   *resultSet
+	ec   *ExecConf
+}
+
+var errNoResultSet = errors.New("Statement didn't return a result set")
+
+// Takes the same optional args as Execute
+func (c *Conn) ExecuteResult(sql string, args ...interface{}) (*ExecResult, error) {
+	ec, err := c.execArgsConf(args)
+	if err != nil {
+		return nil, err
+	}
+	if ec.ChunkSize > 0 && len(ec.Binds) > 0 {
+		n, err := c.executeChunked(sql, ec)
+		return &ExecResult{RowsAffected: n}, err
+	}
+
+	res, err := c.execute(sql, ec)
+	if err != nil {
+		return nil, c.errorf("Unable to Execute: %w", err)
+	}
+	er := &ExecResult{conn: c, sql: sql, ec: ec}
+	for i, r := range res.ResponseData.Results {
+		if i == 0 && r.ResultType == rowCountType {
+			er.RowsAffected = r.RowCount
+		} else if r.ResultType == resultSetType && r.ResultSet != nil && er.rs == nil {
+			er.rs = r.ResultSet
+		} else if r.ResultSet != nil {
+			// Only the first result set is exposed
+			c.discardResultSet(r.ResultSet)
+		}
+	}
+	return er, nil
+}
+
+func (er *ExecResult) HasResultSet() bool {
+	return er.rs != nil
+}
+
+// Streams the result set as FetchChanInfo does. It can only be called once.
+func (er *ExecResult) FetchChan() (<-chan FetchResult, *ResultInfo, error) {
+	rs := er.rs
+	if rs == nil {
+		return nil, nil, errNoResultSet
+	}
+	er.rs = nil
+	c := er.conn
+	info := &ResultInfo{
+		NumRows: rs.NumRows,
+		Columns: rs.Columns,
+		Handle:  rs.ResultSetHandle,
+	}
+
+	err := c.startFetch()
+	if err != nil {
+		return nil, nil, err
+	}
+	ch := make(chan FetchResult, 1000)
+	go func() {
+		defer c.fetches.Done()
+		ctx, cancel := c.callContext(er.ec.Context)
+		defer cancel()
+		c.resultsToChan(ctx, er.sql, rs, ch, c.rowPool(er.ec))
+	}()
+	return ch, info, nil
+}
+
+// Releases the result set if it hasn't been fetched
+func (er *ExecResult) Close() error {
+	rs := er.rs
+	if rs == nil {
+		return nil
+	}
+	er.rs = nil
+	if rs.ResultSetHandle <= 0 {
+		return nil
+	}
+	return er.conn.closeResultSetHandles(rs.ResultSetHandle)
+}
+
+/*--- Private Routines ---*/
+
+func (c *Conn) discardResultSet(rs *resultSet) {
+	if rs.ResultSetHandle > 0 {
+		c.closeResultSet(rs)
+	}
+}
@@ -0,0 +1,38 @@
+package exasol
+
+func (s *testSuite) TestExecuteResult() {
+	exa := s.exaConn
+	s.execute("CREATE TABLE foo ( id INT )")
+	s.execute("INSERT INTO foo SELECT level FROM dual CONNECT BY level <= 5000")
+
+	res, err := exa.ExecuteResult("INSERT INTO foo VALUES (?)", WithBinds([]interface{}{0}))
+	s.NoError(err)
+	s.Equal(int64(1), res.RowsAffected)
+	s.False(res.HasResultSet())
+	_, _, err = res.FetchChan()
+	s.Error(err)
+
+	res, err = exa.ExecuteResult("SELECT id FROM foo ORDER BY id")
+	s.Require().NoError(err)
+	s.True(res.HasResultSet())
+	rows, info, err := res.FetchChan()
+	s.Require().NoError(err)
+	s.Equal(uint64(5001), info.NumRows)
+	n := 0
+	for row := range rows {
+		s.NoError(row.Error)
+		n++
+	}
+	s.Equal(5001, n)
+	s.False(res.HasResultSet(), "Consumed")
+
+	res, err = exa.ExecuteResult("SELECT id FROM foo")
+	s.Require().NoError(err)
+	s.NotEmpty(exa.OpenHandles())
+	s.NoError(res.Close())
+	s.Empty(exa.OpenHandles(), "Closed without fetching")
+
+	_, err = exa.Execute("SELECT id FROM foo")
+	s.NoError(err)
+	s.Empty(exa.OpenHandles(), "Execute doesn't leak the result set")
+}
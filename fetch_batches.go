@@ -0,0 +1,99 @@
+/*
+	FetchBatches streams a result set one fetch block at a time for
+	consumers that do their own vectorized processing. This avoids
+	FetchChan's transposing and per-row channel sends:
+
+	    batches, err := conn.FetchBatches("SELECT id, amount FROM t")
+	    for b := range batches {
+	        if b.Error != nil { ... }
+	        amounts := b.Data[1]
+	        for i := 0; i < b.NumRows; i++ {
+	            total += amounts[i].(float64)
+	        }
+	    }
+
+	The size of the batches is governed by ConnConf.FetchReqSize.
+
+
+	AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import "context"
+
+type Batch struct {
+	Columns []Column
+	// Column-major i.e. Data[col][row]
+	Data    [][]interface{}
+	NumRows int
+	Error   error
+}
+
+// Takes the same optional args as FetchChan
+func (c *Conn) FetchBatches(sql string, args ...interface{}) (<-chan Batch, error) {
+	ec, err := c.fetchArgsConf(args)
+	if err != nil {
+		return nil, err
+	}
+	rs, err := c.executeQuery(sql, ec)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.startFetch()
+	if err != nil {
+		return nil, err
+	}
+	ch := make(chan Batch, 2)
+	go func() {
+		defer c.fetches.Done()
+		defer close(ch)
+		ctx, cancel := c.callContext(ec.Context)
+		defer cancel()
+		c.batchesToChan(ctx, sql, rs, ch)
+	}()
+	return ch, nil
+}
+
+/*--- Private Routines ---*/
+
+func (c *Conn) batchesToChan(ctx context.Context, sql string, rs *resultSet, ch chan<- Batch) {
+	send := func(data [][]interface{}) error {
+		if len(data) == 0 || len(data[0]) == 0 {
+			return nil
+		}
+		select {
+		case ch <- Batch{Columns: rs.Columns, Data: data, NumRows: len(data[0])}:
+			return nil
+		case <-c.fetchCtx.Done():
+			return c.fetchCtx.Err()
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	if rs.NumRows == 0 {
+		return
+	} else if rs.ResultSetHandle <= 0 {
+		send(rs.Data)
+		return
+	}
+	err := c.fetchBlocks(ctx, rs, send)
+	if err != nil {
+		err = c.errorf("Unable to Fetch: %w", c.stmtError(sql, err))
+		select {
+		case ch <- Batch{Error: err}:
+		case <-c.fetchCtx.Done():
+		case <-ctx.Done():
+		}
+	}
+}
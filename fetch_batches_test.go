@@ -0,0 +1,34 @@
+package exasol
+
+func (s *testSuite) TestFetchBatches() {
+	exa := s.exaConn
+	s.execute("CREATE TABLE foo ( id INT, val VARCHAR(100) )")
+	s.execute("INSERT INTO foo SELECT level, RPAD('x', 100, 'x') FROM dual CONNECT BY level <= 50000")
+
+	batches, err := exa.FetchBatches("SELECT id, val FROM foo")
+	s.Require().NoError(err)
+	var rows, numBatches int
+	var sum float64
+	for b := range batches {
+		s.Require().NoError(b.Error)
+		s.Len(b.Columns, 2)
+		s.Len(b.Data, 2, "Column-major")
+		s.Len(b.Data[0], b.NumRows)
+		for _, id := range b.Data[0] {
+			sum += id.(float64)
+		}
+		rows += b.NumRows
+		numBatches++
+	}
+	s.Equal(50000, rows)
+	s.Equal(float64(50000*50001/2), sum)
+	s.True(numBatches > 1, "Several fetch blocks")
+	s.Empty(exa.OpenHandles())
+
+	batches, err = exa.FetchBatches("SELECT id FROM foo WHERE id <= ?", []interface{}{3})
+	s.Require().NoError(err)
+	b := <-batches
+	s.Equal(3, b.NumRows, "Small result sets are a single batch")
+	_, ok := <-batches
+	s.False(ok)
+}
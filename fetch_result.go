@@ -0,0 +1,264 @@
+/*
+	Typed accessors for FetchResult rows so that consumers don't need
+	to index blind []interface{} positions and cast manually e.g.
+
+	    for row := range ch {
+	        id, err := row.Int64(0)
+	        name, err := row.Get("NAME")
+	        created, err := row.Time(2)
+	    }
+
+	NULL values are returned as the type's zero value. Use IsNull to
+	distinguish them.
+
+	Or all the columns can be scanned at once:
+
+	    var id int64
+	    var name *string // nil if NULL
+	    err := row.Scan(&id, &name)
+
+
+	AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	exaDateFormat      = "2006-01-02"
+	exaTimestampFormat = "2006-01-02 15:04:05.999999999"
+)
+
+// Returns the index of the named column. Exact matches are preferred
+// but otherwise the name is matched case-insensitively.
+func (r FetchResult) Index(name string) (int, error) {
+	for i, col := range r.Columns {
+		if col.Name == name {
+			return i, nil
+		}
+	}
+	for i, col := range r.Columns {
+		if strings.EqualFold(col.Name, name) {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("Unknown column: %s", name)
+}
+
+func (r FetchResult) Get(name string) (interface{}, error) {
+	i, err := r.Index(name)
+	if err != nil {
+		return nil, err
+	}
+	return r.value(i)
+}
+
+func (r FetchResult) IsNull(i int) bool {
+	return i >= 0 && i < len(r.Data) && r.Data[i] == nil
+}
+
+func (r FetchResult) String(i int) (string, error) {
+	v, err := r.value(i)
+	if err != nil || v == nil {
+		return "", err
+	}
+	switch s := v.(type) {
+	case string:
+		return s, nil
+	case float64:
+		return strconv.FormatFloat(s, 'f', -1, 64), nil
+	default:
+		return fmt.Sprint(s), nil
+	}
+}
+
+func (r FetchResult) Int64(i int) (int64, error) {
+	v, err := r.value(i)
+	if err != nil || v == nil {
+		return 0, err
+	}
+	switch n := v.(type) {
+	case float64:
+		if n != float64(int64(n)) {
+			return 0, fmt.Errorf("Column %d value %v is not an integer", i, n)
+		}
+		return int64(n), nil
+	case string:
+		// Large DECIMALs are sent as strings
+		return strconv.ParseInt(n, 10, 64)
+	default:
+		return 0, fmt.Errorf("Column %d is a %T not a number", i, v)
+	}
+}
+
+func (r FetchResult) Float64(i int) (float64, error) {
+	v, err := r.value(i)
+	if err != nil || v == nil {
+		return 0, err
+	}
+	switch n := v.(type) {
+	case float64:
+		return n, nil
+	case string:
+		return strconv.ParseFloat(n, 64)
+	default:
+		return 0, fmt.Errorf("Column %d is a %T not a number", i, v)
+	}
+}
+
+func (r FetchResult) Bool(i int) (bool, error) {
+	v, err := r.value(i)
+	if err != nil || v == nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("Column %d is a %T not a bool", i, v)
+	}
+	return b, nil
+}
+
+// DATEs and TIMESTAMPs are parsed in UTC unless the column is
+// a TIMESTAMP WITH LOCAL TIME ZONE in which case the local time zone is used.
+func (r FetchResult) Time(i int) (time.Time, error) {
+	v, err := r.value(i)
+	if err != nil || v == nil {
+		return time.Time{}, err
+	}
+	s, ok := v.(string)
+	if !ok {
+		return time.Time{}, fmt.Errorf("Column %d is a %T not a date/time", i, v)
+	}
+	loc := time.UTC
+	if i < len(r.Columns) && r.Columns[i].DataType.WithLocalTimeZone {
+		loc = time.Local
+	}
+	format := exaTimestampFormat
+	if len(s) == len(exaDateFormat) {
+		format = exaDateFormat
+	}
+	return time.ParseInLocation(format, s, loc)
+}
+
+// Converts each column into the corresponding dest which must be one of
+// *int64, *int, *float64, *string, *bool, *time.Time or *interface{}.
+// A pointer to a pointer to one of those (e.g. **string) is set to nil
+// for NULLs whereas the others are set to their zero value.
+// A nil dest skips the column.
+func (r FetchResult) Scan(dest ...interface{}) error {
+	if r.Error != nil {
+		return r.Error
+	}
+	if len(dest) != len(r.Data) {
+		return fmt.Errorf("Expected %d Scan destinations not %d", len(r.Data), len(dest))
+	}
+	for i, d := range dest {
+		err := r.scan(i, d)
+		if err != nil {
+			return fmt.Errorf("Unable to scan column %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+/*--- Private Routines ---*/
+
+func (r FetchResult) scan(i int, dest interface{}) (err error) {
+	switch d := dest.(type) {
+	case nil:
+	case *int64:
+		*d, err = r.Int64(i)
+	case *int:
+		var n int64
+		n, err = r.Int64(i)
+		*d = int(n)
+	case *float64:
+		*d, err = r.Float64(i)
+	case *string:
+		*d, err = r.String(i)
+	case *bool:
+		*d, err = r.Bool(i)
+	case *time.Time:
+		*d, err = r.Time(i)
+	case *interface{}:
+		*d, err = r.value(i)
+
+	case **int64:
+		if r.IsNull(i) {
+			*d = nil
+			return nil
+		}
+		var n int64
+		n, err = r.Int64(i)
+		*d = &n
+	case **int:
+		if r.IsNull(i) {
+			*d = nil
+			return nil
+		}
+		var n int64
+		n, err = r.Int64(i)
+		m := int(n)
+		*d = &m
+	case **float64:
+		if r.IsNull(i) {
+			*d = nil
+			return nil
+		}
+		var f float64
+		f, err = r.Float64(i)
+		*d = &f
+	case **string:
+		if r.IsNull(i) {
+			*d = nil
+			return nil
+		}
+		var s string
+		s, err = r.String(i)
+		*d = &s
+	case **bool:
+		if r.IsNull(i) {
+			*d = nil
+			return nil
+		}
+		var b bool
+		b, err = r.Bool(i)
+		*d = &b
+	case **time.Time:
+		if r.IsNull(i) {
+			*d = nil
+			return nil
+		}
+		var t time.Time
+		t, err = r.Time(i)
+		*d = &t
+
+	default:
+		return fmt.Errorf("Unsupported Scan destination %T", dest)
+	}
+	return err
+}
+
+func (r FetchResult) value(i int) (interface{}, error) {
+	if i < 0 || i >= len(r.Data) {
+		return nil, fmt.Errorf("Column index %d out of range", i)
+	}
+	if r.Data[i] == ReleasedValue {
+		return nil, errRowReleased
+	}
+	return r.Data[i], nil
+}
@@ -0,0 +1,131 @@
+package exasol
+
+import "time"
+
+func (s *testSuite) TestFetchResultAccessors() {
+	exa := s.exaConn
+	ch, err := exa.FetchChan(`
+		SELECT 42 AS id, 'foo' AS name, 1.5 AS amount, true AS flag,
+		       DATE '2021-02-03' AS d,
+		       TIMESTAMP '2021-02-03 04:05:06.789' AS ts,
+		       CAST(NULL AS VARCHAR(10)) AS empty
+		FROM dual
+	`)
+	s.Require().NoError(err)
+	row := <-ch
+	s.Require().NoError(row.Error)
+
+	id, err := row.Int64(0)
+	s.NoError(err)
+	s.Equal(int64(42), id)
+
+	name, err := row.Get("name")
+	s.NoError(err)
+	s.Equal("foo", name)
+
+	str, err := row.String(0)
+	s.NoError(err)
+	s.Equal("42", str)
+
+	amt, err := row.Float64(2)
+	s.NoError(err)
+	s.Equal(1.5, amt)
+	_, err = row.Int64(2)
+	s.Error(err, "Non-integers can't be Int64s")
+
+	flag, err := row.Bool(3)
+	s.NoError(err)
+	s.True(flag)
+
+	d, err := row.Time(4)
+	s.NoError(err)
+	s.Equal(time.Date(2021, 2, 3, 0, 0, 0, 0, time.UTC), d)
+
+	ts, err := row.Time(5)
+	s.NoError(err)
+	s.Equal(time.Date(2021, 2, 3, 4, 5, 6, 789000000, time.UTC), ts)
+
+	s.True(row.IsNull(6))
+	empty, err := row.String(6)
+	s.NoError(err)
+	s.Equal("", empty)
+
+	_, err = row.Get("nope")
+	s.Error(err)
+	_, err = row.String(7)
+	s.Error(err)
+	for range ch {
+	}
+}
+
+func (s *testSuite) TestPooledRows() {
+	exa := s.exaConn
+	exa.Execute("CREATE TABLE foo ( id INT )")
+	exa.Execute("INSERT INTO foo SELECT level FROM dual CONNECT BY level <= 5000")
+
+	ch, err := exa.FetchChan("SELECT id FROM foo", WithPooledRows())
+	s.Require().NoError(err)
+	var sum float64
+	for row := range ch {
+		s.Require().NoError(row.Error)
+		id, err := row.Float64(0)
+		s.NoError(err)
+		sum += id
+		row.Release()
+	}
+	s.Equal(float64(5000*5001/2), sum)
+
+	conf := s.connConf()
+	conf.DebugRowPool = true
+	c, err := Connect(conf)
+	s.Require().NoError(err)
+	defer c.Disconnect()
+	ch, err = c.FetchChan("SELECT 1 AS a FROM dual", WithPooledRows())
+	s.Require().NoError(err)
+	row := <-ch
+	row.Release()
+	s.Equal(ReleasedValue, row.Data[0])
+	_, err = row.Get("A")
+	s.EqualError(err, "Row used after Release")
+	s.Panics(row.Release, "Double release")
+}
+
+func (s *testSuite) TestScan() {
+	exa := s.exaConn
+	exa.Execute("CREATE TABLE foo ( id INT, name VARCHAR(10), price DOUBLE, ok BOOLEAN, d DATE )")
+	exa.Execute("INSERT INTO foo VALUES (1, 'a', 1.5, true, '2020-01-02'), (2, NULL, NULL, NULL, NULL)")
+
+	var id int64
+	var name *string
+	var price float64
+	var ok *bool
+	var d time.Time
+	err := exa.FetchRow("SELECT * FROM foo WHERE id = ?", []interface{}{1}, &id, &name, &price, &ok, &d)
+	if s.NoError(err) {
+		s.Equal(int64(1), id)
+		s.Equal("a", *name)
+		s.Equal(1.5, price)
+		s.True(*ok)
+		s.Equal(time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC), d)
+	}
+
+	var n int
+	err = exa.FetchRow("SELECT * FROM foo WHERE id = 2", nil, &n, &name, &price, &ok, nil)
+	if s.NoError(err) {
+		s.Equal(2, n)
+		s.Nil(name, "NULL into **string")
+		s.Equal(float64(0), price, "NULL into *float64")
+		s.Nil(ok)
+	}
+
+	err = exa.FetchRow("SELECT * FROM foo WHERE id = 3", nil, &id)
+	s.Equal(ErrNoRows, err)
+
+	ch, err := exa.FetchChan("SELECT id, name FROM foo ORDER BY id")
+	s.Require().NoError(err)
+	for row := range ch {
+		s.NoError(row.Scan(&id, &name))
+		s.EqualError(row.Scan(&id), "Expected 2 Scan destinations not 1")
+		s.Error(row.Scan(&id, &[]byte{}), "Unsupported destination")
+	}
+}
@@ -0,0 +1,138 @@
+/*
+	Conversion between Exasol GEOMETRY values and go-geom
+	(https://github.com/twpayne/go-geom) geometries. It lives in its own
+	module so that the client doesn't pull in go-geom.
+
+	Exasol returns GEOMETRY values as WKT strings and accepts them as
+	binds in the same form:
+
+	    for row := range ch {
+	        g, err := geometry.Get(row, "SHAPE")
+	    }
+
+	    wkt, err := geometry.Bind(geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1, 2}))
+	    _, err = conn.Execute("INSERT INTO shapes VALUES (?)", exasol.WithBinds([]interface{}{wkt}))
+
+	Or convert all the GEOMETRY columns of a row in place with DecodeRow.
+	Decoded geometries get their column's SRID.
+
+
+	AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package geometry
+
+import (
+	"fmt"
+
+	exasol "github.com/grantstreetgroup/go-exasol-client"
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/wkt"
+)
+
+// Decodes a value as returned for a GEOMETRY column.
+// NULLs are returned as a nil geometry.
+func Decode(v interface{}) (geom.T, error) {
+	switch s := v.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		g, err := wkt.Unmarshal(s)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to decode geometry %q: %w", s, err)
+		}
+		return g, nil
+	default:
+		return nil, fmt.Errorf("Unexpected geometry value of type %T", v)
+	}
+}
+
+// Decodes the i-th column of the row
+func Column(r exasol.FetchResult, i int) (geom.T, error) {
+	if r.Error != nil {
+		return nil, r.Error
+	}
+	if i < 0 || i >= len(r.Data) {
+		return nil, fmt.Errorf("Column index %d out of range", i)
+	}
+	g, err := Decode(r.Data[i])
+	if err != nil || g == nil {
+		return g, err
+	}
+	if i < len(r.Columns) {
+		g = withSRID(g, r.Columns[i].DataType.SRId)
+	}
+	return g, nil
+}
+
+// Decodes the named column of the row
+func Get(r exasol.FetchResult, name string) (geom.T, error) {
+	i, err := r.Index(name)
+	if err != nil {
+		return nil, err
+	}
+	return Column(r, i)
+}
+
+// Replaces the WKT strings of the row's GEOMETRY columns with geom.T
+func DecodeRow(r *exasol.FetchResult) error {
+	for i, col := range r.Columns {
+		if col.DataType.Type != "GEOMETRY" {
+			continue
+		}
+		g, err := Column(*r, i)
+		if err != nil {
+			return err
+		}
+		if g != nil {
+			r.Data[i] = g
+		}
+	}
+	return nil
+}
+
+// Encodes a geometry for binding to a GEOMETRY column.
+// A nil geometry is bound as NULL.
+func Bind(g geom.T) (interface{}, error) {
+	if g == nil {
+		return nil, nil
+	}
+	s, err := wkt.Marshal(g)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to encode geometry: %w", err)
+	}
+	return s, nil
+}
+
+/*--- Private Routines ---*/
+
+func withSRID(g geom.T, srid int) geom.T {
+	if srid == 0 {
+		return g
+	}
+	switch t := g.(type) {
+	case *geom.Point:
+		return t.SetSRID(srid)
+	case *geom.LineString:
+		return t.SetSRID(srid)
+	case *geom.Polygon:
+		return t.SetSRID(srid)
+	case *geom.MultiPoint:
+		return t.SetSRID(srid)
+	case *geom.MultiLineString:
+		return t.SetSRID(srid)
+	case *geom.MultiPolygon:
+		return t.SetSRID(srid)
+	case *geom.GeometryCollection:
+		return t.SetSRID(srid)
+	}
+	return g
+}
@@ -0,0 +1,84 @@
+package geometry
+
+import (
+	"testing"
+
+	exasol "github.com/grantstreetgroup/go-exasol-client"
+	"github.com/twpayne/go-geom"
+)
+
+func TestDecode(t *testing.T) {
+	g, err := Decode("POINT (1 2)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, ok := g.(*geom.Point)
+	if !ok || p.X() != 1 || p.Y() != 2 {
+		t.Errorf("Unexpected geometry: %#v", g)
+	}
+
+	if g, err = Decode(nil); g != nil || err != nil {
+		t.Errorf("Expected a nil geometry for NULL, got %v, %v", g, err)
+	}
+	if _, err = Decode("POINT (1"); err == nil {
+		t.Error("Expected an error for invalid WKT")
+	}
+	if _, err = Decode(1.5); err == nil {
+		t.Error("Expected an error for a non-string value")
+	}
+}
+
+func TestBindRoundTrip(t *testing.T) {
+	ls := geom.NewLineStringFlat(geom.XY, []float64{0, 0, 1, 1, 2, 0})
+	v, err := Bind(ls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := Decode(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, ok := g.(*geom.LineString)
+	if !ok || got.NumCoords() != 3 || got.Coord(2).X() != 2 {
+		t.Errorf("Unexpected geometry after round trip: %#v", g)
+	}
+
+	if v, err = Bind(nil); v != nil || err != nil {
+		t.Errorf("Expected NULL for a nil geometry, got %v, %v", v, err)
+	}
+}
+
+func TestColumnAndDecodeRow(t *testing.T) {
+	row := exasol.FetchResult{
+		Data: []interface{}{"POINT (3 4)", int64(7), nil},
+		Columns: []exasol.Column{
+			{Name: "SHAPE", DataType: exasol.DataType{Type: "GEOMETRY", SRId: 4326}},
+			{Name: "ID", DataType: exasol.DataType{Type: "DECIMAL"}},
+			{Name: "EMPTY", DataType: exasol.DataType{Type: "GEOMETRY"}},
+		},
+	}
+
+	g, err := Get(row, "shape")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.SRID() != 4326 {
+		t.Errorf("Expected SRID 4326, got %d", g.SRID())
+	}
+	if _, err = Column(row, 3); err == nil {
+		t.Error("Expected an error for an out of range column")
+	}
+	if _, err = Get(row, "nope"); err == nil {
+		t.Error("Expected an error for an unknown column")
+	}
+
+	if err = DecodeRow(&row); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := row.Data[0].(*geom.Point); !ok {
+		t.Errorf("Expected the GEOMETRY column to be decoded, got %T", row.Data[0])
+	}
+	if row.Data[1] != int64(7) || row.Data[2] != nil {
+		t.Errorf("Other columns shouldn't change: %v", row.Data[1:])
+	}
+}
@@ -0,0 +1,10 @@
+module github.com/grantstreetgroup/go-exasol-client/geometry
+
+go 1.16
+
+require (
+	github.com/grantstreetgroup/go-exasol-client v0.0.0-00010101000000-000000000000
+	github.com/twpayne/go-geom v1.5.2
+)
+
+replace github.com/grantstreetgroup/go-exasol-client => ../
@@ -0,0 +1,171 @@
+/*
+	Tracking of the server-side handles (result sets and prepared
+	statements) opened by a connection. Leaked handles otherwise only
+	show up as server-side resource errors.
+
+
+	AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+type HandleType string
+
+const (
+	ResultSetHandle HandleType = "result set"
+	PrepStmtHandle  HandleType = "prepared statement"
+)
+
+type OpenHandle struct {
+	Type   HandleType
+	Handle int
+	SQL    string // The SQL that opened the handle
+	Opened time.Time
+	Cached bool // Whether it's a prepared statement in the cache
+}
+
+// Returns the currently open handles, oldest first.
+// Intended for debugging.
+func (c *Conn) OpenHandles() []OpenHandle {
+	c.handleMux.Lock()
+	handles := make([]OpenHandle, 0, len(c.handles))
+	for _, h := range c.handles {
+		handles = append(handles, *h)
+	}
+	c.handleMux.Unlock()
+
+	cached := map[int]bool{}
+	c.stmtMux.Lock()
+	for _, ps := range c.prepStmtCache {
+		cached[ps.sth] = true
+	}
+	c.stmtMux.Unlock()
+	for i, h := range handles {
+		handles[i].Cached = h.Type == PrepStmtHandle && cached[h.Handle]
+	}
+	sort.Slice(handles, func(i, j int) bool {
+		return handles[i].Opened.Before(handles[j].Opened)
+	})
+	return handles
+}
+
+// Closes a handle as returned by OpenHandles. A FetchChan still
+// reading from a closed result set stops with ErrResultSetClosed.
+// A cached prepared statement which is being executed is only
+// removed from the cache and then closed once it's finished.
+func (c *Conn) CloseHandle(h OpenHandle) error {
+	switch h.Type {
+	case ResultSetHandle:
+		err := c.closeResultSetHandles(h.Handle)
+		if err != nil {
+			return c.errorf("Unable to close result set %d: %w", h.Handle, err)
+		}
+		return nil
+	case PrepStmtHandle:
+		c.stmtMux.Lock()
+		var sths []int
+		found := false
+		for key, cached := range c.prepStmtCache {
+			if cached.sth == h.Handle {
+				sths = c.dropPrepStmt(key, cached)
+				found = true
+				break
+			}
+		}
+		if !found {
+			sths = c.retirePrepStmt(&prepStmt{sth: h.Handle})
+		}
+		c.stmtMux.Unlock()
+		return c.closeStmtHandles(sths)
+	}
+	return fmt.Errorf("Unknown handle type: %s", h.Type)
+}
+
+// Closes all of the connection's open result sets in a single request
+func (c *Conn) CloseResultSets() error {
+	var handles []int
+	for _, h := range c.OpenHandles() {
+		if h.Type == ResultSetHandle {
+			handles = append(handles, h.Handle)
+		}
+	}
+	if len(handles) == 0 {
+		return nil
+	}
+	err := c.closeResultSetHandles(handles...)
+	if err != nil {
+		return c.errorf("Unable to close result sets: %w", err)
+	}
+	return nil
+}
+
+/*--- Private Routines ---*/
+
+type handleKey struct {
+	typ    HandleType
+	handle int
+}
+
+func (c *Conn) trackHandle(typ HandleType, handle int, sql string) {
+	c.handleMux.Lock()
+	defer c.handleMux.Unlock()
+	if c.handles == nil {
+		c.handles = map[handleKey]*OpenHandle{}
+	}
+	c.handles[handleKey{typ, handle}] = &OpenHandle{
+		Type:   typ,
+		Handle: handle,
+		SQL:    sql,
+		Opened: time.Now(),
+	}
+}
+
+func (c *Conn) untrackHandle(typ HandleType, handle int) {
+	c.handleMux.Lock()
+	defer c.handleMux.Unlock()
+	delete(c.handles, handleKey{typ, handle})
+}
+
+func (c *Conn) isTracked(typ HandleType, handle int) bool {
+	c.handleMux.Lock()
+	defer c.handleMux.Unlock()
+	_, ok := c.handles[handleKey{typ, handle}]
+	return ok
+}
+
+func (c *Conn) trackResultSets(sql string, res *execRes) {
+	if res.ResponseData == nil {
+		return
+	}
+	for _, r := range res.ResponseData.Results {
+		if r.ResultSet != nil && r.ResultSet.ResultSetHandle > 0 {
+			c.trackHandle(ResultSetHandle, r.ResultSet.ResultSetHandle, sql)
+		}
+	}
+}
+
+func (c *Conn) warnOfLeaks() {
+	for _, h := range c.OpenHandles() {
+		if h.Cached {
+			continue
+		}
+		c.log.Warningf(
+			"Disconnecting with open %s %d (opened %s ago) for SQL: %s",
+			h.Type, h.Handle, time.Since(h.Opened).Round(time.Millisecond), h.SQL,
+		)
+	}
+}
@@ -0,0 +1,153 @@
+package exasol
+
+import (
+	"bytes"
+	"time"
+)
+
+func (s *testSuite) TestOpenHandles() {
+	output := &bytes.Buffer{}
+	logger := customTestLogger("warning")
+	logger.SetOutput(output)
+	conf := s.connConf()
+	conf.Logger = logger
+	conf.CachePrepStmts = true
+	conf.WarnOnLeaks = true
+	c, err := Connect(conf)
+	s.Require().NoError(err)
+	s.Empty(c.OpenHandles())
+
+	_, err = c.FetchSlice("SELECT 123 FROM dual WHERE true = ?", []interface{}{true})
+	s.NoError(err)
+	handles := c.OpenHandles()
+	if s.Len(handles, 1) {
+		s.Equal(PrepStmtHandle, handles[0].Type)
+		s.True(handles[0].Cached)
+	}
+
+	// Fully consumed result sets are closed
+	_, err = c.FetchSlice("SELECT level FROM dual CONNECT BY level <= 5000")
+	s.NoError(err)
+	s.Len(c.OpenHandles(), 1)
+
+	// Whereas Execute doesn't consume the result set
+	leak := "SELECT level FROM dual CONNECT BY level <= 5001"
+	_, err = c.Execute(leak)
+	s.NoError(err)
+	handles = c.OpenHandles()
+	if s.Len(handles, 2) {
+		s.Equal(ResultSetHandle, handles[1].Type)
+		s.Equal(leak, handles[1].SQL)
+		s.False(handles[1].Cached)
+	}
+
+	c.Disconnect()
+	s.Contains(output.String(), "Disconnecting with open result set")
+	s.Contains(output.String(), leak)
+	s.NotContains(output.String(), "prepared statement", "Cached statements aren't leaks")
+}
+
+func (s *testSuite) TestCloseHandleInUse() {
+	conf := s.connConf()
+	conf.CachePrepStmts = true
+	c, err := Connect(conf)
+	s.Require().NoError(err)
+	defer c.Disconnect()
+
+	sql := "SELECT 123 FROM dual WHERE true = ?"
+	binds := []interface{}{true}
+	_, err = c.FetchSlice(sql, binds)
+	s.Require().NoError(err)
+	handles := c.OpenHandles()
+	s.Require().Len(handles, 1)
+
+	// Check the statement out as an execution would
+	ps, err := c.getPrepStmt("", sql)
+	s.Require().NoError(err)
+	s.Equal(handles[0].Handle, ps.sth)
+	s.NoError(c.CloseHandle(handles[0]))
+	s.False(ps.closed, "Not closed while in use")
+	handles = c.OpenHandles()
+	if s.Len(handles, 1) {
+		s.False(handles[0].Cached, "No longer cached")
+	}
+
+	c.releasePrepStmt(ps)
+	s.True(ps.closed, "Closed once released")
+	s.Empty(c.OpenHandles())
+	_, err = c.FetchSlice(sql, binds)
+	s.NoError(err, "Prepared afresh")
+}
+
+func (s *testSuite) TestAbandonedFetch() {
+	output := &bytes.Buffer{}
+	logger := customTestLogger("warning")
+	logger.SetOutput(output)
+	conf := s.connConf()
+	conf.Logger = logger
+	conf.AbandonedFetchTimeout = 100 * time.Millisecond
+	c, err := Connect(conf)
+	s.Require().NoError(err)
+	defer c.Disconnect()
+
+	sql := "SELECT level FROM dual CONNECT BY level <= 5000"
+	ch, err := c.FetchChan(sql)
+	s.Require().NoError(err)
+	<-ch // Stop reading early
+	s.Len(c.OpenHandles(), 1)
+
+	for i := 0; i < 100 && len(c.OpenHandles()) > 0; i++ {
+		time.Sleep(20 * time.Millisecond)
+	}
+	s.Empty(c.OpenHandles(), "Result set was closed")
+	s.Contains(output.String(), "FetchChan consumer hasn't read anything for 100ms")
+	s.Contains(output.String(), sql)
+}
+
+func (s *testSuite) TestInterleavedResultSets() {
+	c, err := Connect(s.connConf())
+	s.Require().NoError(err)
+	defer c.Disconnect()
+
+	// Large enough to need several fetches each
+	ch1, err := c.FetchChan("SELECT level FROM dual CONNECT BY level <= 50000")
+	s.Require().NoError(err)
+	ch2, err := c.FetchChan("SELECT -level FROM dual CONNECT BY level <= 50000")
+	s.Require().NoError(err)
+	s.Len(c.OpenHandles(), 2)
+
+	var sum1, sum2 float64
+	for ch1 != nil || ch2 != nil {
+		select {
+		case r, ok := <-ch1:
+			if !ok {
+				ch1 = nil
+				continue
+			}
+			s.Require().NoError(r.Error)
+			sum1 += r.Data[0].(float64)
+		case r, ok := <-ch2:
+			if !ok {
+				ch2 = nil
+				continue
+			}
+			s.Require().NoError(r.Error)
+			sum2 += r.Data[0].(float64)
+		}
+	}
+	s.Equal(float64(50000*50001/2), sum1)
+	s.Equal(-sum1, sum2)
+	s.Empty(c.OpenHandles())
+
+	// Explicitly closing handles
+	_, err = c.Execute("SELECT level FROM dual CONNECT BY level <= 5001")
+	s.NoError(err)
+	_, err = c.Execute("SELECT level FROM dual CONNECT BY level <= 5002")
+	s.NoError(err)
+	handles := c.OpenHandles()
+	s.Require().Len(handles, 2)
+	s.NoError(c.CloseHandle(handles[0]))
+	s.Len(c.OpenHandles(), 1)
+	s.NoError(c.CloseResultSets())
+	s.Empty(c.OpenHandles())
+}
@@ -0,0 +1,237 @@
+/*
+	AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"os/user"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+)
+
+func (c *Conn) login(rejected bool) error {
+	creds, err := c.credentials(rejected)
+	if err != nil {
+		return err
+	}
+	if creds.AccessToken != "" || creds.RefreshToken != "" {
+		return c.tokenLogin(creds)
+	}
+	if c.Conf.PlainLogin {
+		return c.plainLogin(creds)
+	}
+
+	loginReq := &loginReq{
+		Command:         "login",
+		ProtocolVersion: c.protocolVersion(),
+	}
+	loginRes := &loginRes{}
+	err = c.send(loginReq, loginRes)
+	if err != nil {
+		return err
+	}
+
+	pubKeyMod, _ := hex.DecodeString(loginRes.ResponseData.PublicKeyModulus)
+	var modulus big.Int
+	modulus.SetBytes(pubKeyMod)
+
+	pubKeyExp, _ := strconv.ParseUint(loginRes.ResponseData.PublicKeyExponent, 16, 32)
+
+	pubKey := rsa.PublicKey{
+		N: &modulus,
+		E: int(pubKeyExp),
+	}
+	password := []byte(creds.Password)
+	defer zeroBytes(password)
+	encPass, err := rsa.EncryptPKCS1v15(rand.Reader, &pubKey, password)
+	if err != nil {
+		return fmt.Errorf("Password encryption error: %w", err)
+	}
+	b64Pass := base64.StdEncoding.EncodeToString(encPass)
+
+	return c.authenticate(c.newAuthReq(creds.Username, b64Pass))
+}
+
+// In protocol v3 the credentials can be sent along with the login
+// command itself because the channel is already encrypted.
+func (c *Conn) plainLogin(creds *Credentials) error {
+	if c.tlsConfig == nil {
+		return errors.New("PlainLogin requires a TLSConfig")
+	}
+	if c.protocolVersion() < 3 {
+		return fmt.Errorf("PlainLogin requires protocol version 3+ not %d", c.protocolVersion())
+	}
+
+	req := &plainLoginReq{
+		Command:         "login",
+		ProtocolVersion: c.protocolVersion(),
+		authReq:         *c.newAuthReq(creds.Username, creds.Password),
+	}
+	return c.authenticate(req)
+}
+
+// OpenID token authentication (protocol v3+)
+func (c *Conn) tokenLogin(creds *Credentials) error {
+	if c.protocolVersion() < 3 {
+		return fmt.Errorf("Token login requires protocol version 3+ not %d", c.protocolVersion())
+	}
+	err := c.send(&loginReq{
+		Command:         "loginToken",
+		ProtocolVersion: c.protocolVersion(),
+	}, &loginRes{})
+	if err != nil {
+		return err
+	}
+	req := c.newAuthReq("", "")
+	if creds.RefreshToken != "" {
+		req.RefreshToken = creds.RefreshToken
+	} else {
+		req.AccessToken = creds.AccessToken
+	}
+	return c.authenticate(req)
+}
+
+func (c *Conn) newAuthReq(username, password string) *authReq {
+	osUsername := c.Conf.ClientOSUsername
+	if osUsername == "" {
+		// This can fail e.g. in containers running as an unnamed uid
+		if osUser, err := user.Current(); err == nil {
+			osUsername = osUser.Username
+		}
+	}
+	// Only default what wasn't configured
+	clientName, clientVersion := c.Conf.ClientName, c.Conf.ClientVersion
+	if clientName == "" || clientVersion == "" {
+		name, version := buildInfoClient()
+		if clientName == "" {
+			clientName = name
+		}
+		if clientVersion == "" {
+			clientVersion = version
+		}
+	}
+
+	authReq := &authReq{
+		Username:         username,
+		Password:         password,
+		UseCompression:   false, // TODO: See if we can get compression working
+		ClientName:       clientName,
+		ClientVersion:    clientVersion, // The version of the calling application
+		DriverName:       driverName(),
+		ClientOs:         runtime.GOOS + "/" + runtime.GOARCH,
+		ClientOsUsername: osUsername,
+		ClientRuntime:    runtime.Version(),
+		Attributes: &Attributes{
+			// Default AutoCommit to on unless the connection is read-only
+			Autocommit:    !c.Conf.ReadOnly,
+			CurrentSchema: c.Conf.Schema,
+		},
+	}
+	if c.Conf.ReadOnly {
+		authReq.Attributes.Include("autocommit")
+	}
+
+	if c.queryTimeout.Seconds() > 0 {
+		authReq.Attributes.QueryTimeout = uint32(c.queryTimeout.Seconds())
+	}
+	authReq.Attributes.ResultSetMaxRows = c.Conf.MaxRows
+	return authReq
+}
+
+func (c *Conn) authenticate(req interface{}) error {
+	authResp := &authResp{}
+	err := c.send(req, authResp)
+	if err != nil {
+		return fmt.Errorf("Unable to authenticate: %w", err)
+	}
+
+	c.updateAttributes(func(a *Attributes) {
+		a.Autocommit = !c.Conf.ReadOnly
+		a.CurrentSchema = c.Conf.Schema
+		a.QueryTimeout = uint32(c.queryTimeout.Seconds())
+		a.ResultSetMaxRows = c.Conf.MaxRows
+	})
+	c.trackAttributes(authResp.Attributes)
+	c.SessionID = authResp.ResponseData.SessionID
+	c.Metadata = authResp.ResponseData
+	c.log.Info("Connected SessionID:", c.SessionID)
+	c.wsh.EnableCompression(false)
+
+	return nil
+}
+
+// Unless configured the client is identified (e.g. in EXA_DBA_SESSIONS)
+// by the main module's path and version.
+func buildInfoClient() (name, version string) {
+	info, ok := debug.ReadBuildInfo()
+	if !ok || info.Main.Path == "" {
+		return "", ""
+	}
+	return info.Main.Path, info.Main.Version
+}
+
+// Includes the module version when this driver is a dependency
+func driverName() string {
+	name := "go-exasol-client v" + DriverVersion
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, dep := range info.Deps {
+			if dep.Path == driverModule {
+				return name + " (" + dep.Version + ")"
+			}
+		}
+	}
+	return name
+}
+
+const driverModule = "github.com/grantstreetgroup/go-exasol-client"
+
+// The protocol version negotiated with the server
+func (c *Conn) ProtocolVersion() uint16 {
+	if c.Metadata != nil && c.Metadata.ProtocolVersion > 0 {
+		return uint16(c.Metadata.ProtocolVersion)
+	}
+	return c.protocolVersion()
+}
+
+// The protocol version requested at login
+func (c *Conn) protocolVersion() uint16 {
+	if c.protoVersion > 0 {
+		return c.protoVersion
+	}
+	if c.Conf.ProtocolVersion == 0 {
+		return ExasolAPIVersion
+	}
+	return c.Conf.ProtocolVersion
+}
+
+func (c *Conn) minProtocolVersion() uint16 {
+	if c.Conf.MinProtocolVersion == 0 {
+		return 1
+	}
+	return c.Conf.MinProtocolVersion
+}
+
+var protocolErrorRE = regexp.MustCompile(`(?i)protocol ?version`)
+
+func isProtocolError(err error) bool {
+	var exaErr *ExaError
+	return errors.As(err, &exaErr) && protocolErrorRE.MatchString(exaErr.Text)
+}
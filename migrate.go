@@ -0,0 +1,160 @@
+/*
+	A lightweight migration runner for teams that don't want a
+	third-party framework (for golang-migrate see the migrate module).
+	Files named like 001_create_foo.sql are applied in version order,
+	each in its own transaction along with recording its version in
+	the schema_version table, e.g.
+
+	    //go:embed migrations/*.sql
+	    var migrations embed.FS
+
+	    err := conn.Migrate(migrations, "migrations")
+
+	Files may contain multiple statements. See script.go
+
+
+	AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const SchemaVersionTable = "schema_version"
+
+// Applies the .sql files in dir that are newer than the schema's version.
+// Accepts any fs.FS e.g. an embed.FS or os.DirFS.
+func (c *Conn) Migrate(fsys fs.FS, dir string) error {
+	migrations, err := readMigrations(fsys, dir)
+	if err != nil {
+		return c.errorf("Unable to read migrations: %w", err)
+	}
+	_, err = c.execute(
+		"CREATE TABLE IF NOT EXISTS "+SchemaVersionTable+` (
+			version    DECIMAL(18,0) NOT NULL,
+			name       VARCHAR(2000) NOT NULL,
+			applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`, &ExecConf{},
+	)
+	if err != nil {
+		return c.errorf("Unable to create %s: %w", SchemaVersionTable, err)
+	}
+	current, err := c.schemaVersion()
+	if err != nil {
+		return c.errorf("Unable to get the schema version: %w", err)
+	}
+
+	wasAutocommit := c.Autocommit()
+	if err := c.DisableAutoCommit(); err != nil {
+		return err
+	}
+	if wasAutocommit {
+		defer c.EnableAutoCommit()
+	}
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		c.log.Infof("Applying migration %s", m.name)
+		err := c.applyMigration(m)
+		if err != nil {
+			c.Rollback()
+			return c.errorf("Unable to apply migration %s: %w", m.name, err)
+		}
+		err = c.Commit()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*--- Private Routines ---*/
+
+type migration struct {
+	version int64
+	name    string
+	sql     string
+}
+
+var migrationName = regexp.MustCompile(`^(\d+).*\.sql$`)
+
+func readMigrations(fsys fs.FS, dir string) ([]*migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	migrations := []*migration{}
+	versions := map[int64]string{}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".sql") {
+			continue
+		}
+		m := migrationName.FindStringSubmatch(e.Name())
+		if m == nil {
+			return nil, fmt.Errorf("Migration %s doesn't start with a version number", e.Name())
+		}
+		version, err := strconv.ParseInt(m[1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		if dup, ok := versions[version]; ok {
+			return nil, fmt.Errorf("Migrations %s and %s have the same version", dup, e.Name())
+		}
+		versions[version] = e.Name()
+		sql, err := fs.ReadFile(fsys, path.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, &migration{version, e.Name(), string(sql)})
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].version < migrations[j].version
+	})
+	return migrations, nil
+}
+
+// Returns -1 if no migrations have been applied so that 000_ is applied
+func (c *Conn) schemaVersion() (int64, error) {
+	rows, err := c.FetchSlice("SELECT COALESCE(MAX(version), -1) FROM " + SchemaVersionTable)
+	if err != nil {
+		return 0, err
+	}
+	switch v := rows[0][0].(type) {
+	case float64:
+		return int64(v), nil
+	case string:
+		return strconv.ParseInt(v, 10, 64)
+	}
+	return 0, fmt.Errorf("Unexpected version %v", rows[0][0])
+}
+
+func (c *Conn) applyMigration(m *migration) error {
+	for _, stmt := range SplitStatements(m.sql) {
+		_, err := c.execute(stmt.SQL, &ExecConf{})
+		if err != nil {
+			return err
+		}
+	}
+	_, err := c.execute(
+		"INSERT INTO "+SchemaVersionTable+" (version, name) VALUES (?, ?)",
+		&ExecConf{Binds: [][]interface{}{{m.version, m.name}}},
+	)
+	return err
+}
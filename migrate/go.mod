@@ -0,0 +1,15 @@
+module github.com/grantstreetgroup/go-exasol-client/migrate
+
+go 1.18
+
+require (
+	github.com/golang-migrate/migrate/v4 v4.17.1
+	github.com/grantstreetgroup/go-exasol-client v0.0.0-00010101000000-000000000000
+)
+
+require (
+	github.com/gorilla/websocket v1.4.2 // indirect
+	go.uber.org/atomic v1.7.0 // indirect
+)
+
+replace github.com/grantstreetgroup/go-exasol-client => ../
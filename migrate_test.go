@@ -0,0 +1,50 @@
+package exasol
+
+import (
+	"testing/fstest"
+)
+
+func (s *testSuite) TestMigrate() {
+	conf := s.connConf()
+	conf.Schema = s.schema
+	conf.SuppressError = true
+	c, err := Connect(conf)
+	s.Require().NoError(err)
+	defer c.Disconnect()
+
+	version, err := c.schemaVersion()
+	s.NoError(err)
+	s.Equal(int64(-1), version, "No migrations applied")
+
+	fsys := fstest.MapFS{
+		"m/000_init.sql": {Data: []byte("CREATE TABLE bar ( id INT )")},
+		"m/001_create.sql": {Data: []byte(`
+			CREATE TABLE foo ( id INT, txt VARCHAR(10) );
+			-- Semicolons in comments; and strings are ignored
+			INSERT INTO foo VALUES (1, 'a;b');
+		`)},
+		"m/002_insert.sql": {Data: []byte("INSERT INTO foo VALUES (2, 'c')")},
+		"m/README.txt":     {Data: []byte("Not a migration")},
+	}
+	s.Require().NoError(c.Migrate(fsys, "m"))
+	got, err := c.FetchSlice("SELECT txt FROM foo ORDER BY id")
+	s.NoError(err)
+	s.Equal([][]interface{}{{"a;b"}, {"c"}}, got)
+	s.True(c.Autocommit(), "Autocommit restored")
+	exists, err := c.TableExists("", "bar")
+	s.NoError(err)
+	s.True(exists, "Version 0 migration applied")
+
+	// Already applied migrations are skipped and failures are rolled back
+	fsys["m/003_bad.sql"] = &fstest.MapFile{Data: []byte(`
+		INSERT INTO foo VALUES (3, 'd');
+		ASDF;
+	`)}
+	s.Error(c.Migrate(fsys, "m"))
+	version, err = c.schemaVersion()
+	s.NoError(err)
+	s.Equal(int64(2), version)
+	got, err = c.FetchSlice("SELECT COUNT(*) FROM foo")
+	s.NoError(err)
+	s.Equal(float64(2), got[0][0], "Failed migration rolled back")
+}
@@ -0,0 +1,166 @@
+/*
+	Helpers for paging through large query results e.g. for API backends.
+
+	FetchPage is the simple offset/limit approach. Each page requires the
+	server to skip the preceding rows so deep pages get progressively slower.
+
+	FetchAfter uses keyset (aka seek) pagination instead. Rather than a page
+	number you pass in the sort key of the last row you've seen, e.g.
+
+	    sql := "SELECT id, name FROM users"
+	    rows, err := conn.FetchAfter(sql, []string{"id"}, nil, 1000)
+	    for len(rows) > 0 {
+	        ...
+	        rows, err = conn.FetchAfter(sql, []string{"id"}, rows[len(rows)-1][:1], 1000)
+	    }
+
+
+	AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type Page struct {
+	Rows     [][]interface{}
+	Page     int
+	PageSize int
+	// The total number of rows across all pages. Only calculated when the
+	// WithTotalCount option is given, otherwise it's -1.
+	Total int64
+}
+
+// Returns the given page (starting at 1) of the query's results.
+// The optional args are ExecOptions (e.g. WithBinds) or the deprecated
+// positional binds and schema the same as FetchChan.
+// The query should have an ORDER BY otherwise the rows on each page
+// are not deterministic. The LIMIT and OFFSET are appended to the query
+// itself (a subselect's order isn't guaranteed to be kept) after
+// stripping any trailing comments so it mustn't have a LIMIT of its own.
+func (c *Conn) FetchPage(sql string, page, pageSize int, args ...interface{}) (*Page, error) {
+	if page < 1 || pageSize < 1 {
+		return nil, c.errorf("Invalid page %d or pageSize %d", page, pageSize)
+	}
+	ec, err := c.fetchArgsConf(args)
+	if err != nil {
+		return nil, err
+	}
+	opts := ec.options()
+
+	sql = trimStmt(sql)
+	pageSQL := fmt.Sprintf("%s LIMIT %d OFFSET %d", sql, pageSize, (page-1)*pageSize)
+	rows, err := c.FetchSlice(pageSQL, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Page{Rows: rows, Page: page, PageSize: pageSize, Total: -1}
+	if ec.TotalCount {
+		count, err := c.FetchSlice(fmt.Sprintf("SELECT COUNT(*) FROM (%s)", sql), opts...)
+		if err != nil {
+			return nil, err
+		}
+		total, ok := count[0][0].(float64)
+		if !ok {
+			return nil, c.errorf("Unexpected total count: %v", count[0][0])
+		}
+		p.Total = int64(total)
+	}
+	return p, nil
+}
+
+// Returns up to n rows of the query's results that sort after lastKey.
+// The rows are ordered (ascending) by orderCols which must uniquely identify
+// a row and lastKey holds the values of those columns for the last row of
+// the previous page. Pass in a nil lastKey to get the first page.
+// Don't include an ORDER BY in the query, it is added for you.
+// The optional args are the same as FetchPage's.
+func (c *Conn) FetchAfter(sql string, orderCols []string, lastKey []interface{}, n int, args ...interface{}) ([][]interface{}, error) {
+	if len(orderCols) == 0 {
+		return nil, c.error("FetchAfter requires at least one order column")
+	}
+	if lastKey != nil && len(lastKey) != len(orderCols) {
+		return nil, c.errorf("lastKey has %d values but there are %d order columns", len(lastKey), len(orderCols))
+	}
+	if n < 1 {
+		return nil, c.errorf("Invalid n %d", n)
+	}
+	ec, err := c.fetchArgsConf(args)
+	if err != nil {
+		return nil, err
+	}
+
+	cols := make([]string, len(orderCols))
+	for i, col := range orderCols {
+		cols[i] = c.QuoteIdent(col)
+	}
+
+	var binds []interface{}
+	if len(ec.Binds) > 0 {
+		binds = append(binds, ec.Binds[0]...)
+	}
+	where := ""
+	if lastKey != nil {
+		// (a > ?) OR (a = ? AND b > ?) OR ...
+		// Exasol doesn't support row value comparisons i.e. (a, b) > (?, ?)
+		preds := make([]string, len(cols))
+		for i := range cols {
+			conds := []string{}
+			for j := 0; j < i; j++ {
+				conds = append(conds, cols[j]+" = ?")
+				binds = append(binds, lastKey[j])
+			}
+			conds = append(conds, cols[i]+" > ?")
+			binds = append(binds, lastKey[i])
+			preds[i] = "(" + strings.Join(conds, " AND ") + ")"
+		}
+		where = " WHERE " + strings.Join(preds, " OR ")
+	}
+
+	pageSQL := fmt.Sprintf(
+		"SELECT * FROM (%s)%s ORDER BY %s LIMIT %d",
+		trimStmt(sql), where, strings.Join(cols, ", "), n,
+	)
+	ec.Binds = nil
+	if len(binds) > 0 {
+		ec.Binds = [][]interface{}{binds}
+	}
+	return c.FetchSlice(pageSQL, ec.options()...)
+}
+
+/*--- Private Routines ---*/
+
+var trailingSemicolon = regexp.MustCompile(`;\s*$`)
+
+// Strips whitespace, any trailing semicolon and trailing comments so
+// the statement can be embedded as a subselect or appended to.
+// Otherwise e.g. a -- comment would swallow whatever is appended.
+func trimStmt(sql string) string {
+	for {
+		sql = strings.TrimSpace(trailingSemicolon.ReplaceAllString(sql, ""))
+		locs := quotedOrComment.FindAllStringIndex(sql, -1)
+		if len(locs) == 0 {
+			return sql
+		}
+		last := locs[len(locs)-1]
+		comment := strings.HasPrefix(sql[last[0]:], "--") ||
+			strings.HasPrefix(sql[last[0]:], "/*")
+		if !comment || strings.TrimSpace(sql[last[1]:]) != "" {
+			return sql
+		}
+		sql = sql[:last[0]]
+	}
+}
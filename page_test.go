@@ -0,0 +1,93 @@
+package exasol
+
+func (s *testSuite) TestFetchPage() {
+	exa := s.exaConn
+	exa.Execute("CREATE TABLE foo ( id INT, val CHAR(1) )")
+	exa.Execute(
+		"INSERT INTO foo VALUES (?,?)",
+		WithBinds([]interface{}{1, 2, 3, 4, 5}, []interface{}{"a", "b", "c", "d", "e"}),
+		WithColumnar(),
+	)
+
+	got, err := exa.FetchPage("SELECT * FROM foo ORDER BY id;", 2, 2)
+	if s.NoError(err) {
+		expect := [][]interface{}{
+			{float64(3), "c"},
+			{float64(4), "d"},
+		}
+		s.Equal(expect, got.Rows)
+		s.Equal(int64(-1), got.Total)
+	}
+
+	got, err = exa.FetchPage(
+		"SELECT * FROM foo WHERE id > ? ORDER BY id", 2, 2,
+		WithBinds([]interface{}{1}), WithTotalCount(),
+	)
+	if s.NoError(err) {
+		s.Equal([][]interface{}{{float64(4), "d"}, {float64(5), "e"}}, got.Rows)
+		s.Equal(int64(4), got.Total)
+	}
+
+	got, err = exa.FetchPage("SELECT * FROM foo ORDER BY id DESC", 1, 2)
+	if s.NoError(err) {
+		s.Equal([][]interface{}{{float64(5), "e"}, {float64(4), "d"}}, got.Rows, "Order kept")
+	}
+
+	got, err = exa.FetchPage("SELECT * FROM foo ORDER BY id; -- by id\n/* done */", 1, 2)
+	if s.NoError(err) {
+		s.Len(got.Rows, 2, "Trailing comments don't swallow the LIMIT")
+	}
+	s.Equal("SELECT '--' FROM foo", trimStmt("SELECT '--' FROM foo -- x"))
+
+	got, err = exa.FetchPage("SELECT * FROM foo ORDER BY id", 4, 2)
+	if s.NoError(err) {
+		s.Empty(got.Rows)
+	}
+
+	exa.Conf.SuppressError = true
+	_, err = exa.FetchPage("SELECT * FROM foo", 0, 2)
+	s.Error(err)
+}
+
+func (s *testSuite) TestFetchAfter() {
+	exa := s.exaConn
+	exa.Execute("CREATE TABLE foo ( grp INT, id INT, val CHAR(1) )")
+	exa.Execute(
+		"INSERT INTO foo VALUES (?,?,?)",
+		WithBinds(
+			[]interface{}{1, 1, 1, 2, 2},
+			[]interface{}{1, 2, 3, 1, 2},
+			[]interface{}{"a", "b", "c", "d", "e"},
+		),
+		WithColumnar(),
+	)
+
+	sql := "SELECT * FROM foo WHERE val <> ?"
+	keys := []string{"grp", "id"}
+	binds := WithBinds([]interface{}{"c"})
+
+	got, err := exa.FetchAfter(sql, keys, nil, 2, binds)
+	if s.NoError(err) {
+		s.Equal([][]interface{}{
+			{float64(1), float64(1), "a"},
+			{float64(1), float64(2), "b"},
+		}, got)
+	}
+
+	got, err = exa.FetchAfter(sql, keys, []interface{}{1, 2}, 2, binds)
+	if s.NoError(err) {
+		s.Equal([][]interface{}{
+			{float64(2), float64(1), "d"},
+			{float64(2), float64(2), "e"},
+		}, got)
+	}
+
+	got, err = exa.FetchAfter(sql, keys, []interface{}{2, 2}, 2, binds)
+	if s.NoError(err) {
+		s.Empty(got)
+	}
+
+	exa.Conf.SuppressError = true
+	_, err = exa.FetchAfter(sql, keys, []interface{}{1}, 2, binds)
+	s.Error(err)
+}
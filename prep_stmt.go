@@ -14,58 +14,206 @@ package exasol
 
 import (
 	"sort"
+	"sync"
 	"time"
 )
 
+const defaultMaxOpenStmts = 1000
+
+// The cache (along with warmStmts and the Stmt* Stats) is guarded by
+// Conn.stmtMux so that concurrent Executes can share it. Statements
+// are reference counted while executing so that they aren't evicted
+// from under each other. The lock is never held while waiting on the
+// server: statements are reserved or retired under it and then
+// prepared or closed after it's released.
 type prepStmt struct {
 	sth      int
-	columns  []column
+	columns  []Column
 	lastUsed time.Time
+	inUse    int
+	dropped  bool // From the cache while in use so it's closed on release
+	closed   bool // Or forgotten, so its handle isn't closed twice
+}
+
+// The same SQL prepared with different current schemas can refer
+// to different tables so the schema is part of the cache key.
+type prepStmtKey struct {
+	schema string
+	sql    string
+}
+
+// Prepares (and caches) the statements of a known workload up front
+// e.g. at startup so that the first requests don't pay for it.
+// Requires CachePrepStmts. The statements are prepared in the current
+// schema and are re-prepared in it after each Reconnect.
+func (c *Conn) PrepareAll(sqls []string) error {
+	if !c.Conf.CachePrepStmts {
+		return c.error("PrepareAll requires CachePrepStmts")
+	}
+	for _, sql := range sqls {
+		key := c.prepStmtKey("", sql)
+		ps, err := c.getPrepStmt(key.schema, sql)
+		if err != nil {
+			return c.errorf("Unable to prepare %s: %w", sql, err)
+		}
+		c.releasePrepStmt(ps)
+		c.stmtMux.Lock()
+		c.warmStmts[key] = true
+		c.stmtMux.Unlock()
+	}
+	return nil
 }
 
+// DDL can invalidate the server-side handles of cached prepared statements.
+// These allow you to purge them proactively rather than waiting for
+// the next Execute to fail.
+
+// Invalidates the statement as prepared in any schema
+func (c *Conn) InvalidatePrepStmt(sql string) error {
+	c.stmtMux.Lock()
+	var sths []int
+	for key, ps := range c.prepStmtCache {
+		if key.sql == sql {
+			sths = append(sths, c.dropPrepStmt(key, ps)...)
+		}
+	}
+	c.stmtMux.Unlock()
+	return c.closeStmtHandles(sths)
+}
+
+func (c *Conn) ClearPrepStmtCache() error {
+	c.stmtMux.Lock()
+	var sths []int
+	for key, ps := range c.prepStmtCache {
+		sths = append(sths, c.dropPrepStmt(key, ps)...)
+	}
+	c.stmtMux.Unlock()
+	return c.closeStmtHandles(sths)
+}
+
+// The returned statement must be passed to releasePrepStmt (or
+// forgetPrepStmt) once it's been executed.
 func (c *Conn) getPrepStmt(schema, sql string) (*prepStmt, error) {
 	// TODO die if the num cols/rows expected by prepared statement
 	//      doesn't match the passed in data (i.e. placeholder/binds mismatch)
 	//      otherwise results in lowerlevel websocket closure
 
 	c.log.Debug("Preparing stmt for:", sql)
-	psc := c.prepStmtCache
-	ps := psc[sql]
-	if ps == nil {
-		var err error
-		ps, err = c.createPrepStmt(schema, sql)
-		if err != nil {
+	key := c.prepStmtKey(schema, sql)
+	c.stmtMux.Lock()
+	for {
+		if ps := c.prepStmtCache[key]; ps != nil {
+			c.Stats["StmtCacheHit"]++
+			ps.lastUsed = time.Now()
+			ps.inUse++
+			c.stmtsBusy++
+			c.stmtMux.Unlock()
+			return ps, nil
+		}
+		evicted, err := c.makeRoomForStmt()
+		if len(evicted) > 0 {
+			c.stmtMux.Unlock()
+			c.closeStmtHandles(evicted)
+			c.stmtMux.Lock()
+			continue // The cache may have changed in the meantime
+		}
+		if err == nil {
+			break
+		}
+		if c.stmtsBusy == 0 {
+			c.stmtMux.Unlock()
 			return nil, err
 		}
-		if c.Conf.CachePrepStmts {
-			psc[sql] = ps
-			c.Stats["StmtCacheLen"] = len(psc)
+		// Wait for a busy statement's handle to become evictable
+		c.stmtFreedCond().Wait()
+	}
+	// Reserve the handle so that concurrent prepares count against MaxOpenStmts
+	c.Stats["StmtHandlesOpen"]++
+	c.stmtsBusy++
+	c.stmtMux.Unlock()
+
+	ps, err := c.createPrepStmt(schema, sql)
+
+	c.stmtMux.Lock()
+	if err != nil {
+		c.Stats["StmtHandlesOpen"]--
+		c.unbusyStmt()
+		c.stmtMux.Unlock()
+		return nil, err
+	}
+	var dup []int
+	if c.Conf.CachePrepStmts {
+		if cached := c.prepStmtCache[key]; cached != nil {
+			// Another Execute prepared it in the meantime
+			dup = c.retirePrepStmt(ps)
+			ps = cached
+		} else {
+			c.prepStmtCache[key] = ps
+			c.Stats["StmtCacheLen"] = len(c.prepStmtCache)
 			c.Stats["StmtCacheMiss"]++
 		}
 	}
 	ps.lastUsed = time.Now()
+	ps.inUse++
+	c.stmtMux.Unlock()
+	c.closeStmtHandles(dup)
+	return ps, nil
+}
+
+// Closes the statement once executed unless it's cached
+func (c *Conn) releasePrepStmt(ps *prepStmt) {
+	c.stmtMux.Lock()
+	ps.inUse--
+	c.unbusyStmt()
+	var sths []int
+	if ps.inUse == 0 && (!c.Conf.CachePrepStmts || ps.dropped) {
+		sths = c.retirePrepStmt(ps)
+	}
+	c.stmtMux.Unlock()
+	c.closeStmtHandles(sths)
+}
+
+// Exasol is unhappy if there are thousands of open statements so
+// before opening another the least recently used cached ones are
+// evicted to stay under MaxOpenStmts. Returns the handles to close
+// once stmtMux, which the caller must hold, is released.
+func (c *Conn) makeRoomForStmt() ([]int, error) {
+	max := c.Conf.MaxOpenStmts
+	if max <= 0 {
+		max = defaultMaxOpenStmts
+	}
+	psc := c.prepStmtCache
+	if c.Stats["StmtHandlesOpen"] < max {
+		return nil, nil
+	}
 
-	// Prune the prep stmt cache. I don't know how necessary it is
-	// but I saw something on the site about Exasol
-	// being unhappy if there are thousands of open statements.
-	if len(psc) > 1000 {
-		sortedStmts := make([]string, len(psc))
-		i := 0
-		for sql := range psc {
-			sortedStmts[i] = sql
-			i++
+	sortedStmts := make([]prepStmtKey, 0, len(psc))
+	for key, ps := range psc {
+		if ps.inUse == 0 {
+			sortedStmts = append(sortedStmts, key)
 		}
-		sort.Slice(sortedStmts, func(i, j int) bool {
-			return psc[sortedStmts[i]].lastUsed.Before(psc[sortedStmts[j]].lastUsed)
-		})
-		leastUsed := sortedStmts[0]
-		c.closePrepStmt(psc[leastUsed].sth)
+	}
+	sort.Slice(sortedStmts, func(i, j int) bool {
+		return psc[sortedStmts[i]].lastUsed.Before(psc[sortedStmts[j]].lastUsed)
+	})
+	var evicted []int
+	for _, leastUsed := range sortedStmts {
+		if c.Stats["StmtHandlesOpen"] < max {
+			break
+		}
+		evicted = append(evicted, c.retirePrepStmt(psc[leastUsed])...)
 		delete(psc, leastUsed)
+		c.Stats["StmtCacheLen"] = len(psc)
+		c.Stats["StmtCacheEvict"]++
 	}
-
-	return ps, nil
+	if c.Stats["StmtHandlesOpen"] >= max {
+		return evicted, c.errorf("Unable to prepare statement: %w (%d)", ErrTooManyStmts, max)
+	}
+	return evicted, nil
 }
 
+// The caller must have reserved the handle in StmtHandlesOpen
+// and must not hold stmtMux.
 func (c *Conn) createPrepStmt(schema string, sql string) (*prepStmt, error) {
 	sthReq := &createPrepStmtReq{
 		Command:    "createPreparedStatement",
@@ -79,19 +227,129 @@ func (c *Conn) createPrepStmt(schema string, sql string) (*prepStmt, error) {
 	}
 
 	sth := sthRes.ResponseData.StatementHandle
+	c.trackHandle(PrepStmtHandle, sth, sql)
 	cols := sthRes.ResponseData.ParameterData.Columns
-	return &prepStmt{sth, cols, time.Now()}, nil
+	return &prepStmt{sth: sth, columns: cols, lastUsed: time.Now()}, nil
 }
 
-func (c *Conn) closePrepStmt(sth int) error {
-	c.log.Debug("Closing stmt handle ", sth)
-	closeReq := &closePrepStmt{
-		Command:         "closePreparedStatement",
-		StatementHandle: int(sth),
+// Re-prepares PrepareAll's statements after a Reconnect. Failures
+// (e.g. a table that's since been dropped) are only warned about as
+// the statement will just be prepared on demand instead.
+func (c *Conn) rewarmPrepStmts() {
+	c.stmtMux.Lock()
+	keys := make([]prepStmtKey, 0, len(c.warmStmts))
+	for key := range c.warmStmts {
+		keys = append(keys, key)
 	}
-	err := c.send(closeReq, &response{})
-	if err != nil {
-		return c.errorf("Unable to closePrepStmt: %s", err)
+	c.stmtMux.Unlock()
+	c.rePrepare(keys)
+}
+
+func (c *Conn) rePrepare(keys []prepStmtKey) {
+	for _, key := range keys {
+		ps, err := c.getPrepStmt(key.schema, key.sql)
+		if err != nil {
+			c.log.Warningf("Unable to re-prepare statement: %s", err)
+			continue
+		}
+		c.releasePrepStmt(ps)
 	}
-	return nil
+}
+
+// Statements prepared without an explicit schema use the current one
+func (c *Conn) prepStmtKey(schema, sql string) prepStmtKey {
+	if schema == "" {
+		schema = c.trackedAttributes().CurrentSchema
+	}
+	return prepStmtKey{schema: schema, sql: sql}
+}
+
+func (c *Conn) staleStmtRetries() int {
+	if c.Conf.StaleStmtRetries == 0 {
+		return 1
+	}
+	return c.Conf.StaleStmtRetries
+}
+
+// Drops a statement whose handle the server doesn't recognise
+// without trying to close it. This also releases it.
+func (c *Conn) forgetPrepStmt(schema, sql string, ps *prepStmt) {
+	c.stmtMux.Lock()
+	defer c.stmtMux.Unlock()
+	ps.inUse--
+	c.unbusyStmt()
+	if ps.closed {
+		return // Another Execute already found it stale
+	}
+	ps.closed = true
+	key := c.prepStmtKey(schema, sql)
+	if c.prepStmtCache[key] == ps {
+		delete(c.prepStmtCache, key)
+		c.Stats["StmtCacheLen"] = len(c.prepStmtCache)
+	}
+	c.Stats["StmtHandlesOpen"]--
+	c.untrackHandle(PrepStmtHandle, ps.sth)
+}
+
+// Getting a statement waits, rather than failing with ErrTooManyStmts,
+// while the handles that would be evicted are busy. The caller must
+// hold stmtMux.
+func (c *Conn) stmtFreedCond() *sync.Cond {
+	if c.stmtFreed == nil {
+		c.stmtFreed = sync.NewCond(&c.stmtMux)
+	}
+	return c.stmtFreed
+}
+
+// The caller must hold stmtMux
+func (c *Conn) unbusyStmt() {
+	c.stmtsBusy--
+	if c.stmtFreed != nil {
+		c.stmtFreed.Broadcast()
+	}
+}
+
+// Removes the statement from the cache. It's retired straight away
+// unless it's in use in which case releasePrepStmt retires it. The
+// caller must hold stmtMux and close the returned handles after.
+func (c *Conn) dropPrepStmt(key prepStmtKey, ps *prepStmt) []int {
+	delete(c.prepStmtCache, key)
+	c.Stats["StmtCacheLen"] = len(c.prepStmtCache)
+	if ps.inUse > 0 {
+		ps.dropped = true
+		return nil
+	}
+	return c.retirePrepStmt(ps)
+}
+
+// Marks the statement closed and returns its handle (unless it was
+// already closed) for closeStmtHandles to close once stmtMux, which
+// the caller must hold, is released.
+func (c *Conn) retirePrepStmt(ps *prepStmt) []int {
+	if ps.closed {
+		return nil
+	}
+	ps.closed = true
+	// Whether or not the close succeeds we no longer consider the handle usable
+	c.Stats["StmtHandlesOpen"]--
+	c.untrackHandle(PrepStmtHandle, ps.sth)
+	return []int{ps.sth}
+}
+
+// Returns the first error but tries to close all the handles.
+// The caller must not hold stmtMux.
+func (c *Conn) closeStmtHandles(sths []int) error {
+	var err error
+	for _, sth := range sths {
+		c.log.Debug("Closing stmt handle ", sth)
+		closeReq := &closePrepStmt{
+			Command:         "closePreparedStatement",
+			StatementHandle: sth,
+		}
+		e := c.send(closeReq, &response{})
+		if e != nil && err == nil {
+			err = c.errorf("Unable to closePrepStmt: %w", e)
+		}
+	}
+	return err
 }
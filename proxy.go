@@ -18,6 +18,7 @@ package exasol
 import (
 	"bytes"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"net"
 	"strconv"
@@ -32,6 +33,7 @@ type Proxy struct {
 	running bool
 	pool    *sync.Pool
 	log     Logger
+	limiter *rateLimiter // Optional bytes per second limit
 }
 
 func NewProxy(host string, port uint16, bufPool *sync.Pool, log Logger) (*Proxy, error) {
@@ -41,10 +43,11 @@ func NewProxy(host string, port uint16, bufPool *sync.Pool, log Logger) (*Proxy,
 	}
 
 	var err error
-	uri := fmt.Sprintf("%s:%d", host, port)
+	// Brackets IPv6 addresses
+	uri := net.JoinHostPort(host, strconv.Itoa(int(port)))
 	p.conn, err = net.Dial("tcp", uri)
 	if err != nil {
-		return nil, fmt.Errorf("Unable to setup proxy (1): %s", err)
+		return nil, fmt.Errorf("Unable to setup proxy (1): %w", err)
 	}
 	p.running = true
 
@@ -55,14 +58,14 @@ func NewProxy(host string, port uint16, bufPool *sync.Pool, log Logger) (*Proxy,
 	binary.LittleEndian.PutUint32(req[8:], 1)
 	_, err = p.conn.Write(req)
 	if err != nil {
-		return nil, fmt.Errorf("Unable to setup proxy (2): %s", err)
+		return nil, fmt.Errorf("Unable to setup proxy (2): %w", err)
 	}
 
 	// Exasol replies with the internal host/port it's listening on
 	resp := make([]byte, 24)
 	_, err = p.conn.Read(resp)
 	if err != nil {
-		return nil, fmt.Errorf("Unable to setup proxy (3): %s", err)
+		return nil, fmt.Errorf("Unable to setup proxy (3): %w", err)
 	}
 
 	p.Port = binary.LittleEndian.Uint32(resp[4:])
@@ -90,12 +93,12 @@ DATA:
 	for {
 		chunkSize, err := p.readLine()
 		if err != nil {
-			return totalRead, fmt.Errorf("Unable to read from proxy(2): %s", err)
+			return totalRead, fmt.Errorf("Unable to read from proxy(2): %w", err)
 		}
 
 		chunkLen, err := strconv.ParseInt(string(chunkSize), 16, 64)
 		if err != nil {
-			return totalRead, fmt.Errorf("Unable to parse chunkSize %s: %s", chunkSize, err)
+			return totalRead, fmt.Errorf("Unable to parse chunkSize %s: %w", chunkSize, err)
 		}
 		chunk := p.pool.Get().([]byte)
 		if chunkLen > int64(cap(chunk)) {
@@ -109,7 +112,7 @@ DATA:
 		for {
 			l, err := p.conn.Read(chunk[readLen:])
 			if err != nil {
-				return totalRead, fmt.Errorf("Unable to read from proxy(3): %s", err)
+				return totalRead, fmt.Errorf("Unable to read from proxy(3): %w", err)
 			}
 			readLen += l
 			if int64(readLen) == chunkLen {
@@ -118,7 +121,7 @@ DATA:
 		}
 		endOfChunk, err := p.readLine()
 		if len(endOfChunk) != 0 || err != nil {
-			return totalRead, fmt.Errorf("Unable to read from proxy(4):%s/%s", endOfChunk, err)
+			return totalRead, fmt.Errorf("Unable to read from proxy(4):%s/%w", endOfChunk, err)
 		}
 
 		if chunkLen == 0 {
@@ -132,6 +135,7 @@ DATA:
 		}
 
 		totalRead += chunkLen
+		p.limiter.wait(chunkLen, stop)
 		select {
 		case <-stop:
 			p.Shutdown()
@@ -144,6 +148,30 @@ DATA:
 }
 
 func (p *Proxy) Write(data <-chan []byte) (bytesWritten int64, err error) {
+	return p.write(data, nil)
+}
+
+func (p *Proxy) Shutdown() {
+	if p.IsRunning() {
+		if p.conn != nil {
+			p.conn.Close()
+		}
+		p.running = false
+	}
+}
+
+func (p *Proxy) IsRunning() bool {
+	return p.running
+}
+
+/* Private routines */
+
+// Returned by write if it was stopped before the data was all read
+var errProxyStopped = errors.New("Proxy write was stopped")
+
+// Stops reading the data once stop is closed so that whatever
+// is left can be sent elsewhere e.g. by retrying
+func (p *Proxy) write(data <-chan []byte, stop <-chan struct{}) (bytesWritten int64, err error) {
 	_, err = p.readHeaders()
 	if err != nil {
 		return bytesWritten, err
@@ -156,43 +184,39 @@ func (p *Proxy) Write(data <-chan []byte) (bytesWritten int64, err error) {
 		"Transfer-Encoding: chunked",
 		"Connection: close",
 	})
-
 	if err != nil {
-		err = fmt.Errorf("Unable to send headers to proxy: %s", err)
-	} else {
-		for b := range data {
-			l := int64(len(b))
-			bytesWritten += l
-			chunkSize := strconv.FormatInt(l, 16)
-			p.conn.Write([]byte(chunkSize))
-			p.conn.Write([]byte("\r\n"))
-			_, err = p.conn.Write(b)
-			if err != nil {
-				err = fmt.Errorf("Unable to upload data to proxy (2): %s", err)
-				break
-			}
-			p.conn.Write([]byte("\r\n"))
-		}
-		p.conn.Write([]byte("0\r\n\r\n")) // A final zero chunk
+		return bytesWritten, fmt.Errorf("Unable to send headers to proxy: %w", err)
 	}
-	return bytesWritten, err
-}
 
-func (p *Proxy) Shutdown() {
-	if p.IsRunning() {
-		if p.conn != nil {
-			p.conn.Close()
+	for {
+		var b []byte
+		var ok bool
+		select {
+		case b, ok = <-data:
+		case <-stop:
+			// No final chunk so the file isn't taken to be complete
+			return bytesWritten, errProxyStopped
 		}
-		p.running = false
+		if !ok {
+			break
+		}
+		l := int64(len(b))
+		p.limiter.wait(l, nil)
+		bytesWritten += l
+		chunkSize := strconv.FormatInt(l, 16)
+		p.conn.Write([]byte(chunkSize))
+		p.conn.Write([]byte("\r\n"))
+		_, err = p.conn.Write(b)
+		if err != nil {
+			err = fmt.Errorf("Unable to upload data to proxy (2): %w", err)
+			break
+		}
+		p.conn.Write([]byte("\r\n"))
 	}
+	p.conn.Write([]byte("0\r\n\r\n")) // A final zero chunk
+	return bytesWritten, err
 }
 
-func (p *Proxy) IsRunning() bool {
-	return p.running
-}
-
-/* Private routines */
-
 func (p *Proxy) readLine() ([]byte, error) {
 	var line bytes.Buffer
 	var err error
@@ -222,7 +246,7 @@ func (p *Proxy) sendHeaders(headers []string) error {
 		p.log.Debug("Sent Header: ", header)
 		_, err := p.conn.Write([]byte(header))
 		if err != nil {
-			return fmt.Errorf("Unable to send header <%s>to proxy: %s", header, err)
+			return fmt.Errorf("Unable to send header <%s>to proxy: %w", header, err)
 		}
 	}
 	return nil
@@ -232,7 +256,7 @@ func (p *Proxy) readHeaders() (headers []string, err error) {
 	for {
 		line, err := p.readLine()
 		if err != nil {
-			return headers, fmt.Errorf("Unable to read from proxy(1): %s", err)
+			return headers, fmt.Errorf("Unable to read from proxy(1): %w", err)
 		}
 		p.log.Debug("Got header:", string(line))
 		// Blank line means end of headers
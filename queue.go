@@ -0,0 +1,74 @@
+/*
+	The websocket API handles one command at a time per session so
+	concurrent callers of a Conn (multiple goroutines calling Execute,
+	FetchChan etc.) are queued here, first come first served, for each
+	request/response round trip. Callers therefore no longer need to
+	coordinate with Lock/Unlock just to share a connection. Statements
+	overriding session attributes (e.g. WithTimeout or WithAttributes)
+	also wait for other statements to finish and vice versa so that the
+	override only ever applies to its own statement.
+
+	A queued command gives up waiting once its context is done e.g. on
+	a WithContext deadline or a CommandTimeouts timeout.
+
+
+	AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+type cmdQueue struct {
+	once    sync.Once
+	slot    chan struct{} // Blocked senders are woken in FIFO order
+	waiting int32
+	peak    int32
+}
+
+// The number of commands currently waiting for the websocket
+func (c *Conn) QueueDepth() int {
+	return int(atomic.LoadInt32(&c.queue.waiting))
+}
+
+/*--- Private Routines ---*/
+
+func (q *cmdQueue) acquire(ctx context.Context) error {
+	q.once.Do(func() { q.slot = make(chan struct{}, 1) })
+
+	n := atomic.AddInt32(&q.waiting, 1)
+	defer atomic.AddInt32(&q.waiting, -1)
+	for {
+		peak := atomic.LoadInt32(&q.peak)
+		if n <= peak || atomic.CompareAndSwapInt32(&q.peak, peak, n) {
+			break
+		}
+	}
+
+	if ctx == nil {
+		q.slot <- struct{}{}
+		return nil
+	}
+	select {
+	case q.slot <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (q *cmdQueue) release() {
+	<-q.slot
+}
@@ -0,0 +1,89 @@
+/*
+	When ConnConf.ReadOnly is set only statements which can't modify
+	the database are sent to the server. Everything else fails with a
+	*ReadOnlyError before leaving the client, e.g.
+
+	    _, err := conn.Execute("DELETE FROM foo")
+	    var roErr *exasol.ReadOnlyError
+	    if errors.As(err, &roErr) { ... }
+
+	This is a client-side check on the statement's leading keyword
+	(and for SELECT ... INTO TABLE), not a substitute for granting the
+	user only SELECT privileges.
+
+
+	AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Returned when a read-only connection is asked to modify the database
+type ReadOnlyError struct {
+	SQL string
+}
+
+func (e *ReadOnlyError) Error() string {
+	return fmt.Sprintf("Refusing to run on a read-only connection: %s", e.SQL)
+}
+
+/*--- Private Routines ---*/
+
+// EXPORT only reads from the database (it's how StreamQuery works)
+// and COMMIT/ROLLBACK merely end the read transaction.
+var readOnlyKeywords = map[string]bool{
+	"SELECT":   true,
+	"WITH":     true,
+	"VALUES":   true,
+	"DESCRIBE": true,
+	"EXPORT":   true,
+	"COMMIT":   true,
+	"ROLLBACK": true,
+}
+
+var leadingNoise = regexp.MustCompile(`^(\s+|\(|--[^\n]*(\n|$)|/\*(?s:.*?)\*/)+`)
+var leadingWord = regexp.MustCompile(`^[A-Za-z]+`)
+
+// Literals, quoted identifiers and comments which could contain
+// e.g. "INTO TABLE" or a ? which isn't a placeholder
+var quotedOrComment = regexp.MustCompile(`'[^']*'|"[^"]*"|--[^\n]*|/\*(?s:.*?)\*/`)
+
+// SELECT ... INTO TABLE creates a table
+var intoTable = regexp.MustCompile(`(?i)\bINTO\s+TABLE\b`)
+
+func (c *Conn) checkReadOnly(sql string) error {
+	if !c.Conf.ReadOnly || isReadOnlyStmt(sql) {
+		return nil
+	}
+	return c.errorf("%w", &ReadOnlyError{SQL: sql})
+}
+
+// Per-statement attributes (i.e. WithAttributes) mustn't turn autocommit on
+func (c *Conn) checkReadOnlyAttrs(attrs *Attributes) error {
+	if !c.Conf.ReadOnly || attrs == nil || !attrs.Autocommit {
+		return nil
+	}
+	return c.errorf("%w", &ReadOnlyError{SQL: "autocommit on"})
+}
+
+func isReadOnlyStmt(sql string) bool {
+	sql = leadingNoise.ReplaceAllString(sql, "")
+	keyword := strings.ToUpper(leadingWord.FindString(sql))
+	if !readOnlyKeywords[keyword] {
+		return false
+	}
+	return !intoTable.MatchString(quotedOrComment.ReplaceAllString(sql, " "))
+}
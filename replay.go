@@ -0,0 +1,143 @@
+/*
+	By default Reconnect opens a session configured afresh from the
+	ConnConf. With ConnConf.ReplaySession set it instead re-applies the
+	old session's state so that application code can't tell the
+	difference:
+	  * The tracked attributes that can be set e.g. the current schema,
+	    autocommit and query timeout
+	  * Session parameters set via AlterSession
+	  * The time zone, if it was changed some other way
+	With ConnConf.ReplayPrepStmts the previously cached prepared
+	statements are also re-prepared. (Those from PrepareAll always are.)
+
+	Open transactions, result sets and ALTER SESSIONs executed directly
+	(other than of the time zone) can't be replayed.
+
+
+	AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"fmt"
+	"sort"
+)
+
+// The attributes that setAttributes accepts
+var replayableAttrs = []string{
+	"currentSchema",
+	"autocommit",
+	"queryTimeout",
+	"resultSetMaxRows",
+	"snapshotTransactionsEnabled",
+	"timestampUtcEnabled",
+	"feedbackInterval",
+}
+
+/*--- Private Routines ---*/
+
+type sessionState struct {
+	attrs     Attributes
+	altered   map[SessionParam]interface{}
+	prepStmts []prepStmtKey
+}
+
+// Must be called before resetSession
+func (c *Conn) saveSessionState() *sessionState {
+	st := &sessionState{
+		attrs:   c.trackedAttributes(),
+		altered: c.altered,
+	}
+	if c.Conf.ReplayPrepStmts {
+		c.stmtMux.Lock()
+		for key := range c.prepStmtCache {
+			st.prepStmts = append(st.prepStmts, key)
+		}
+		c.stmtMux.Unlock()
+	}
+	return st
+}
+
+func (c *Conn) replaySessionState(st *sessionState) error {
+	if c.Conf.ReplaySession {
+		err := c.replayAttributes(st.attrs)
+		if err != nil {
+			return c.errorf("Unable to replay session attributes: %w", err)
+		}
+		err = c.replayAlterSession(st)
+		if err != nil {
+			return c.errorf("Unable to replay session parameters: %w", err)
+		}
+	}
+	var keys []prepStmtKey
+	c.stmtMux.Lock()
+	for _, key := range st.prepStmts {
+		if !c.warmStmts[key] { // Otherwise done by rewarmPrepStmts
+			keys = append(keys, key)
+		}
+	}
+	c.stmtMux.Unlock()
+	c.rePrepare(keys)
+	return nil
+}
+
+func (c *Conn) replayAttributes(old Attributes) error {
+	now := c.trackedAttributes()
+	attrs := &Attributes{}
+	changed := false
+	for _, key := range replayableAttrs {
+		val := old.field(key)
+		if val == now.field(key) {
+			continue
+		}
+		if key == "autocommit" && c.Conf.ReadOnly && val == true {
+			continue
+		}
+		attrs.setField(key, val)
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	return c.setAttributes(attrs)
+}
+
+func (c *Conn) replayAlterSession(st *sessionState) error {
+	params := make([]string, 0, len(st.altered))
+	for p := range st.altered {
+		params = append(params, string(p))
+	}
+	sort.Strings(params) // For predictability
+
+	for _, p := range params {
+		param := SessionParam(p)
+		literal, err := sessionLiteral(param, st.altered[param])
+		if err != nil {
+			return err
+		}
+		_, err = c.execute(fmt.Sprintf("ALTER SESSION SET %s = %s", param, literal), &ExecConf{})
+		if err != nil {
+			return err
+		}
+	}
+	c.altered = st.altered
+
+	tz := st.attrs.Timezone
+	if _, done := st.altered[SessionTimeZone]; !done && tz != "" && tz != c.trackedAttributes().Timezone {
+		sql := fmt.Sprintf("ALTER SESSION SET TIME_ZONE = '%s'", QuoteStr(tz))
+		_, err := c.execute(sql, &ExecConf{})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package exasol
+
+import (
+	"context"
+	"time"
+)
+
+func (s *testSuite) TestReplaySession() {
+	s.execute("CREATE TABLE foo ( id INT )")
+	conf := s.connConf()
+	conf.ReplaySession = true
+	conf.ReplayPrepStmts = true
+	conf.CachePrepStmts = true
+	c, err := Connect(conf)
+	s.Require().NoError(err)
+	defer c.Disconnect()
+
+	_, err = c.Execute("OPEN SCHEMA " + s.qschema)
+	s.NoError(err)
+	s.NoError(c.DisableAutoCommit())
+	s.NoError(c.SetQueryTimeout(33 * time.Second))
+	s.NoError(c.AlterSession(SessionNLSDateFormat, "DD.MM.YYYY"))
+	_, err = c.Execute("INSERT INTO foo VALUES (?)", WithBinds([]interface{}{1}))
+	s.NoError(err)
+	s.NoError(c.Commit())
+	oldSession := c.SessionID
+
+	s.NoError(c.Reconnect())
+	s.NotEqual(oldSession, c.SessionID)
+
+	attrs, err := c.RefreshAttributes(context.Background())
+	s.NoError(err)
+	s.Equal("TEST", attrs.CurrentSchema)
+	s.False(attrs.Autocommit)
+	s.Equal(uint32(33), attrs.QueryTimeout)
+	val, _, err := c.SessionValue(SessionNLSDateFormat)
+	s.NoError(err)
+	s.Equal("DD.MM.YYYY", val)
+	s.Equal(1, c.Stats["StmtCacheLen"], "Re-prepared")
+
+	// Without replaying the session starts afresh
+	c.Conf.ReplaySession = false
+	c.Conf.ReplayPrepStmts = false
+	s.NoError(c.Reconnect())
+	attrs, err = c.RefreshAttributes(context.Background())
+	s.NoError(err)
+	s.True(attrs.Autocommit)
+	s.Equal(0, c.Stats["StmtCacheLen"])
+}
@@ -0,0 +1,121 @@
+/*
+	FetchChan closes the result set once it has been read. To make several
+	passes over a result without re-running the query, open it with
+	OpenResultSet instead. It stays open (server-side) until Closed and
+	can be fetched from any start position any number of times:
+
+	    rs, err := conn.OpenResultSet("SELECT * FROM t ORDER BY id")
+	    defer rs.Close()
+	    rows, err := rs.FetchChan(0)
+	    for row := range rows { ... }
+	    rows, err = rs.FetchChan(0) // Second pass
+	    for row := range rows { ... }
+	    rows, err = rs.FetchChan(rs.Info.NumRows - 10) // Just the last 10
+
+	Small result sets are returned in full with the query's response and
+	have no server-side handle. They're held in memory instead.
+
+
+	AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import "sync/atomic"
+
+type ResultSet struct {
+	Info ResultInfo
+
+	conn   *Conn
+	sql    string
+	rs     *resultSet
+	ec     *ExecConf
+	closed int32
+}
+
+// Takes the same optional args as FetchChan
+func (c *Conn) OpenResultSet(sql string, args ...interface{}) (*ResultSet, error) {
+	ec, err := c.fetchArgsConf(args)
+	if err != nil {
+		return nil, err
+	}
+	rs, err := c.executeQuery(sql, ec)
+	if err != nil {
+		return nil, err
+	}
+	return &ResultSet{
+		Info: ResultInfo{
+			NumRows: rs.NumRows,
+			Columns: rs.Columns,
+			Handle:  rs.ResultSetHandle,
+		},
+		conn: c,
+		sql:  sql,
+		rs:   rs,
+		ec:   ec,
+	}, nil
+}
+
+// Streams the rows from startPosition (zero-based) to the end
+func (r *ResultSet) FetchChan(startPosition uint64) (<-chan FetchResult, error) {
+	c := r.conn
+	if atomic.LoadInt32(&r.closed) == 1 {
+		return nil, ErrResultSetClosed
+	}
+	if startPosition > r.rs.NumRows {
+		return nil, c.errorf("Start position %d is beyond the %d rows", startPosition, r.rs.NumRows)
+	}
+
+	err := c.startFetch()
+	if err != nil {
+		return nil, err
+	}
+	ch := make(chan FetchResult, 1000)
+	go func() {
+		defer c.fetches.Done()
+		defer close(ch)
+		ctx, cancel := c.callContext(r.ec.Context)
+		defer cancel()
+		pool := c.rowPool(r.ec)
+		rs := r.rs
+
+		var err error
+		if startPosition == rs.NumRows {
+			// Nothing left
+		} else if rs.ResultSetHandle <= 0 {
+			data := make([][]interface{}, len(rs.Data))
+			for i, col := range rs.Data {
+				data[i] = col[startPosition:]
+			}
+			err = transposeToChan(c.fetchCtx, ch, rs.Columns, data, c.Conf.AbandonedFetchTimeout, pool)
+		} else {
+			err = c.fetchBlocksFrom(ctx, rs, startPosition, true, func(data [][]interface{}) error {
+				return transposeToChan(c.fetchCtx, ch, rs.Columns, data, c.Conf.AbandonedFetchTimeout, pool)
+			})
+		}
+		if err != nil {
+			c.sendFetchError(ch, r.sql, err)
+		}
+	}()
+	return ch, nil
+}
+
+// Closes the server-side result set (if any). Closing twice is harmless.
+func (r *ResultSet) Close() error {
+	if !atomic.CompareAndSwapInt32(&r.closed, 0, 1) || r.rs.ResultSetHandle <= 0 {
+		return nil
+	}
+	err := r.conn.closeResultSetHandles(r.rs.ResultSetHandle)
+	if err != nil {
+		return r.conn.errorf("Unable to close result set: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package exasol
+
+func (s *testSuite) TestResultSetRefetch() {
+	exa := s.exaConn
+	s.execute("CREATE TABLE foo ( id INT )")
+	s.execute("INSERT INTO foo SELECT level FROM dual CONNECT BY level <= 10000")
+
+	ids := func(ch <-chan FetchResult) (got []float64) {
+		for row := range ch {
+			s.Require().NoError(row.Error)
+			got = append(got, row.Data[0].(float64))
+		}
+		return got
+	}
+
+	rs, err := exa.OpenResultSet("SELECT id FROM foo ORDER BY id")
+	s.Require().NoError(err)
+	s.Equal(uint64(10000), rs.Info.NumRows)
+	s.True(rs.Info.Handle > 0)
+
+	for pass := 1; pass <= 2; pass++ {
+		ch, err := rs.FetchChan(0)
+		s.Require().NoError(err)
+		got := ids(ch)
+		s.Len(got, 10000, "Pass %d", pass)
+		s.Equal(float64(1), got[0])
+	}
+	ch, err := rs.FetchChan(9995)
+	s.Require().NoError(err)
+	s.Equal([]float64{9996, 9997, 9998, 9999, 10000}, ids(ch))
+	s.Len(exa.OpenHandles(), 1, "Still open")
+
+	exa.Conf.SuppressError = true
+	_, err = rs.FetchChan(10001)
+	s.Error(err)
+	s.NoError(rs.Close())
+	s.NoError(rs.Close())
+	s.Empty(exa.OpenHandles())
+	_, err = rs.FetchChan(0)
+	s.Equal(ErrResultSetClosed, err)
+	exa.Conf.SuppressError = false
+
+	rs, err = exa.OpenResultSet("SELECT id FROM foo WHERE id <= ? ORDER BY id", []interface{}{3})
+	s.Require().NoError(err)
+	defer rs.Close()
+	s.Equal(0, rs.Info.Handle, "Held in memory")
+	ch, err = rs.FetchChan(1)
+	s.Require().NoError(err)
+	s.Equal([]float64{2, 3}, ids(ch))
+	ch, err = rs.FetchChan(0)
+	s.Require().NoError(err)
+	s.Equal([]float64{1, 2, 3}, ids(ch))
+}
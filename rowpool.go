@@ -0,0 +1,113 @@
+/*
+	Opt-in recycling of FetchChan row slices (see WithPooledRows). For
+	billion row scans allocating a new []interface{} per row dominates
+	so instead the rows come from a pool and consumers hand them back:
+
+	    ch, err := conn.FetchChan(sql, exasol.WithPooledRows())
+	    for row := range ch {
+	        process(row.Data)
+	        row.Release()
+	    }
+
+	Neither row.Data nor the values in it may be used after Release
+	(copy them if need be). Each row must be released at most once.
+
+	Misuse can't be detected cheaply so with ConnConf.DebugRowPool set
+	rows are never actually recycled. Instead released rows have their
+	values replaced with ReleasedValue (which the FetchResult accessors
+	report as an error) and releasing a row twice panics.
+
+
+	AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// Replaces the values of released rows when ConnConf.DebugRowPool is set
+var ReleasedValue = releasedValue{}
+
+type releasedValue struct{}
+
+func (releasedValue) String() string { return "<released>" }
+
+var errRowReleased = errors.New("Row used after Release")
+
+type rowPool struct {
+	debug bool
+}
+
+type pooledRow struct {
+	data []interface{}
+	gen  uint32 // Incremented on each release to detect stale releases
+	pool *rowPool
+}
+
+var pooledRows = sync.Pool{
+	New: func() interface{} { return &pooledRow{} },
+}
+
+// Returns the row to the pool if it came from one. Otherwise it's a no-op.
+func (r FetchResult) Release() {
+	pr := r.pooled
+	if pr == nil {
+		return
+	}
+	if !atomic.CompareAndSwapUint32(&pr.gen, r.gen, r.gen+1) {
+		if pr.pool.debug {
+			panic("exasol: FetchResult released twice")
+		}
+		return
+	}
+	if pr.pool.debug {
+		for i := range pr.data {
+			pr.data[i] = ReleasedValue
+		}
+		return
+	}
+	for i := range pr.data {
+		pr.data[i] = nil // Don't pin the values
+	}
+	pooledRows.Put(pr)
+}
+
+/*--- Private Routines ---*/
+
+// Safe to call on a nil pool in which case the row is simply allocated
+func (p *rowPool) row(cols []Column, n int) FetchResult {
+	if p == nil {
+		return FetchResult{Data: make([]interface{}, n), Columns: cols}
+	}
+	pr := pooledRows.Get().(*pooledRow)
+	if cap(pr.data) < n {
+		pr.data = make([]interface{}, n)
+	}
+	pr.data = pr.data[:n]
+	pr.pool = p
+	return FetchResult{
+		Data:    pr.data,
+		Columns: cols,
+		pooled:  pr,
+		gen:     atomic.LoadUint32(&pr.gen),
+	}
+}
+
+func (c *Conn) rowPool(ec *ExecConf) *rowPool {
+	if !ec.PooledRows {
+		return nil
+	}
+	return &rowPool{debug: c.Conf.DebugRowPool}
+}
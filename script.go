@@ -0,0 +1,240 @@
+/*
+	Splits SQL scripts (e.g. .sql files) into their statements so they
+	can be executed one by one, e.g.
+
+	    err := conn.ExecuteScript(string(sqlFile))
+	    var scriptErr *exasol.ScriptError
+	    if errors.As(err, &scriptErr) {
+	        fmt.Printf("Failed at line %d, column %d\n", scriptErr.Line, scriptErr.Column)
+	    }
+
+	Statements are separated by semicolons outside of string literals,
+	quoted identifiers, comments and dollar-quoted ($$ ... $$ or
+	$tag$ ... $tag$) blocks. As in EXAplus a line containing just a "/"
+	also ends a statement. It's required after the body of a CREATE
+	SCRIPT or CREATE FUNCTION (unless the body is dollar-quoted) because
+	the body is passed through as-is, semicolons, Lua/Python comments
+	and all.
+
+
+	AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type ScriptStatement struct {
+	SQL string
+	// Where the statement starts in the script (1-based)
+	Line   int
+	Column int
+}
+
+// Returned by ExecuteScript for the first statement that fails
+type ScriptError struct {
+	Index     int // Of the statement in the script (0-based)
+	Statement ScriptStatement
+	// The position of the error in the script if the server reported
+	// where in the statement it is, otherwise where the statement starts
+	Line   int
+	Column int
+	Err    error
+}
+
+func (e *ScriptError) Error() string {
+	return fmt.Sprintf("Statement %d [line %d, column %d]: %s",
+		e.Index+1, e.Line, e.Column, e.Err)
+}
+
+func (e *ScriptError) Unwrap() error { return e.Err }
+
+// Leading comments and empty statements are dropped
+func SplitStatements(script string) []ScriptStatement {
+	var stmts []ScriptStatement
+	for i := skipSpaceAndComments(script, 0); i < len(script); {
+		end, next := statementEnd(script, i)
+		if sql := strings.TrimRightFunc(script[i:end], unicode.IsSpace); sql != "" {
+			line, col := scriptPosition(script, i)
+			stmts = append(stmts, ScriptStatement{SQL: sql, Line: line, Column: col})
+		}
+		i = skipSpaceAndComments(script, next)
+	}
+	return stmts
+}
+
+// Executes the statements in the script in order stopping at the first
+// failure, which is returned as a *ScriptError. Any args (e.g. WithSchema
+// or WithContext) apply to every statement.
+func (c *Conn) ExecuteScript(script string, args ...interface{}) error {
+	for i, stmt := range SplitStatements(script) {
+		_, err := c.Execute(stmt.SQL, args...)
+		if err != nil {
+			return newScriptError(i, stmt, err)
+		}
+	}
+	return nil
+}
+
+/*--- Private Routines ---*/
+
+var scriptHeaderRE = regexp.MustCompile(
+	`(?is)^CREATE\s+(?:OR\s+REPLACE\s+)?(?:FUNCTION|(\w+\s+)?(?:(?:SCALAR|SET|ADAPTER)\s+)?SCRIPT)\s`,
+)
+
+// Words which can precede SCRIPT when it's the name of something else
+// e.g. CREATE TABLE script (...)
+var notScriptLanguage = map[string]bool{
+	"TABLE": true, "VIEW": true, "FORCE": true, "SCHEMA": true,
+	"USER": true, "ROLE": true, "CONNECTION": true, "VIRTUAL": true,
+}
+
+func isScriptHeader(sql string) bool {
+	m := scriptHeaderRE.FindStringSubmatch(sql)
+	return m != nil && !notScriptLanguage[strings.ToUpper(strings.TrimSpace(m[1]))]
+}
+
+// Returns where the statement starting at start ends (excluding
+// the terminator) and where the next one might start.
+func statementEnd(script string, start int) (end, next int) {
+	body := isScriptHeader(script[start:])
+	for i := start; i < len(script); i++ {
+		if i > start && script[i-1] == '\n' {
+			eol := strings.IndexByte(script[i:], '\n')
+			if eol < 0 {
+				eol = len(script) - i
+			}
+			if strings.TrimSpace(script[i:i+eol]) == "/" {
+				return i, i + eol
+			}
+		}
+		if tag := dollarTag(script, i); tag != "" {
+			n := strings.Index(script[i+len(tag):], tag)
+			if n < 0 {
+				break // Unterminated so the rest is part of this statement
+			}
+			i += len(tag) + n + len(tag) - 1
+			body = false
+			continue
+		}
+		if body {
+			continue // Only a "/" line (or a dollar-quoted body) ends it
+		}
+
+		var n, skip int // Where the quote/comment ends relative to i
+		switch {
+		case script[i] == '\'' || script[i] == '"':
+			n, skip = strings.IndexByte(script[i+1:], script[i]), 1
+		case strings.HasPrefix(script[i:], "--"):
+			n, skip = strings.IndexByte(script[i:], '\n'), 0
+		case strings.HasPrefix(script[i:], "/*"):
+			n, skip = strings.Index(script[i+2:], "*/"), 3
+		case script[i] == ';':
+			return i, i + 1
+		default:
+			continue
+		}
+		if n < 0 {
+			break
+		}
+		i += n + skip
+	}
+	return len(script), len(script)
+}
+
+// Returns the $tag$ (which may be just $$) starting at i if any
+func dollarTag(script string, i int) string {
+	if script[i] != '$' || i > 0 && isIdentChar(script[i-1]) {
+		return ""
+	}
+	for j := i + 1; j < len(script); j++ {
+		switch {
+		case script[j] == '$':
+			return script[i : j+1]
+		case !isIdentChar(script[j]) || j == i+1 && script[j] >= '0' && script[j] <= '9':
+			return ""
+		}
+	}
+	return ""
+}
+
+func isIdentChar(b byte) bool {
+	return b == '_' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9'
+}
+
+// Also skips stray semicolons and "/" lines between statements
+func skipSpaceAndComments(script string, i int) int {
+	for i < len(script) {
+		r, size := utf8.DecodeRuneInString(script[i:])
+		switch {
+		case unicode.IsSpace(r) || r == ';':
+			i += size
+		case strings.HasPrefix(script[i:], "--"):
+			n := strings.IndexByte(script[i:], '\n')
+			if n < 0 {
+				return len(script)
+			}
+			i += n
+		case strings.HasPrefix(script[i:], "/*"):
+			n := strings.Index(script[i+2:], "*/")
+			if n < 0 {
+				return len(script)
+			}
+			i += n + 4
+		case r == '/':
+			eol := strings.IndexByte(script[i:], '\n')
+			if eol < 0 {
+				eol = len(script) - i
+			}
+			if strings.TrimSpace(script[i:i+eol]) != "/" {
+				return i
+			}
+			i += eol
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// The 1-based line and column (in characters) of the byte offset
+func scriptPosition(script string, offset int) (line, col int) {
+	lineStart := strings.LastIndexByte(script[:offset], '\n') + 1
+	line = strings.Count(script[:offset], "\n") + 1
+	col = utf8.RuneCountInString(script[lineStart:offset]) + 1
+	return line, col
+}
+
+func newScriptError(i int, stmt ScriptStatement, err error) *ScriptError {
+	se := &ScriptError{
+		Index:     i,
+		Statement: stmt,
+		Line:      stmt.Line,
+		Column:    stmt.Column,
+		Err:       err,
+	}
+	var exaErr *ExaError
+	if errors.As(err, &exaErr) && exaErr.Line > 0 {
+		se.Line = stmt.Line + exaErr.Line - 1
+		se.Column = exaErr.Column
+		if exaErr.Line == 1 {
+			se.Column += stmt.Column - 1
+		}
+	}
+	return se
+}
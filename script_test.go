@@ -0,0 +1,35 @@
+package exasol
+
+import "errors"
+
+func (s *testSuite) TestSplitStatements() {
+	script := "-- Setup; comment\n" +
+		"CREATE TABLE script (a VARCHAR(10));\n" +
+		"INSERT INTO script VALUES ('a;b'); /* c; */ SELECT 1 FROM dual;;\n" +
+		"CREATE OR REPLACE LUA SCRIPT s AS\n  -- it's; fine\n  return 1;\n/\n" +
+		"SELECT $tag$ ; $tag$ FROM dual"
+	got := SplitStatements(script)
+	s.Equal([]ScriptStatement{
+		{"CREATE TABLE script (a VARCHAR(10))", 2, 1},
+		{"INSERT INTO script VALUES ('a;b')", 3, 1},
+		{"SELECT 1 FROM dual", 3, 45},
+		{"CREATE OR REPLACE LUA SCRIPT s AS\n  -- it's; fine\n  return 1;", 4, 1},
+		{"SELECT $tag$ ; $tag$ FROM dual", 8, 1},
+	}, got)
+}
+
+func (s *testSuite) TestExecuteScript() {
+	script := "CREATE TABLE " + s.qschema + ".script_t (a VARCHAR(10));\n" +
+		"INSERT INTO " + s.qschema + ".script_t VALUES ('a;b');\n" +
+		"\n  SELECT a\n  FROM WHERE;\n" +
+		"INSERT INTO " + s.qschema + ".script_t VALUES ('never');"
+	err := s.exaConn.ExecuteScript(script)
+	var scriptErr *ScriptError
+	s.Require().True(errors.As(err, &scriptErr))
+	s.Equal(2, scriptErr.Index)
+	s.Equal(ScriptStatement{"SELECT a\n  FROM WHERE", 4, 3}, scriptErr.Statement)
+	s.Equal(5, scriptErr.Line, "Error position is relative to the script")
+	s.Contains(err.Error(), "[line 5, column")
+
+	s.Equal([][]interface{}{{"a;b"}}, s.fetch("SELECT a FROM "+s.qschema+".script_t"))
+}
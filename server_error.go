@@ -0,0 +1,145 @@
+/*
+	Errors reported by the Exasol server can be vetted by a
+	ConnConf.OnServerError callback before they are logged e.g.
+
+	    conf.OnServerError = func(e exasol.ExaError) exasol.ErrorAction {
+	        switch {
+	        case e.SQLCode == "42500": // Expected syntax errors from user input
+	            return exasol.ErrorAction{Suppress: true}
+	        case strings.Contains(e.Text, "GlobalTransactionRollback"):
+	            return exasol.ErrorAction{Rewrite: "Transaction conflict: " + e.Text}
+	        }
+	        return exasol.ErrorAction{}
+	    }
+
+
+	AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+)
+
+// An error reported by the Exasol server
+type ExaError struct {
+	Text    string
+	SQLCode string // e.g. 42000 for syntax errors
+	// The position in the statement the error relates to if reported
+	Line   int
+	Column int
+
+	handled bool
+	action  ErrorAction
+	rewrite string // From the action. Text is kept for errors.Is
+}
+
+func (e *ExaError) Error() string {
+	if e.rewrite != "" {
+		return "Server Error: " + e.rewrite
+	}
+	return "Server Error: " + e.Text
+}
+
+// Allows errors.Is to match the sentinels in errors.go
+func (e *ExaError) Is(target error) bool {
+	for _, s := range serverErrorSentinels {
+		if target != s.err {
+			continue
+		}
+		if s.generic {
+			// An error without a code is taken on its text alone
+			return (e.SQLCode == "" || e.SQLCode == s.code) && s.re.MatchString(e.Text)
+		}
+		return s.re.MatchString(e.Text) ||
+			s.code != "" && s.code == e.SQLCode
+	}
+	return false
+}
+
+// Wraps any error from executing or fetching a statement
+// so it can be traced back to the session and statement.
+type StmtError struct {
+	SessionID uint64
+	SQLDigest string // Matches the hashes in DebugState
+	SQL       string // Truncated to the first 100 characters
+	Err       error
+}
+
+func (e *StmtError) Error() string { return e.Err.Error() }
+func (e *StmtError) Unwrap() error { return e.Err }
+
+// What to do with a server error. The zero value logs it as usual.
+type ErrorAction struct {
+	// Don't log the error (it's still returned)
+	Suppress bool
+	// Log the error even where it would otherwise be suppressed
+	// e.g. by ConnConf.SuppressError or when Rows are closed early.
+	// This takes precedence over Suppress.
+	Escalate bool
+	// If set replaces the message of the error that's logged and returned.
+	// The ExaError's Text and SQLCode are still as the server reported.
+	Rewrite string
+}
+
+/*--- Private Routines ---*/
+
+var errorPosition = regexp.MustCompile(`\[line (\d+), column (\d+)\]`)
+
+func newExaError(text, sqlCode string) *ExaError {
+	e := &ExaError{Text: text, SQLCode: sqlCode}
+	if m := errorPosition.FindStringSubmatch(text); m != nil {
+		e.Line, _ = strconv.Atoi(m[1])
+		e.Column, _ = strconv.Atoi(m[2])
+	}
+	return e
+}
+
+func (c *Conn) stmtError(sql string, err error) error {
+	var stmtErr *StmtError
+	if err == nil || errors.As(err, &stmtErr) {
+		return err
+	}
+	snippet := []rune(sql)
+	if len(snippet) > 100 {
+		snippet = snippet[:100]
+	}
+	return &StmtError{
+		SessionID: c.SessionID,
+		SQLDigest: hashSQL(sql),
+		SQL:       string(snippet),
+		Err:       err,
+	}
+}
+
+// The callback is only consulted once per server error
+// even though the error is typically wrapped a few times.
+func (c *Conn) serverErrorAction(args []interface{}) ErrorAction {
+	var exaErr *ExaError
+	for _, arg := range args {
+		if err, ok := arg.(error); ok && errors.As(err, &exaErr) {
+			break
+		}
+		exaErr = nil
+	}
+	if exaErr == nil || c.Conf.OnServerError == nil {
+		return ErrorAction{}
+	}
+	if !exaErr.handled {
+		exaErr.handled = true
+		exaErr.action = c.Conf.OnServerError(*exaErr)
+		exaErr.rewrite = exaErr.action.Rewrite
+	}
+	return exaErr.action
+}
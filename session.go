@@ -0,0 +1,160 @@
+/*
+	A wrapper for ALTER SESSION that quotes the value appropriately and
+	then reads the parameter back from EXA_PARAMETERS to verify that the
+	server applied it, e.g.
+
+	    err := conn.AlterSession(exasol.SessionNLSDateFormat, "YYYY-MM-DD")
+	    err = conn.AlterSession(exasol.SessionQueryTimeout, 60)
+
+
+	AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type SessionParam string
+
+const (
+	SessionQueryTimeout                SessionParam = "QUERY_TIMEOUT"
+	SessionNLSDateFormat               SessionParam = "NLS_DATE_FORMAT"
+	SessionNLSTimestampFormat          SessionParam = "NLS_TIMESTAMP_FORMAT"
+	SessionNLSDateLanguage             SessionParam = "NLS_DATE_LANGUAGE"
+	SessionNLSNumericCharacters        SessionParam = "NLS_NUMERIC_CHARACTERS"
+	SessionNLSFirstDayOfWeek           SessionParam = "NLS_FIRST_DAY_OF_WEEK"
+	SessionTimeZone                    SessionParam = "TIME_ZONE"
+	SessionTimeZoneBehavior            SessionParam = "TIME_ZONE_BEHAVIOR"
+	SessionProfile                     SessionParam = "PROFILE"
+	SessionQueryCache                  SessionParam = "QUERY_CACHE"
+	SessionSQLPreprocessorScript       SessionParam = "SQL_PREPROCESSOR_SCRIPT"
+	SessionDefaultLikeEscapeChar       SessionParam = "DEFAULT_LIKE_ESCAPE_CHARACTER"
+	SessionTempDBRAMLimit              SessionParam = "SESSION_TEMP_DB_RAM_LIMIT"
+	SessionSnapshotMode                SessionParam = "SNAPSHOT_MODE"
+	SessionDefaultConsumerGroup        SessionParam = "DEFAULT_CONSUMER_GROUP"
+	SessionScriptOutputAddress         SessionParam = "SCRIPT_OUTPUT_ADDRESS"
+	SessionIdleTimeout                 SessionParam = "IDLE_TIMEOUT"
+	SessionHashtypeFormat              SessionParam = "HASHTYPE_FORMAT"
+	SessionTimestampArithmeticBehavior SessionParam = "TIMESTAMP_ARITHMETIC_BEHAVIOR"
+)
+
+// The known parameters and whether their values are
+// numbers rather than string literals
+var sessionParams = map[SessionParam]bool{
+	SessionQueryTimeout:                true,
+	SessionNLSDateFormat:               false,
+	SessionNLSTimestampFormat:          false,
+	SessionNLSDateLanguage:             false,
+	SessionNLSNumericCharacters:        false,
+	SessionNLSFirstDayOfWeek:           true,
+	SessionTimeZone:                    false,
+	SessionTimeZoneBehavior:            false,
+	SessionProfile:                     false,
+	SessionQueryCache:                  false,
+	SessionSQLPreprocessorScript:       false,
+	SessionDefaultLikeEscapeChar:       false,
+	SessionTempDBRAMLimit:              false,
+	SessionSnapshotMode:                false,
+	SessionDefaultConsumerGroup:        false,
+	SessionScriptOutputAddress:         false,
+	SessionIdleTimeout:                 true,
+	SessionHashtypeFormat:              false,
+	SessionTimestampArithmeticBehavior: false,
+}
+
+// The value is a string or, for numeric parameters, an integer
+func (c *Conn) AlterSession(param SessionParam, value interface{}) error {
+	literal, err := sessionLiteral(param, value)
+	if err != nil {
+		return c.errorf("Unable to alter session: %w", err)
+	}
+	_, err = c.Execute(fmt.Sprintf("ALTER SESSION SET %s = %s", param, literal))
+	if err != nil {
+		return c.errorf("Unable to alter session %s: %w", param, err)
+	}
+	if c.altered == nil {
+		c.altered = map[SessionParam]interface{}{}
+	}
+	c.altered[param] = value // For replaying after a Reconnect
+	if param == SessionQueryTimeout {
+		// Also an attribute so keep QueryTimeout in sync
+		if secs, err := strconv.ParseUint(literal, 10, 32); err == nil {
+			c.updateAttributes(func(a *Attributes) { a.QueryTimeout = uint32(secs) })
+		}
+	}
+
+	got, found, err := c.SessionValue(param)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return nil // Not every parameter is listed so it can't be verified
+	}
+	want := strings.Trim(literal, "'")
+	want = strings.ReplaceAll(want, "''", "'")
+	if !strings.EqualFold(strings.TrimSpace(got), strings.TrimSpace(want)) {
+		return c.errorf("Session %s is %q after setting it to %q", param, got, want)
+	}
+	return nil
+}
+
+// Returns the session's current value of the parameter and
+// whether it's listed in EXA_PARAMETERS
+func (c *Conn) SessionValue(param SessionParam) (string, bool, error) {
+	res, err := c.FetchSlice(
+		"SELECT session_value FROM exa_parameters WHERE parameter_name = ?",
+		[]interface{}{string(param)},
+	)
+	if err != nil {
+		return "", false, c.errorf("Unable to read session %s: %w", param, err)
+	}
+	if len(res) == 0 {
+		return "", false, nil
+	}
+	s, err := FetchResult{Data: res[0]}.String(0)
+	if err != nil {
+		return "", false, c.errorf("Unable to read session %s: %w", param, err)
+	}
+	return s, true, nil
+}
+
+/*--- Private Routines ---*/
+
+func sessionLiteral(param SessionParam, value interface{}) (string, error) {
+	numeric, known := sessionParams[param]
+	if !known {
+		return "", fmt.Errorf("Unknown session parameter: %s", param)
+	}
+	if !numeric {
+		s, ok := value.(string)
+		if !ok {
+			return "", fmt.Errorf("Session %s must be a string not %T", param, value)
+		}
+		return "'" + QuoteStr(s) + "'", nil
+	}
+	switch n := value.(type) {
+	case int:
+		return strconv.Itoa(n), nil
+	case int64:
+		return strconv.FormatInt(n, 10), nil
+	case uint32:
+		return strconv.FormatUint(uint64(n), 10), nil
+	case string:
+		if _, err := strconv.ParseUint(n, 10, 64); err == nil {
+			return n, nil
+		}
+	}
+	return "", fmt.Errorf("Session %s must be a whole number not %v", param, value)
+}
@@ -0,0 +1,31 @@
+package exasol
+
+import (
+	"time"
+)
+
+func (s *testSuite) TestAlterSession() {
+	exa := s.exaConn
+	exa.Conf.SuppressError = true
+
+	s.NoError(exa.AlterSession(SessionNLSDateFormat, "DD.MM.YYYY"))
+	got, err := exa.FetchSlice("SELECT TO_CHAR(DATE '2020-01-02') FROM dual")
+	s.Require().NoError(err)
+	s.Equal("02.01.2020", got[0][0])
+
+	s.NoError(exa.AlterSession(SessionQueryTimeout, 42))
+	val, found, err := exa.SessionValue(SessionQueryTimeout)
+	s.NoError(err)
+	s.True(found)
+	s.Equal("42", val)
+	s.Equal(42*time.Second, exa.QueryTimeout(), "The attribute is kept in sync")
+	s.Equal(42*time.Second, exa.EffectiveConfig().QueryTimeout)
+	s.NoError(exa.AlterSession(SessionQueryTimeout, 0))
+	s.Equal(time.Duration(0), exa.QueryTimeout())
+
+	// Values are quoted
+	s.Error(exa.AlterSession(SessionNLSDateFormat, "YYYY' = 1"))
+
+	s.Error(exa.AlterSession(SessionQueryTimeout, "forever"))
+	s.Error(exa.AlterSession(SessionParam("NO_SUCH_PARAM"), "x"))
+}
@@ -0,0 +1,173 @@
+/*
+    AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// Also matches ErrConnClosed
+var ErrShutdown = newSentinelError("Connection is shut down", ErrConnClosed)
+
+// Gracefully closes the connection e.g. during a service rollout.
+// New statements are refused straight away and then in-flight FetchChan
+// goroutines are given until the context is done to finish.
+// Any still running at that point are cancelled (their consumers receive
+// a FetchResult with the context's error) before disconnecting.
+// Returns the context's error if the fetches had to be cancelled.
+func (c *Conn) Shutdown(ctx context.Context) error {
+	if !c.startClosing() {
+		return ErrShutdown
+	}
+
+	done := make(chan struct{})
+	go func() {
+		c.fetches.Wait()
+		close(done)
+	}()
+
+	var err error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+		c.log.Warning("Cancelling in-flight fetches: ", err)
+		c.cancelFetches()
+		<-done
+	}
+
+	c.Disconnect()
+	return err
+}
+
+/*--- Private Routines ---*/
+
+// The longest we'll wait for the server when cleaning up after
+// the connection's context is done.
+const contextCleanupTimeout = 5 * time.Second
+
+// Closes the connection when its context is done. Its websocket operations
+// are aborted by the context itself so this waits for any fetches to stop
+// and then closes the result sets and session with a fresh context.
+func (c *Conn) watchContext() {
+	done := c.ctx.Done()
+	if done == nil {
+		return
+	}
+	c.disconnected = make(chan struct{})
+	go func() {
+		select {
+		case <-c.disconnected:
+			return
+		case <-done:
+		}
+		if !atomic.CompareAndSwapInt32(&c.disconnecting, 0, 1) {
+			return // Racing with Disconnect
+		}
+		c.startClosing() // Refuse new statements
+		c.log.Info("Context done. Closing SessionID:", c.SessionID)
+		c.cancelFetches()
+		c.fetches.Wait()
+
+		ctx, cancel := context.WithTimeout(context.Background(), contextCleanupTimeout)
+		defer cancel()
+		var handles []int
+		for _, h := range c.OpenHandles() {
+			if h.Type == ResultSetHandle {
+				handles = append(handles, h.Handle)
+			}
+		}
+		if len(handles) > 0 {
+			err := c.sendContext(ctx, &closeResultSet{
+				Command:          "closeResultSet",
+				ResultSetHandles: handles,
+			}, &response{})
+			if err != nil {
+				c.log.Warning("Unable to close result sets: ", err)
+			}
+		}
+		// Prepared statements are discarded with the session
+		err := c.sendContext(ctx, &request{Command: "disconnect"}, &response{})
+		if err != nil {
+			c.log.Warning("Unable to disconnect from Exasol: ", err)
+		}
+		c.closeWebsocket()
+	}()
+}
+
+func (c *Conn) closeWebsocket() {
+	atomic.StoreInt32(&c.closed, 1)
+	if c.disconnected != nil {
+		close(c.disconnected)
+	}
+	c.closeWatchConn()
+	// Closing aborts any round trip in progress. The handler itself is
+	// left in place as the closed flag stops it from being used again.
+	err := c.wsh.Close()
+	if err != nil {
+		c.log.Warning("Unable to close websocket: ", err)
+	}
+}
+
+// Sets the closing flag. Returns false if it was already set.
+// It's set under fetchMux so that no fetch can start once
+// Shutdown is waiting for the in-flight ones.
+func (c *Conn) startClosing() bool {
+	c.fetchMux.Lock()
+	defer c.fetchMux.Unlock()
+	return atomic.CompareAndSwapInt32(&c.closing, 0, 1)
+}
+
+// Registers a fetch goroutine with c.fetches unless the connection
+// is shutting down. The goroutine must call c.fetches.Done.
+func (c *Conn) startFetch() error {
+	c.fetchMux.Lock()
+	defer c.fetchMux.Unlock()
+	if err := c.checkOpen(); err != nil {
+		return err
+	}
+	c.fetches.Add(1)
+	return nil
+}
+
+func (c *Conn) checkOpen() error {
+	if atomic.LoadInt32(&c.closing) != 0 {
+		return ErrShutdown
+	}
+	if atomic.LoadInt32(&c.closed) != 0 {
+		return ErrConnClosed
+	}
+	return nil
+}
+
+// Sends an error to the result channel. If the fetch was cancelled
+// the consumer may have gone away so it doesn't block.
+func (c *Conn) sendFetchError(ch chan<- FetchResult, sql string, err error) {
+	err = c.stmtError(sql, err)
+	if errors.Is(err, errFetchAbandoned) {
+		c.log.Warningf(
+			"Closing result set as FetchChan consumer hasn't read anything for %s. SQL: %s",
+			c.Conf.AbandonedFetchTimeout, sql,
+		)
+	} else if c.fetchCtx.Err() == nil {
+		ch <- FetchResult{Error: err}
+		return
+	}
+	select {
+	case ch <- FetchResult{Error: err}:
+	default:
+	}
+}
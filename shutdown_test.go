@@ -0,0 +1,124 @@
+package exasol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+func (s *testSuite) TestShutdown() {
+	conf := s.connConf()
+	conf.SuppressError = true
+
+	// In-flight fetches are allowed to finish
+	c, err := Connect(conf)
+	s.Require().NoError(err)
+	ch, err := c.FetchChan("SELECT level FROM dual CONNECT BY level <= 5000")
+	s.Require().NoError(err)
+	n := make(chan int)
+	go func() {
+		i := 0
+		for range ch {
+			i++
+		}
+		n <- i
+	}()
+	s.NoError(c.Shutdown(context.Background()))
+	s.Equal(5000, <-n)
+
+	_, err = c.Execute("SELECT 1")
+	s.Equal(ErrShutdown, err, "New statements are refused")
+	s.Equal(ErrShutdown, c.Shutdown(context.Background()))
+
+	// Abandoned fetches are cancelled
+	c, err = Connect(conf)
+	s.Require().NoError(err)
+	ch, err = c.FetchChan("SELECT level FROM dual CONNECT BY level <= 5000")
+	s.Require().NoError(err)
+	<-ch
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	s.Equal(context.DeadlineExceeded, c.Shutdown(ctx))
+}
+
+func (s *testSuite) TestConnectContextLifetime() {
+	conf := s.connConf()
+	conf.SuppressError = true
+	ctx, cancel := context.WithCancel(context.Background())
+	c, err := ConnectContext(conf, ctx)
+	s.Require().NoError(err)
+	sessionID := c.SessionID
+
+	// Leave a fetch in-flight with its consumer stalled
+	ch, err := c.FetchChan("SELECT level FROM dual CONNECT BY level <= 100000")
+	s.Require().NoError(err)
+	<-ch
+
+	cancel()
+	for range ch {
+		// The fetch is cancelled so the channel gets closed
+	}
+	deadline := time.Now().Add(10 * time.Second)
+	for atomic.LoadInt32(&c.closed) == 0 && time.Now().Before(deadline) {
+		time.Sleep(50 * time.Millisecond)
+	}
+	s.Require().Equal(int32(1), atomic.LoadInt32(&c.closed), "Disconnected")
+
+	got := s.fetch(fmt.Sprintf("SELECT COUNT(*) FROM exa_dba_sessions WHERE session_id = %d", sessionID))
+	s.Equal(float64(0), got[0][0], "Session was closed")
+
+	_, err = c.Execute("SELECT 1")
+	s.True(errors.Is(err, ErrConnClosed))
+	c.Disconnect() // Harmless
+}
+
+func (s *testSuite) TestDisconnectWhileExecuting() {
+	conf := s.connConf()
+	conf.SuppressError = true
+	c, err := Connect(conf)
+	s.Require().NoError(err)
+
+	errs := make(chan error, 4)
+	for i := 0; i < 4; i++ {
+		go func() {
+			var err error
+			for err == nil {
+				_, err = c.Execute("SELECT 1")
+			}
+			errs <- err
+		}()
+	}
+	time.Sleep(100 * time.Millisecond)
+	c.Disconnect()
+	for i := 0; i < 4; i++ {
+		s.True(errors.Is(<-errs, ErrConnClosed), "Statements fail rather than panic")
+	}
+}
+
+func (s *testSuite) TestShutdownWhileFetching() {
+	conf := s.connConf()
+	conf.SuppressError = true
+	c, err := Connect(conf)
+	s.Require().NoError(err)
+
+	errs := make(chan error, 4)
+	for i := 0; i < 4; i++ {
+		go func() {
+			var err error
+			for err == nil {
+				var ch <-chan FetchResult
+				ch, err = c.FetchChan("SELECT level FROM dual CONNECT BY level <= 10")
+				for range ch {
+				}
+			}
+			errs <- err
+		}()
+	}
+	time.Sleep(100 * time.Millisecond)
+	s.NoError(c.Shutdown(context.Background()))
+	for i := 0; i < 4; i++ {
+		s.True(errors.Is(<-errs, ErrConnClosed), "New fetches are refused")
+	}
+}
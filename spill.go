@@ -0,0 +1,192 @@
+/*
+	Optional spilling of fetched result blocks to disk.
+
+	Normally FetchChan only fetches the next block of rows from Exasol once
+	the consumer has drained the previous one, which keeps the result set
+	(and the connection) busy for as long as the consumer takes.
+	With ConnConf.SpillBudget set, blocks are fetched as fast as Exasol
+	can deliver them. They're kept in memory until the unconsumed rows
+	exceed the budget after which they are written to temporary files
+	and replayed to the consumer in order.
+
+
+	AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"sync"
+)
+
+type spillBlock struct {
+	data [][]interface{} // Set if the block is held in memory
+	size int64
+	file string // Set if the block was spilled
+}
+
+// A FIFO queue of column-major result blocks
+type spillQueue struct {
+	dir      string
+	budget   int64
+	memBytes int64
+	blocks   []*spillBlock
+	done     bool
+	aborted  bool
+	err      error
+	mux      sync.Mutex
+	cond     *sync.Cond
+}
+
+var errSpillAborted = errors.New("Result consumer went away")
+
+func newSpillQueue(dir string, budget int64) *spillQueue {
+	q := &spillQueue{dir: dir, budget: budget}
+	q.cond = sync.NewCond(&q.mux)
+	return q
+}
+
+func (q *spillQueue) push(data [][]interface{}) error {
+	enc, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	b := &spillBlock{size: int64(len(enc))}
+
+	q.mux.Lock()
+	if q.aborted {
+		q.mux.Unlock()
+		return errSpillAborted
+	}
+	spill := q.memBytes+b.size > q.budget
+	if !spill {
+		b.data = data
+		q.memBytes += b.size
+	}
+	q.mux.Unlock()
+
+	if spill {
+		f, err := ioutil.TempFile(q.dir, "exasol-spill-*")
+		if err != nil {
+			return err
+		}
+		b.file = f.Name()
+		_, err = f.Write(enc)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(b.file)
+			return err
+		}
+	}
+
+	q.mux.Lock()
+	defer q.mux.Unlock()
+	if q.aborted {
+		q.removeFile(b)
+		return errSpillAborted
+	}
+	q.blocks = append(q.blocks, b)
+	q.cond.Signal()
+	return nil
+}
+
+// Called by the producer once all blocks have been pushed (or it failed)
+func (q *spillQueue) finish(err error) {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+	q.done = true
+	q.err = err
+	q.cond.Signal()
+}
+
+// Blocks until the next block is available. Returns nil, nil once the
+// queue is drained and the producer has finished successfully.
+func (q *spillQueue) pop() ([][]interface{}, error) {
+	q.mux.Lock()
+	for len(q.blocks) == 0 && !q.done {
+		q.cond.Wait()
+	}
+	if len(q.blocks) == 0 {
+		defer q.mux.Unlock()
+		return nil, q.err
+	}
+	b := q.blocks[0]
+	q.blocks[0] = nil
+	q.blocks = q.blocks[1:]
+	if b.file == "" {
+		q.memBytes -= b.size
+		q.mux.Unlock()
+		return b.data, nil
+	}
+	q.mux.Unlock()
+
+	defer os.Remove(b.file)
+	enc, err := ioutil.ReadFile(b.file)
+	if err != nil {
+		return nil, err
+	}
+	var data [][]interface{}
+	err = json.Unmarshal(enc, &data)
+	return data, err
+}
+
+// Called by the consumer if it stops early. Removes any spilled files
+// and causes the producer's next push to fail.
+func (q *spillQueue) abort() {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+	q.aborted = true
+	for _, b := range q.blocks {
+		q.removeFile(b)
+	}
+	q.blocks = nil
+}
+
+func (q *spillQueue) removeFile(b *spillBlock) {
+	if b.file != "" {
+		os.Remove(b.file)
+	}
+}
+
+func (c *Conn) spillResultsToChan(ctx context.Context, sql string, rs *resultSet, ch chan<- FetchResult, pool *rowPool) {
+	q := newSpillQueue(c.Conf.SpillDir, c.Conf.SpillBudget)
+	produced := make(chan struct{})
+	go func() {
+		defer close(produced)
+		q.finish(c.fetchBlocks(ctx, rs, q.push))
+	}()
+
+	for {
+		data, err := q.pop()
+		if err == nil && data == nil {
+			return
+		}
+		if err == nil {
+			err = transposeToChan(c.fetchCtx, ch, rs.Columns, data, c.Conf.AbandonedFetchTimeout, pool)
+		}
+		if err != nil {
+			q.abort()
+			// Wait for the producer so that it's not still using the
+			// connection after we return.
+			<-produced
+			c.sendFetchError(ch, sql, err)
+			c.log.Warning("Error send to result channel:", err)
+			return
+		}
+	}
+}
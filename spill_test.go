@@ -0,0 +1,80 @@
+package exasol
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"time"
+)
+
+func (s *testSuite) TestSpillToDisk() {
+	dir, err := ioutil.TempDir("", "exasol-spill-test")
+	s.Require().NoError(err)
+	defer os.RemoveAll(dir)
+
+	conf := s.connConf()
+	conf.FetchReqSize = 1024
+	conf.SpillBudget = 4096
+	conf.SpillDir = dir
+	c, err := Connect(conf)
+	s.Require().NoError(err)
+	defer c.Disconnect()
+
+	sql := "SELECT level, 'row' || level FROM dual CONNECT BY level <= 10000 ORDER BY 1"
+	ch, err := c.FetchChan(sql)
+	s.Require().NoError(err)
+
+	// Reading the first row and then waiting for the producer to
+	// get ahead ensures some blocks are spilled
+	first := <-ch
+	s.Equal(float64(1), first.Data[0])
+	for i := 0; i < 100; i++ {
+		files, _ := ioutil.ReadDir(dir)
+		if len(files) > 0 {
+			break
+		}
+		<-time.After(10 * time.Millisecond)
+	}
+	files, _ := ioutil.ReadDir(dir)
+	s.NotEmpty(files, "Blocks were spilled")
+
+	n := 1
+	for row := range ch {
+		s.NoError(row.Error)
+		n++
+		s.Equal(float64(n), row.Data[0])
+	}
+	s.Equal(10000, n)
+
+	files, _ = ioutil.ReadDir(dir)
+	s.Empty(files, "Spill files were removed")
+}
+
+func (s *testSuite) TestSpillShutdownAbandoned() {
+	dir, err := ioutil.TempDir("", "exasol-spill-test")
+	s.Require().NoError(err)
+	defer os.RemoveAll(dir)
+
+	conf := s.connConf()
+	conf.SuppressError = true
+	conf.FetchReqSize = 1024
+	conf.SpillBudget = 4096
+	conf.SpillDir = dir
+	c, err := Connect(conf)
+	s.Require().NoError(err)
+
+	ch, err := c.FetchChan("SELECT level FROM dual CONNECT BY level <= 10000")
+	s.Require().NoError(err)
+	<-ch // And then abandon it
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- c.Shutdown(ctx) }()
+	select {
+	case err = <-done:
+		s.Equal(context.DeadlineExceeded, err)
+	case <-time.After(10 * time.Second):
+		s.Fail("Shutdown didn't cancel the spilling fetch")
+	}
+}
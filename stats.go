@@ -0,0 +1,259 @@
+/*
+	Typed access to some of the EXA_STATISTICS system tables for building
+	operational dashboards, e.g.
+
+	    sizes, err := conn.DBSizeStats()
+	    fmt.Printf("%.1f GiB\n", sizes[len(sizes)-1].RawObjectSize)
+
+	Sizes are in GiB and durations in seconds as reported by Exasol.
+	AuditSQL requires auditing to be enabled and access to
+	EXA_DBA_AUDIT_SQL.
+
+
+	AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// A row of EXA_DB_SIZE_LAST_DAY
+type DBSizeStat struct {
+	MeasureTime          time.Time
+	RawObjectSize        float64
+	MemObjectSize        float64
+	AuxiliarySize        float64
+	StatisticsSize       float64
+	RecommendedDBRAMSize float64
+	StorageSize          float64
+	Use                  float64 // Percentage of the storage used
+	ObjectCount          int64
+}
+
+// A row of EXA_MONITOR_LAST_DAY
+type MonitorStat struct {
+	MeasureTime     time.Time
+	Load            float64
+	CPU             float64 // Percentage
+	TempDBRAM       float64 // In MiB
+	PersistentDBRAM float64 // In MiB
+	HDDRead         float64 // In MiB/s
+	HDDWrite        float64 // In MiB/s
+	Net             float64 // In MiB/s
+	Swap            float64 // In MiB/s
+}
+
+// A row of EXA_DBA_AUDIT_SQL
+type AuditSQLStat struct {
+	SessionID     uint64
+	StmtID        int64
+	CommandName   string
+	CommandClass  string
+	Duration      float64
+	StartTime     time.Time
+	StopTime      time.Time
+	CPU           float64
+	TempDBRAMPeak float64
+	HDDRead       float64
+	HDDWrite      float64
+	Net           float64
+	Success       bool
+	ErrorCode     string
+	ErrorText     string
+	ScopeSchema   string
+	RowCount      int64
+	SQLText       string
+}
+
+// The database size over the last 24 hours, oldest first
+func (c *Conn) DBSizeStats() ([]DBSizeStat, error) {
+	stats := []DBSizeStat{}
+	err := c.scanStats(`
+		SELECT measure_time, raw_object_size, mem_object_size, auxiliary_size,
+		       statistics_size, recommended_db_ram_size, storage_size, use,
+		       object_count
+		FROM exa_statistics.exa_db_size_last_day
+		ORDER BY measure_time
+	`, nil, func(s *statScanner) {
+		stats = append(stats, DBSizeStat{
+			MeasureTime:          s.time(0),
+			RawObjectSize:        s.float(1),
+			MemObjectSize:        s.float(2),
+			AuxiliarySize:        s.float(3),
+			StatisticsSize:       s.float(4),
+			RecommendedDBRAMSize: s.float(5),
+			StorageSize:          s.float(6),
+			Use:                  s.float(7),
+			ObjectCount:          s.int(8),
+		})
+	})
+	if err != nil {
+		return nil, c.errorf("Unable to get DB size stats: %w", err)
+	}
+	return stats, nil
+}
+
+// The CPU, temp DB RAM etc usage over the last 24 hours, oldest first
+func (c *Conn) MonitorStats() ([]MonitorStat, error) {
+	stats := []MonitorStat{}
+	err := c.scanStats(`
+		SELECT measure_time, load, cpu, temp_db_ram, persistent_db_ram,
+		       hdd_read, hdd_write, net, swap
+		FROM exa_statistics.exa_monitor_last_day
+		ORDER BY measure_time
+	`, nil, func(s *statScanner) {
+		stats = append(stats, MonitorStat{
+			MeasureTime:     s.time(0),
+			Load:            s.float(1),
+			CPU:             s.float(2),
+			TempDBRAM:       s.float(3),
+			PersistentDBRAM: s.float(4),
+			HDDRead:         s.float(5),
+			HDDWrite:        s.float(6),
+			Net:             s.float(7),
+			Swap:            s.float(8),
+		})
+	})
+	if err != nil {
+		return nil, c.errorf("Unable to get monitor stats: %w", err)
+	}
+	return stats, nil
+}
+
+// The statements started since the given time, oldest first.
+// At most limit are returned unless it's zero.
+func (c *Conn) AuditSQL(since time.Time, limit int) ([]AuditSQLStat, error) {
+	sql := `
+		SELECT session_id, stmt_id, command_name, command_class, duration,
+		       start_time, stop_time, cpu, temp_db_ram_peak, hdd_read,
+		       hdd_write, net, success, error_code, error_text,
+		       scope_schema, row_count, sql_text
+		FROM exa_statistics.exa_dba_audit_sql
+		WHERE start_time >= ?
+		ORDER BY start_time, session_id, stmt_id`
+	if limit > 0 {
+		sql += fmt.Sprintf(" LIMIT %d", limit)
+	}
+	binds := []interface{}{since.UTC().Format(exaTimestampFormat)}
+	stats := []AuditSQLStat{}
+	err := c.scanStats(sql, binds, func(s *statScanner) {
+		stats = append(stats, AuditSQLStat{
+			SessionID:     s.uint(0),
+			StmtID:        s.int(1),
+			CommandName:   s.str(2),
+			CommandClass:  s.str(3),
+			Duration:      s.float(4),
+			StartTime:     s.time(5),
+			StopTime:      s.time(6),
+			CPU:           s.float(7),
+			TempDBRAMPeak: s.float(8),
+			HDDRead:       s.float(9),
+			HDDWrite:      s.float(10),
+			Net:           s.float(11),
+			Success:       s.bool(12),
+			ErrorCode:     s.str(13),
+			ErrorText:     s.str(14),
+			ScopeSchema:   s.str(15),
+			RowCount:      s.int(16),
+			SQLText:       s.str(17),
+		})
+	})
+	if err != nil {
+		return nil, c.errorf("Unable to get audited SQL: %w", err)
+	}
+	return stats, nil
+}
+
+/*--- Private Routines ---*/
+
+// Wraps the FetchResult accessors keeping the first error
+// so that each column needn't be checked individually
+type statScanner struct {
+	r   FetchResult
+	err error
+}
+
+func (s *statScanner) keep(err error) {
+	if s.err == nil && err != nil {
+		s.err = err
+	}
+}
+
+func (s *statScanner) time(i int) time.Time {
+	t, err := s.r.Time(i)
+	s.keep(err)
+	return t
+}
+
+func (s *statScanner) float(i int) float64 {
+	f, err := s.r.Float64(i)
+	s.keep(err)
+	return f
+}
+
+func (s *statScanner) int(i int) int64 {
+	n, err := s.r.Int64(i)
+	s.keep(err)
+	return n
+}
+
+// Session IDs can exceed int64
+func (s *statScanner) uint(i int) uint64 {
+	if s.r.IsNull(i) {
+		return 0
+	}
+	str, err := s.r.String(i)
+	s.keep(err)
+	n, err := strconv.ParseUint(str, 10, 64)
+	s.keep(err)
+	return n
+}
+
+func (s *statScanner) str(i int) string {
+	str, err := s.r.String(i)
+	s.keep(err)
+	return str
+}
+
+func (s *statScanner) bool(i int) bool {
+	b, err := s.r.Bool(i)
+	s.keep(err)
+	return b
+}
+
+func (c *Conn) scanStats(sql string, binds []interface{}, scan func(*statScanner)) error {
+	var args []interface{}
+	if binds != nil {
+		args = append(args, binds)
+	}
+	ch, err := c.FetchChan(sql, args...)
+	if err != nil {
+		return err
+	}
+	var scanErr error
+	for row := range ch {
+		if scanErr != nil {
+			continue // Drain it
+		}
+		if row.Error != nil {
+			scanErr = row.Error
+			continue
+		}
+		s := &statScanner{r: row}
+		scan(s)
+		scanErr = s.err
+	}
+	return scanErr
+}
@@ -0,0 +1,33 @@
+package exasol
+
+import "time"
+
+func (s *testSuite) TestStatistics() {
+	exa := s.exaConn
+	// Statistics are collected periodically so a fresh
+	// database may not have any yet
+	exa.Execute("FLUSH STATISTICS")
+
+	sizes, err := exa.DBSizeStats()
+	s.Require().NoError(err)
+	for _, size := range sizes {
+		s.False(size.MeasureTime.IsZero())
+		s.True(size.RawObjectSize >= 0)
+	}
+
+	mons, err := exa.MonitorStats()
+	s.Require().NoError(err)
+	for _, mon := range mons {
+		s.False(mon.MeasureTime.IsZero())
+	}
+
+	// Auditing is typically off in test databases
+	exa.Conf.SuppressError = true
+	audits, err := exa.AuditSQL(time.Now().Add(-time.Hour), 10)
+	if err == nil {
+		s.True(len(audits) <= 10)
+		for _, a := range audits {
+			s.NotEmpty(a.CommandName)
+		}
+	}
+}
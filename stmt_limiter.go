@@ -0,0 +1,105 @@
+/*
+	Optional client-side throttling of statements so that batch jobs can
+	be kept from overwhelming a shared production cluster. Statements
+	wait for both a token from the PerSecond token bucket (allowing
+	bursts of up to a second's worth) and one of the MaxConcurrent
+	slots, which is held until the execute (or prepare and execute)
+	round trip completes. Fetching the results isn't throttled.
+
+	Share one StmtLimiter between connections to throttle them as a whole:
+
+	    limiter := &exasol.StmtLimiter{PerSecond: 20, MaxConcurrent: 4}
+	    conf.StmtLimiter = limiter
+
+	The time spent waiting is available via WaitTime for the limiter
+	as a whole and via Conn.StmtLimitWait (and the DebugState's
+	StmtLimitWaitMs stat) for each connection.
+
+
+	AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type StmtLimiter struct {
+	waited int64 // First for 64-bit alignment as it's used atomically
+
+	PerSecond     int64 // Zero for no rate limit
+	MaxConcurrent int   // Zero for no concurrency limit
+
+	once  sync.Once
+	rate  *rateLimiter
+	slots chan struct{}
+}
+
+// The total time statements have spent waiting on the limiter
+func (l *StmtLimiter) WaitTime() time.Duration {
+	return time.Duration(atomic.LoadInt64(&l.waited))
+}
+
+// The total time this connection's statements have spent waiting on
+// the Conf.StmtLimiter
+func (c *Conn) StmtLimitWait() time.Duration {
+	return time.Duration(atomic.LoadInt64(&c.stmtWait))
+}
+
+/*--- Private Routines ---*/
+
+// Waits for the statement to be allowed adding the time waited to
+// waited as well as to the limiter's total. The returned func must
+// be called once the statement is done.
+func (l *StmtLimiter) acquire(ctx context.Context, waited *int64) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+	l.once.Do(func() {
+		l.rate = newRateLimiter(l.PerSecond)
+		if l.MaxConcurrent > 0 {
+			l.slots = make(chan struct{}, l.MaxConcurrent)
+		}
+	})
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	start := time.Now()
+	defer func() {
+		d := int64(time.Since(start))
+		atomic.AddInt64(&l.waited, d)
+		atomic.AddInt64(waited, d)
+	}()
+
+	if delay := l.rate.reserve(1); delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if l.slots == nil {
+		return func() {}, nil
+	}
+	select {
+	case l.slots <- struct{}{}:
+		return func() { <-l.slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,55 @@
+package exasol
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+func (s *testSuite) TestStmtLimiter() {
+	limiter := &StmtLimiter{PerSecond: 5, MaxConcurrent: 1}
+	conf := s.connConf()
+	conf.StmtLimiter = limiter
+	var conns []*Conn
+	for i := 0; i < 2; i++ {
+		c, err := Connect(conf)
+		s.Require().NoError(err)
+		defer c.Disconnect()
+		conns = append(conns, c)
+	}
+
+	// The burst of 5 is used up, the rest wait 1/5s each
+	start := time.Now()
+	var wg sync.WaitGroup
+	for _, c := range conns {
+		wg.Add(1)
+		go func(c *Conn) {
+			defer wg.Done()
+			for i := 0; i < 5; i++ {
+				_, err := c.Execute("SELECT 1 FROM dual")
+				s.NoError(err)
+			}
+		}(c)
+	}
+	wg.Wait()
+	s.True(time.Since(start) >= 900*time.Millisecond, "Rate limited")
+	s.True(limiter.WaitTime() >= 900*time.Millisecond)
+	s.Equal(limiter.WaitTime(), conns[0].StmtLimitWait()+conns[1].StmtLimitWait())
+	s.True(conns[0].DebugState().Stats["StmtLimitWaitMs"] > 0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	// A circuit breaker trial isn't used up by waiting on the limiter
+	c := conns[0]
+	c.Conf.CircuitBreaker = &CircuitBreaker{hosts: map[string]*circuit{
+		c.host: {failures: 5, openUntil: time.Now().Add(-time.Second)},
+	}}
+	limiter.slots <- struct{}{} // Hog the only slot
+	_, err := c.Execute("SELECT 1 FROM dual", WithContext(ctx))
+	<-limiter.slots
+	s.True(errors.Is(err, context.DeadlineExceeded), "Waiting gives up with the context")
+	_, err = c.Execute("SELECT 1 FROM dual")
+	s.NoError(err, "Trial allowed")
+	s.False(c.Conf.CircuitBreaker.IsOpen(c.host))
+}
@@ -0,0 +1,118 @@
+/*
+	Helpers for the table housekeeping that ETL pipelines do over and
+	over e.g. building a new version of a table alongside the live one
+	and then swapping them:
+
+	    conn.DropIfExists("etl", "sales_new")
+	    conn.Execute("CREATE TABLE etl.sales_new LIKE etl.sales")
+	    ... load etl.sales_new ...
+	    conn.SwapTables("etl", "sales", "sales_new")
+	    conn.Truncate("etl", "sales_new")
+
+	Names are quoted with QuoteIdent, as InsertValues does, and the
+	schema may be empty to use the currently open one.
+
+
+	AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+func (c *Conn) TableExists(schema, table string) (bool, error) {
+	sql := "SELECT 1 FROM exa_all_tables WHERE table_schema = "
+	binds := []interface{}{}
+	if schema == "" {
+		sql += "CURRENT_SCHEMA"
+	} else {
+		sql += "?"
+		binds = append(binds, c.catalogName(schema))
+	}
+	sql += " AND table_name = ?"
+	binds = append(binds, c.catalogName(table))
+
+	var found int64
+	err := c.FetchRow(sql, binds, &found)
+	if errors.Is(err, ErrNoRows) {
+		return false, nil
+	} else if err != nil {
+		return false, c.errorf("Unable to check whether table %s exists: %w", table, err)
+	}
+	return true, nil
+}
+
+func (c *Conn) Truncate(schema, table string) error {
+	_, err := c.execute("TRUNCATE TABLE "+c.qualifiedName(schema, table), &ExecConf{})
+	if err != nil {
+		return c.errorf("Unable to truncate table %s: %w", table, err)
+	}
+	return nil
+}
+
+func (c *Conn) DropIfExists(schema, table string) error {
+	_, err := c.execute("DROP TABLE IF EXISTS "+c.qualifiedName(schema, table), &ExecConf{})
+	if err != nil {
+		return c.errorf("Unable to drop table %s: %w", table, err)
+	}
+	return nil
+}
+
+// Swaps the names of the two tables (in the same schema) by renaming
+// them within a single transaction so other sessions only ever see
+// both tables either before or after. As this uses Transaction it
+// requires autocommit to be on.
+func (c *Conn) SwapTables(schema, a, b string) error {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return c.errorf("Unable to swap tables %s and %s: %w", a, b, err)
+	}
+	tmp := strings.ToUpper(c.catalogName(a) + "_SWAP_" + hex.EncodeToString(suffix))
+	renames := [][2]string{{a, tmp}, {b, a}, {tmp, b}}
+
+	err := c.Transaction(func() error {
+		for _, r := range renames {
+			sql := "RENAME TABLE " + c.qualifiedName(schema, r[0]) + " TO " + c.QuoteIdent(r[1])
+			if _, err := c.execute(sql, &ExecConf{}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return c.errorf("Unable to swap tables %s and %s: %w", a, b, err)
+	}
+	return nil
+}
+
+/*--- Private Routines ---*/
+
+func (c *Conn) qualifiedName(schema, name string) string {
+	if schema == "" {
+		return c.QuoteIdent(name)
+	}
+	return c.QuoteIdent(schema) + "." + c.QuoteIdent(name)
+}
+
+// The name as stored in the catalog once quoted by QuoteIdent
+// i.e. regular identifiers are uppercased
+func (c *Conn) catalogName(name string) string {
+	quoted := c.QuoteIdent(name)
+	if quoted == name && !strings.HasPrefix(name, `"`) && !strings.HasPrefix(name, "[") {
+		return strings.ToUpper(name)
+	}
+	return quoted[1 : len(quoted)-1]
+}
@@ -0,0 +1,41 @@
+package exasol
+
+import (
+	"errors"
+)
+
+func (s *testSuite) TestTableHelpers() {
+	exists, err := s.exaConn.TableExists(s.schema, "live")
+	s.NoError(err)
+	s.False(exists)
+
+	s.execute("CREATE TABLE " + s.qschema + ".live (v VARCHAR(10))")
+	s.execute("INSERT INTO " + s.qschema + ".live VALUES ('old')")
+	s.execute("CREATE TABLE " + s.qschema + ".live_new (v VARCHAR(10))")
+	s.execute("INSERT INTO " + s.qschema + ".live_new VALUES ('new')")
+
+	exists, err = s.exaConn.TableExists(s.schema, "live")
+	s.NoError(err)
+	s.True(exists, "Regular identifiers are matched case-insensitively")
+	exists, err = s.exaConn.TableExists(s.schema, `"live"`)
+	s.NoError(err)
+	s.False(exists, "Quoted identifiers are matched exactly")
+
+	s.Require().NoError(s.exaConn.SwapTables(s.schema, "live", "live_new"))
+	s.Equal([][]interface{}{{"new"}}, s.fetch("SELECT v FROM "+s.qschema+".live"))
+	s.Equal([][]interface{}{{"old"}}, s.fetch("SELECT v FROM "+s.qschema+".live_new"))
+
+	s.Require().NoError(s.exaConn.DisableAutoCommit())
+	err = s.exaConn.SwapTables(s.schema, "live", "live_new")
+	s.True(errors.Is(err, ErrTxInProgress), "Refused with autocommit off")
+	s.Require().NoError(s.exaConn.EnableAutoCommit())
+
+	s.Require().NoError(s.exaConn.Truncate(s.schema, "live_new"))
+	s.Empty(s.fetch("SELECT v FROM " + s.qschema + ".live_new"))
+
+	s.Require().NoError(s.exaConn.DropIfExists(s.schema, "live_new"))
+	s.NoError(s.exaConn.DropIfExists(s.schema, "live_new"), "Already dropped")
+	exists, err = s.exaConn.TableExists(s.schema, "live_new")
+	s.NoError(err)
+	s.False(exists)
+}
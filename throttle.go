@@ -0,0 +1,96 @@
+/*
+	Optional throttling of bulk transfers (see WithThrottle) so that
+	massive loads can run alongside other work without saturating the
+	cluster or the network link. Both limits are token buckets allowing
+	bursts of up to a second's worth.
+
+	The bytes limit applies to the data as it goes over the wire (i.e.
+	after any compression) and is shared by all the proxies of a parallel
+	transfer. The rows limit applies to the CSV records.
+
+
+	AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"sync"
+	"time"
+)
+
+type rateLimiter struct {
+	rate   float64 // Per second
+	tokens float64
+	last   time.Time
+	mux    sync.Mutex
+}
+
+// Returns nil (which never waits) for no limit
+func newRateLimiter(perSec int64) *rateLimiter {
+	if perSec <= 0 {
+		return nil
+	}
+	return &rateLimiter{
+		rate:   float64(perSec),
+		tokens: float64(perSec),
+		last:   time.Now(),
+	}
+}
+
+// Blocks until n more units are allowed or stop is closed.
+// Larger requests than the burst go into debt which later ones wait out.
+func (l *rateLimiter) wait(n int64, stop <-chan bool) {
+	if delay := l.reserve(n); delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-stop:
+		}
+	}
+}
+
+// Takes n units returning how long to wait before using them
+func (l *rateLimiter) reserve(n int64) time.Duration {
+	if l == nil || n <= 0 {
+		return 0
+	}
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.rate {
+		l.tokens = l.rate
+	}
+	l.last = now
+	l.tokens -= float64(n)
+	if l.tokens < 0 {
+		return time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	return 0
+}
+
+// Passes the CSV data through at no more than the limiter's rows per second
+func throttleRows(data <-chan []byte, l *rateLimiter, quote byte) <-chan []byte {
+	out := make(chan []byte, 1)
+	go func() {
+		defer close(out)
+		cc := &csvCounter{quote: quote}
+		for b := range data {
+			before := cc.rows
+			cc.add(b)
+			l.wait(cc.rows-before, nil)
+			out <- b
+		}
+	}()
+	return out
+}
@@ -0,0 +1,70 @@
+/*
+	AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Works out the TLS config to actually connect with based on
+// the TLSConfig, TLSFingerprint and RequireTLS settings.
+func (c *Conn) initTLS() error {
+	cfg := c.Conf.TLSConfig
+	if cfg == nil {
+		if c.Conf.RequireTLS {
+			return errors.New("RequireTLS is set but no TLSConfig was specified")
+		}
+		if c.Conf.TLSFingerprint != "" {
+			return errors.New("TLSFingerprint requires a TLSConfig")
+		}
+		return nil
+	}
+
+	fingerprint := normalizeFingerprint(c.Conf.TLSFingerprint)
+	if fingerprint == "" {
+		if c.Conf.RequireTLS && cfg.InsecureSkipVerify {
+			return errors.New("RequireTLS is set but the certificate is not verified " +
+				"(InsecureSkipVerify without a TLSFingerprint)")
+		}
+		c.tlsConfig = cfg
+		return nil
+	}
+
+	// The fingerprint takes the place of the usual chain verification
+	// because Exasol clusters commonly use self-signed certificates.
+	cfg = cfg.Clone()
+	cfg.InsecureSkipVerify = true
+	cfg.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return errors.New("Server presented no certificate")
+		}
+		sum := sha256.Sum256(rawCerts[0])
+		got := strings.ToUpper(hex.EncodeToString(sum[:]))
+		if got != fingerprint {
+			return fmt.Errorf("Server certificate fingerprint %s does not match %s", got, fingerprint)
+		}
+		return nil
+	}
+	c.tlsConfig = cfg
+	return nil
+}
+
+func normalizeFingerprint(fp string) string {
+	fp = strings.ReplaceAll(fp, ":", "")
+	return strings.ToUpper(strings.TrimSpace(fp))
+}
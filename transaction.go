@@ -0,0 +1,130 @@
+/*
+	Exasol serializes transactions with a cluster-wide locking model
+	so concurrent writers regularly get rolled back with transaction
+	conflicts (SQL code 40001, "GlobalTransactionRollback"). These can
+	be detected with errors.Is(err, exasol.ErrTxConflict).
+
+	Transaction runs a closure in a transaction, committing it if the
+	closure succeeds and rolling it back otherwise. If ConnConf.TxRetry
+	allows it, transactions failing due to a conflict are rolled back
+	and the whole closure is run again after a backoff, e.g.
+
+	    conf.TxRetry = exasol.TxRetryPolicy{Attempts: 5}
+	    ...
+	    err := conn.Transaction(func() error {
+	        if _, err := conn.Execute("UPDATE ..."); err != nil {
+	            return err
+	        }
+	        _, err := conn.Execute("INSERT ...")
+	        return err
+	    })
+
+	The closure must therefore be safe to re-run, i.e. it shouldn't
+	have side effects outside of the transaction.
+
+	With autocommit off the session is always in a transaction which
+	may hold uncommitted work so Transaction refuses to run then (or
+	when nested) and returns ErrTxInProgress.
+
+
+	AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"errors"
+	"math/rand"
+	"time"
+)
+
+type TxRetryPolicy struct {
+	// The most times to run the closure. Zero or one means never retry.
+	Attempts int
+	// The wait before the first retry which doubles for each subsequent
+	// one (up to MaxBackoff) with up to 50% jitter added. Defaults to 100ms
+	Backoff time.Duration
+	// Defaults to 10s
+	MaxBackoff time.Duration
+}
+
+// Runs fn in a transaction as described above. Autocommit must be on.
+// It's disabled for the duration and then re-enabled.
+func (c *Conn) Transaction(fn func() error) error {
+	if !c.Autocommit() {
+		return c.errorf("Unable to start transaction: %w", ErrTxInProgress)
+	}
+	if err := c.DisableAutoCommit(); err != nil {
+		return err
+	}
+	defer c.EnableAutoCommit()
+
+	policy := c.Conf.TxRetry
+	backoff := policy.backoff()
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil {
+			err = c.Commit()
+		}
+		if err == nil {
+			return nil
+		}
+		if rbErr := c.Rollback(); rbErr != nil {
+			return err
+		}
+		if !errors.Is(err, ErrTxConflict) || attempt >= policy.Attempts {
+			return err
+		}
+		c.log.Warningf("Transaction conflict (attempt %d of %d). Retrying in %s",
+			attempt, policy.Attempts, backoff)
+		if !c.sleep(backoff) {
+			return err
+		}
+		backoff = policy.next(backoff)
+	}
+}
+
+/*--- Private Routines ---*/
+
+func (p TxRetryPolicy) backoff() time.Duration {
+	if p.Backoff <= 0 {
+		return 100 * time.Millisecond
+	}
+	return p.Backoff
+}
+
+func (p TxRetryPolicy) next(backoff time.Duration) time.Duration {
+	max := p.MaxBackoff
+	if max <= 0 {
+		max = 10 * time.Second
+	}
+	backoff = backoff*2 + time.Duration(rand.Int63n(int64(backoff)/2+1))
+	if backoff > max {
+		backoff = max
+	}
+	return backoff
+}
+
+// Returns false if the connection's context is done first
+func (c *Conn) sleep(d time.Duration) bool {
+	var done <-chan struct{}
+	if c.ctx != nil {
+		done = c.ctx.Done()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-done:
+		return false
+	}
+}
@@ -0,0 +1,77 @@
+package exasol
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+func (s *testSuite) TestTransactionRetry() {
+	conf := s.connConf()
+	conf.TxRetry = TxRetryPolicy{Attempts: 3, Backoff: time.Millisecond}
+	c, err := Connect(conf)
+	s.Require().NoError(err)
+	defer c.Disconnect()
+	s.execute("CREATE TABLE " + s.qschema + ".tx_retry (a INT)")
+	sql := "INSERT INTO " + s.qschema + ".tx_retry VALUES (1)"
+
+	conflict := fmt.Errorf("Unable to execute: %w", newExaError(
+		"GlobalTransactionRollback msg: Transaction collision: automatic transaction rollback.",
+		"40001"))
+	s.True(errors.Is(conflict, ErrTxConflict))
+
+	runs := 0
+	err = c.Transaction(func() error {
+		runs++
+		if _, err := c.Execute(sql); err != nil {
+			return err
+		}
+		if runs < 3 {
+			return conflict
+		}
+		return nil
+	})
+	s.NoError(err)
+	s.Equal(3, runs, "Retried until it succeeded")
+	s.True(c.Autocommit(), "Autocommit restored")
+	got, err := c.FetchSlice("SELECT COUNT(*) FROM " + s.qschema + ".tx_retry")
+	s.NoError(err)
+	s.Equal([][]interface{}{{float64(1)}}, got, "Failed attempts rolled back")
+
+	runs = 0
+	err = c.Transaction(func() error { runs++; return conflict })
+	s.True(errors.Is(err, ErrTxConflict), "Gives up after the attempts")
+	s.Equal(3, runs)
+
+	runs = 0
+	other := errors.New("Something else")
+	err = c.Transaction(func() error { runs++; return other })
+	s.Equal(other, err, "Other errors aren't retried")
+	s.Equal(1, runs)
+}
+
+func (s *testSuite) TestTransactionInProgress() {
+	c, err := Connect(s.connConf())
+	s.Require().NoError(err)
+	defer c.Disconnect()
+	s.execute("CREATE TABLE " + s.qschema + ".tx_open (a INT)")
+	sql := "INSERT INTO " + s.qschema + ".tx_open VALUES (1)"
+
+	s.Require().NoError(c.DisableAutoCommit())
+	_, err = c.Execute(sql)
+	s.Require().NoError(err)
+	runs := 0
+	err = c.Transaction(func() error { runs++; return errors.New("Rolled back") })
+	s.True(errors.Is(err, ErrTxInProgress))
+	s.Equal(0, runs, "Not run")
+	s.NoError(c.Commit())
+	got, err := c.FetchSlice("SELECT COUNT(*) FROM " + s.qschema + ".tx_open")
+	s.NoError(err)
+	s.Equal([][]interface{}{{float64(1)}}, got, "Earlier work left alone")
+	s.NoError(c.EnableAutoCommit())
+
+	err = c.Transaction(func() error {
+		return c.Transaction(func() error { return nil })
+	})
+	s.True(errors.Is(err, ErrTxInProgress), "Can't be nested")
+}
@@ -21,6 +21,7 @@ import (
 	"regexp"
 	"strings"
 	"sync"
+	"time"
 )
 
 var keywordLock sync.RWMutex
@@ -111,28 +112,53 @@ func (c *Conn) error(text string) error {
 	return err
 }
 
+// If one of the args is a server error it's first passed to OnServerError
 func (c *Conn) errorf(format string, args ...interface{}) error {
+	action := c.serverErrorAction(args)
 	err := fmt.Errorf(format, args...)
-	if c.Conf.SuppressError == false {
+	if action.Escalate || !action.Suppress && !c.Conf.SuppressError {
 		c.log.Error(err)
 	}
 	return err
 }
 
-func transposeToChan(ctx context.Context, ch chan<- FetchResult, matrix [][]interface{}) error {
+var errFetchAbandoned = errors.New("FetchChan consumer appears to have been abandoned")
+var errFetchDone = errors.New("Fetched enough rows")
+
+// If abandonAfter is non-zero and the channel stays full for that long
+// errFetchAbandoned is returned.
+func transposeToChan(ctx context.Context, ch chan<- FetchResult, cols []Column, matrix [][]interface{}, abandonAfter time.Duration, pool *rowPool) error {
 	// matrix is columnar ... this transposes it to rowular
 	for row := range matrix[0] {
-		ret := make([]interface{}, len(matrix))
+		res := pool.row(cols, len(matrix))
 		for col := range matrix {
-			ret[col] = matrix[col][row]
+			res.Data[col] = matrix[col][row]
+		}
+		if abandonAfter <= 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case ch <- res:
+				// continue
+			}
+			continue
+		}
+
+		select {
+		case ch <- res:
+			continue
+		default:
 		}
+		timer := time.NewTimer(abandonAfter)
 		select {
 		case <-ctx.Done():
+			timer.Stop()
 			return ctx.Err()
-		case ch <- FetchResult{Data: ret}:
-			// continue
+		case <-timer.C:
+			return errFetchAbandoned
+		case ch <- res:
+			timer.Stop()
 		}
-
 	}
 	return nil
 }
@@ -0,0 +1,145 @@
+/*
+	For frequent tiny writes the prepare/execute round trips of
+	Execute-with-binds dominate latency. InsertValues instead renders a
+	single multi-row INSERT ... VALUES (...),(...) statement with the
+	values escaped client-side as SQL literals, so it takes one round trip.
+
+	    n, err := conn.InsertValues("my_schema", "my_table",
+	        []string{"id", "name"},
+	        [][]interface{}{{1, "a"}, {2, nil}},
+	    )
+
+	This is only intended for small batches. The whole statement is sent
+	(and parsed) as text, so use Execute with binds, or BulkInsert, for
+	larger ones.
+
+
+	AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Columns may be nil in which case each row must contain a value for
+// every column of the table in order. Supported value types are nil,
+// string, bool, the integer and float types and time.Time.
+func (c *Conn) InsertValues(schema, table string, columns []string, rows [][]interface{}) (int64, error) {
+	if len(rows) == 0 {
+		return 0, nil
+	}
+
+	var sql strings.Builder
+	sql.WriteString("INSERT INTO ")
+	if schema != "" {
+		sql.WriteString(c.QuoteIdent(schema) + ".")
+	}
+	sql.WriteString(c.QuoteIdent(table))
+	if len(columns) > 0 {
+		quoted := make([]string, len(columns))
+		for i, col := range columns {
+			quoted[i] = c.QuoteIdent(col)
+		}
+		sql.WriteString(" (" + strings.Join(quoted, ", ") + ")")
+	}
+	sql.WriteString(" VALUES ")
+
+	numCols := len(rows[0])
+	if len(columns) > 0 {
+		numCols = len(columns)
+	}
+	for i, row := range rows {
+		if len(row) != numCols {
+			return 0, c.errorf("InsertValues row %d has %d values not %d", i+1, len(row), numCols)
+		}
+		if i > 0 {
+			sql.WriteString(", ")
+		}
+		sql.WriteString("(")
+		for j, val := range row {
+			lit, err := sqlLiteral(val)
+			if err != nil {
+				return 0, c.errorf("Unable to render row %d column %d: %w", i+1, j+1, err)
+			}
+			if j > 0 {
+				sql.WriteString(", ")
+			}
+			sql.WriteString(lit)
+		}
+		sql.WriteString(")")
+	}
+
+	res, err := c.execute(sql.String(), &ExecConf{})
+	if err != nil {
+		return 0, c.errorf("Unable to InsertValues: %w", err)
+	} else if res.ResponseData.NumResults > 0 {
+		return res.ResponseData.Results[0].RowCount, nil
+	}
+	return 0, nil
+}
+
+/*--- Private Routines ---*/
+
+func sqlLiteral(val interface{}) (string, error) {
+	switch v := val.(type) {
+	case nil:
+		return "NULL", nil
+	case string:
+		return "'" + QuoteStr(v) + "'", nil
+	case bool:
+		if v {
+			return "TRUE", nil
+		}
+		return "FALSE", nil
+	case int:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int8:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int16:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int32:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint8:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint16:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint32:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case float32:
+		return floatLiteral(float64(v), 32)
+	case float64:
+		return floatLiteral(v, 64)
+	case time.Time:
+		return "TIMESTAMP '" + v.Format("2006-01-02 15:04:05.000000") + "'", nil
+	default:
+		return "", fmt.Errorf("Unsupported value type %T", val)
+	}
+}
+
+func floatLiteral(f float64, bitSize int) (string, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", fmt.Errorf("Exasol doesn't support %v", f)
+	}
+	// The E notation makes it a DOUBLE literal rather than a DECIMAL
+	return strconv.FormatFloat(f, 'E', -1, bitSize), nil
+}
@@ -0,0 +1,37 @@
+package exasol
+
+import (
+	"math"
+	"time"
+)
+
+func (s *testSuite) TestInsertValues() {
+	exa := s.exaConn
+	s.execute("CREATE TABLE foo ( id INT, name VARCHAR(10), price DOUBLE, ok BOOLEAN, ts TIMESTAMP )")
+
+	n, err := exa.InsertValues(s.schema, "foo", nil, [][]interface{}{
+		{1, "it's", 1.5, true, time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{int64(2), nil, float32(2), false, nil},
+	})
+	s.NoError(err)
+	s.Equal(int64(2), n)
+
+	n, err = exa.InsertValues(s.schema, "foo", []string{"id", "name"}, [][]interface{}{{3, "c"}})
+	s.NoError(err)
+	s.Equal(int64(1), n)
+
+	got := s.fetch("SELECT id, name, price, ok, ts FROM foo ORDER BY id")
+	s.Equal([][]interface{}{
+		{float64(1), "it's", 1.5, true, "2020-01-02 03:04:05.000000"},
+		{float64(2), nil, float64(2), false, nil},
+		{float64(3), "c", nil, nil, nil},
+	}, got)
+
+	exa.Conf.SuppressError = true
+	_, err = exa.InsertValues(s.schema, "foo", []string{"id", "name"}, [][]interface{}{{4}})
+	s.EqualError(err, "InsertValues row 1 has 1 values not 2")
+	_, err = exa.InsertValues(s.schema, "foo", []string{"price"}, [][]interface{}{{math.NaN()}})
+	s.Error(err)
+	_, err = exa.InsertValues(s.schema, "foo", []string{"name"}, [][]interface{}{{[]byte("x")}})
+	s.EqualError(err, "Unable to render row 1 column 1: Unsupported value type []uint8")
+}
@@ -0,0 +1,91 @@
+/*
+	Optional row count verification of bulk transfers (see WithVerify)
+	so that silently truncated data is caught. The number of CSV records
+	sent or received by the client is compared to the row count reported
+	by the server for the IMPORT or EXPORT. For chunked imports each chunk
+	is verified before it's committed.
+
+	The server doesn't report anything a checksum of the data could be
+	compared to so only counts are verified.
+
+
+	AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import "fmt"
+
+type CountMismatchError struct {
+	Op       string // "import" or "export"
+	Chunk    int    // The 1-based chunk of a chunked import otherwise zero
+	Expected int64  // The number of records sent or received by the client
+	Actual   int64  // As reported by the server
+}
+
+func (e *CountMismatchError) Error() string {
+	chunk := ""
+	if e.Chunk > 0 {
+		chunk = fmt.Sprintf(" of chunk %d", e.Chunk)
+	}
+	return fmt.Sprintf(
+		"Row count mismatch for %s%s: the client has %d rows but the server has %d",
+		e.Op, chunk, e.Expected, e.Actual,
+	)
+}
+
+/*--- Private Routines ---*/
+
+// Counts the records in a CSV stream passed to add in pieces
+type csvCounter struct {
+	quote   byte
+	inQuote bool
+	rows    int64
+	last    byte
+}
+
+func (cc *csvCounter) add(b []byte) {
+	if len(b) == 0 {
+		return
+	}
+	_, found := scanCSVRecords(b, -1, cc.quote, &cc.inQuote)
+	cc.rows += int64(found)
+	cc.last = b[len(b)-1]
+}
+
+// Includes a final record without a trailing newline
+func (cc *csvCounter) total() int64 {
+	if cc.last != 0 && cc.last != '\n' {
+		return cc.rows + 1
+	}
+	return cc.rows
+}
+
+// Passes the data through counting its records. The count
+// is complete once the returned channel has been closed.
+func countCSV(data <-chan []byte, cc *csvCounter) <-chan []byte {
+	out := make(chan []byte, 1)
+	go func() {
+		defer close(out)
+		for b := range data {
+			cc.add(b)
+			out <- b
+		}
+	}()
+	return out
+}
+
+func verifyCount(op string, chunk int, expected, actual int64) error {
+	if expected == actual {
+		return nil
+	}
+	return &CountMismatchError{Op: op, Chunk: chunk, Expected: expected, Actual: actual}
+}
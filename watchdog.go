@@ -0,0 +1,202 @@
+/*
+	An optional watchdog for long-running statements, protecting shared
+	clusters from runaway queries. Once a statement has been running for
+	the Threshold it's looked up in EXA_DBA_SESSIONS (every Interval until
+	it finishes) and reported to the OnLongRunning callback. It's aborted
+	if the callback says so or once it has been running for AbortAfter.
+
+	    conf.Watchdog = &exasol.Watchdog{
+	        Threshold:  time.Minute,
+	        AbortAfter: 30 * time.Minute,
+	        OnLongRunning: func(s exasol.LongRunningStmt) bool {
+	            log.Printf("Statement running for %s: %s", s.Elapsed, s.SQLText)
+	            return false
+	        },
+	    }
+
+	As the connection is busy with the statement the watchdog uses a
+	second session which is opened alongside the connection's own. That
+	requires access to EXA_DBA_SESSIONS. Aborts use KILL STATEMENT which
+	the user can always do for their own sessions.
+
+
+	AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+type Watchdog struct {
+	Threshold time.Duration
+	// How often a long-running statement is rechecked. Defaults to Threshold.
+	Interval time.Duration
+	// Optional. Statements running for longer than this are aborted.
+	AbortAfter time.Duration
+	// Optional. Return true to abort the statement.
+	OnLongRunning func(LongRunningStmt) bool
+}
+
+type LongRunningStmt struct {
+	SessionID   uint64
+	StmtID      int64
+	Elapsed     time.Duration // As measured by the client
+	Status      string
+	CommandName string
+	Activity    string
+	TempDBRAM   float64 // In MiB
+	Resources   float64 // Percentage
+	SQLText     string
+	Aborted     bool // Whether it's about to be aborted
+}
+
+/*--- Private Routines ---*/
+
+// Opens the watchdog's session. Called while the credentials are
+// still available. Without it the watchdog is disabled.
+func (c *Conn) openWatchConn() {
+	if c.Conf.Watchdog == nil || c.Conf.Watchdog.Threshold <= 0 || c.watchSession() != nil {
+		return
+	}
+	conf := c.Conf
+	conf.Watchdog = nil
+	conf.CachePrepStmts = false
+	if conf.ClientName != "" {
+		conf.ClientName += " watchdog"
+	}
+	if c.prompted != nil {
+		conf.Username = c.prompted.Username
+		conf.Password = c.prompted.Password
+		conf.PromptCredentials = nil
+	}
+	wc, err := ConnectContext(conf, c.ctx)
+	if err != nil {
+		c.log.Warning("Unable to open watchdog session. The watchdog is disabled: ", err)
+		return
+	}
+	c.watchMux.Lock()
+	c.watchConn = wc
+	c.watchMux.Unlock()
+}
+
+func (c *Conn) closeWatchConn() {
+	c.watchMux.Lock()
+	wc := c.watchConn
+	c.watchConn = nil
+	c.watchMux.Unlock()
+	if wc != nil {
+		wc.Disconnect()
+	}
+}
+
+// The watchdog's session if it's open. The watchdog holds on to the
+// session it started with so a concurrent Disconnect merely makes its
+// checks fail.
+func (c *Conn) watchSession() *Conn {
+	c.watchMux.Lock()
+	defer c.watchMux.Unlock()
+	return c.watchConn
+}
+
+// Starts watching the request if it's a statement. The returned
+// func must be called once the response has been received.
+func (c *Conn) watch(request interface{}) func() {
+	wd := c.Conf.Watchdog
+	if wd == nil || wd.Threshold <= 0 {
+		return func() {}
+	}
+	wc := c.watchSession()
+	if wc == nil {
+		return func() {}
+	}
+	switch commandName(request) {
+	case "execute", "executePreparedStatement":
+	default:
+		return func() {}
+	}
+	interval := wd.Interval
+	if interval <= 0 {
+		interval = wd.Threshold
+	}
+
+	start := time.Now()
+	sessionID := c.SessionID
+	done := make(chan struct{})
+	go func() {
+		timer := time.NewTimer(wd.Threshold)
+		defer timer.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-timer.C:
+			}
+			if c.checkLongRunning(wc, sessionID, time.Since(start)) {
+				return
+			}
+			timer.Reset(interval)
+		}
+	}()
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
+
+// Returns true once there's nothing more to watch
+func (c *Conn) checkLongRunning(wc *Conn, sessionID uint64, elapsed time.Duration) bool {
+	wd := c.Conf.Watchdog
+	if wc.checkOpen() != nil {
+		return true // Closed along with the connection
+	}
+	stmt := LongRunningStmt{SessionID: sessionID, Elapsed: elapsed}
+	err := wc.scanStats(`
+		SELECT stmt_id, status, command_name, activity, temp_db_ram,
+		       resources, sql_text
+		FROM exa_dba_sessions
+		WHERE session_id = ?
+	`, []interface{}{fmt.Sprint(sessionID)}, func(s *statScanner) {
+		stmt.StmtID = s.int(0)
+		stmt.Status = s.str(1)
+		stmt.CommandName = s.str(2)
+		stmt.Activity = s.str(3)
+		stmt.TempDBRAM = s.float(4)
+		stmt.Resources = s.float(5)
+		stmt.SQLText = s.str(6)
+	})
+	if err != nil {
+		c.log.Warning("Watchdog is unable to check session: ", err)
+		return false
+	}
+	if stmt.StmtID == 0 || stmt.Status == "IDLE" {
+		return true // It has just finished
+	}
+
+	stmt.Aborted = wd.AbortAfter > 0 && elapsed >= wd.AbortAfter
+	if wd.OnLongRunning != nil && wd.OnLongRunning(stmt) {
+		stmt.Aborted = true
+	}
+	if !stmt.Aborted {
+		return false
+	}
+	c.log.Warningf(
+		"Watchdog is aborting statement %d in session %d after %s: %s",
+		stmt.StmtID, sessionID, elapsed.Round(time.Second), stmt.SQLText,
+	)
+	_, err = wc.Execute(fmt.Sprintf("KILL STATEMENT %d IN SESSION %d", stmt.StmtID, sessionID))
+	if err != nil {
+		c.log.Warning("Watchdog is unable to abort statement: ", err)
+		return false
+	}
+	return true
+}
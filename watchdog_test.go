@@ -0,0 +1,47 @@
+package exasol
+
+import (
+	"sync"
+	"time"
+)
+
+func (s *testSuite) TestWatchdog() {
+	var mux sync.Mutex
+	var reported []LongRunningStmt
+	conf := s.connConf()
+	conf.SuppressError = true
+	conf.Watchdog = &Watchdog{
+		Threshold:  500 * time.Millisecond,
+		Interval:   250 * time.Millisecond,
+		AbortAfter: 2 * time.Second,
+		OnLongRunning: func(stmt LongRunningStmt) bool {
+			mux.Lock()
+			defer mux.Unlock()
+			reported = append(reported, stmt)
+			return false
+		},
+	}
+	c, err := Connect(conf)
+	s.Require().NoError(err)
+	defer c.Disconnect()
+
+	// Quick statements aren't reported
+	_, err = c.Execute("SELECT 1 FROM dual")
+	s.NoError(err)
+
+	start := time.Now()
+	_, err = c.Execute(`
+		SELECT COUNT(*) FROM
+		  (SELECT level FROM dual CONNECT BY level <= 100000) a,
+		  (SELECT level FROM dual CONNECT BY level <= 100000) b
+	`)
+	s.Error(err, "Aborted")
+	s.True(time.Since(start) < 10*time.Second)
+
+	mux.Lock()
+	defer mux.Unlock()
+	s.Require().NotEmpty(reported)
+	s.Equal(c.SessionID, reported[0].SessionID)
+	s.Contains(reported[0].SQLText, "CONNECT BY")
+	s.True(reported[len(reported)-1].Aborted)
+}
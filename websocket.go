@@ -13,15 +13,28 @@
 package exasol
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"math/rand"
+	"net"
 	"net/url"
 	"reflect"
 	"regexp"
 	"strconv"
+	"sync/atomic"
 	"time"
 )
 
+// See ConnConf.CommandTimeouts. Zero means no timeout.
+type CommandTimeouts struct {
+	Login      time.Duration // login, loginToken and the authentication
+	Execute    time.Duration // Executing and preparing statements
+	Fetch      time.Duration // Each block of a result set
+	Disconnect time.Duration // Including closing result sets and statements
+	Attributes time.Duration // get/setAttributes and getHosts
+}
+
 func (c *Conn) wsConnect() (err error) {
 	host := c.Conf.Host
 
@@ -40,65 +53,243 @@ func (c *Conn) wsConnect() (err error) {
 		rand.Shuffle(len(ips), func(i, j int) { ips[i], ips[j] = ips[j], ips[i] })
 
 		for _, ip := range ips {
-			err = c.wsConnectHost(ip)
+			err = c.wsConnectHost(ip, ip)
+			if err == nil {
+				break
+			}
+		}
+	} else if ips := c.resolveHost(host); len(ips) > 1 {
+		// Cluster DNS typically round-robins across the nodes so,
+		// like the official drivers, pick one at random and if that
+		// fails try the next.
+		rand.Shuffle(len(ips), func(i, j int) { ips[i], ips[j] = ips[j], ips[i] })
+		for _, ip := range ips {
+			err = c.wsConnectHost(ip, host)
 			if err == nil {
 				break
 			}
+			c.log.Warningf("Unable to connect to %s (%s): %s", host, ip, err)
 		}
 	} else {
-		err = c.wsConnectHost(host)
+		err = c.wsConnectHost(host, host)
 	}
 
 	return err
 }
 
-func (c *Conn) wsConnectHost(host string) error {
-	uri := fmt.Sprintf("%s:%d", host, c.Conf.Port)
+// Returns the addresses the host resolves to or
+// nil if it's an IP or can't be resolved
+func (c *Conn) resolveHost(host string) []string {
+	if net.ParseIP(host) != nil {
+		return nil
+	}
+	ips, err := net.DefaultResolver.LookupHost(c.ctx, host)
+	if err != nil {
+		c.log.Debugf("Unable to resolve %s: %s", host, err)
+		return nil
+	}
+	return ips
+}
+
+// The serverName is the hostname to verify the TLS certificate against
+// when connecting to one of its resolved IPs.
+func (c *Conn) wsConnectHost(host, serverName string) (err error) {
 	scheme := "ws"
-	if c.Conf.TLSConfig != nil {
+	if c.Conf.URLScheme != "" {
+		scheme = c.Conf.URLScheme
+	} else if c.tlsConfig != nil {
 		scheme = "wss"
 	}
+	if scheme != "ws" && scheme != "wss" {
+		return fmt.Errorf("Invalid websocket URL scheme: %s", scheme)
+	}
+	// Otherwise an explicit ws scheme would silently connect in plaintext
+	if scheme == "ws" && (c.tlsConfig != nil || c.Conf.RequireTLS) {
+		return errors.New("The ws URL scheme can't be used with TLS. Use wss")
+	}
+
+	if err := c.Conf.CircuitBreaker.allow(host); err != nil {
+		return err
+	}
+	defer func() { c.Conf.CircuitBreaker.record(host, err) }()
+
+	tlsConfig := c.tlsConfig
+	if tlsConfig != nil && host != serverName && tlsConfig.ServerName == "" {
+		tlsConfig = tlsConfig.Clone()
+		tlsConfig.ServerName = serverName
+	}
+	uri := net.JoinHostPort(host, strconv.Itoa(int(c.Conf.Port)))
 	u := url.URL{
-		Scheme: scheme,
-		Host:   uri,
+		Scheme:   scheme,
+		Host:     uri,
+		Path:     c.Conf.URLPath,
+		RawQuery: c.Conf.URLQuery.Encode(),
 	}
 	c.log.Debugf("Connecting to %s", u.String())
 
-	return c.wsh.Connect(u, c.Conf.TLSConfig, c.Conf.ConnectTimeout)
+	ctx := c.ctx
+	if c.Conf.ConnectTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Conf.ConnectTimeout)
+		defer cancel()
+	}
+	err = c.wsh.Connect(ctx, u, tlsConfig)
+	if err == nil {
+		c.host = host
+	}
+	return err
 }
 
 // Request and Response are pointers to structs representing the API JSON.
 // The Response struct is updated in-place.
 
 func (c *Conn) send(request, response interface{}) error {
-	receiver, err := c.asyncSend(request)
+	return c.sendContext(c.ctx, request, response)
+}
+
+func (c *Conn) asyncSend(request interface{}) (func(interface{}) error, error) {
+	return c.asyncSendContext(c.ctx, request)
+}
+
+func (ct CommandTimeouts) forRequest(request interface{}) time.Duration {
+	switch commandName(request) {
+	case "login", "loginToken", "authenticate":
+		return ct.Login
+	case "execute", "executePreparedStatement", "createPreparedStatement":
+		return ct.Execute
+	case "fetch":
+		return ct.Fetch
+	case "disconnect", "closeResultSet", "closePreparedStatement":
+		return ct.Disconnect
+	case "getAttributes", "setAttributes", "getHosts":
+		return ct.Attributes
+	}
+	return 0
+}
+
+// The authentication request is the only one without a command
+func commandName(request interface{}) string {
+	if _, ok := request.(*authReq); ok {
+		return "authenticate"
+	}
+	r := reflect.Indirect(reflect.ValueOf(request))
+	if r.Kind() != reflect.Struct {
+		return ""
+	}
+	if cmd := r.FieldByName("Command"); cmd.IsValid() {
+		return cmd.String()
+	}
+	return ""
+}
+
+// The ID of the most recently sent command.
+// Useful for correlating retries with the debug logs.
+func (c *Conn) lastCommandID() uint64 {
+	return atomic.LoadUint64(&c.cmdID)
+}
+
+// Combines a per-call context with the connection's. The returned
+// func must be called to release the resources once the call is done.
+func (c *Conn) callContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if ctx == nil {
+		return c.ctx, func() {}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	if c.ctx == nil || c.ctx.Done() == nil {
+		return ctx, cancel
+	}
+	go func() {
+		select {
+		case <-c.ctx.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+// The context's deadline applies to the websocket write and read
+func (c *Conn) sendContext(ctx context.Context, request, response interface{}) error {
+	receiver, err := c.asyncSendContext(ctx, request)
 	if err != nil {
 		return err
 	}
 	return receiver(response)
 }
 
-func (c *Conn) asyncSend(request interface{}) (func(interface{}) error, error) {
-	err := c.wsh.WriteJSON(request)
+func (c *Conn) asyncSendContext(ctx context.Context, request interface{}) (func(interface{}) error, error) {
+	cancel := func() {}
+	if timeout := c.Conf.CommandTimeouts.forRequest(request); timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	}
+	// Commands are numbered so that requests and responses
+	// can be correlated in the debug logs
+	id := atomic.AddUint64(&c.cmdID, 1)
+	c.log.Debugf("Command %d (session %d): Sending %s", id, c.SessionID, commandName(request))
+	// The websocket is held from the request until its response is read
+	// so that concurrent callers (e.g. several FetchChan consumers) don't
+	// receive each other's responses.
+	err := c.queue.acquire(ctx)
+	if err != nil {
+		cancel()
+		return nil, c.errorf("Gave up waiting to send %s: %w", commandName(request), err)
+	}
+	// Checked again now that we hold the websocket as closeWebsocket
+	// waits for the queue before dropping it.
+	if atomic.LoadInt32(&c.closed) != 0 {
+		c.queue.release()
+		cancel()
+		return nil, c.errorf("Unable to send %s: %w", commandName(request), ErrConnClosed)
+	}
+	err = c.wsh.WriteJSON(ctx, request)
 	if err != nil {
-		return nil, c.errorf("WebSocket API Error sending: %s", err)
+		c.queue.release()
+		cancel()
+		if isClosedError(err) {
+			err = newSentinelError(err.Error(), ErrConnClosed)
+		}
+		return nil, c.errorf("WebSocket API Error sending: %w", err)
 	}
 
+	unwatch := c.watch(request)
+
 	return func(response interface{}) error {
-		err = c.wsh.ReadJSON(response)
+		defer cancel()
+		err := c.wsh.ReadJSON(ctx, response)
+		unwatch()
+		c.queue.release()
 		if err != nil {
+			var sizeErr *MessageSizeError
+			if errors.As(err, &sizeErr) {
+				return sizeErr
+			}
 			if regexp.MustCompile(`abnormal closure`).
 				MatchString(err.Error()) {
-				return fmt.Errorf("Server terminated statement")
+				// This is how the server enforces the query timeout
+				if c.trackedAttributes().QueryTimeout > 0 {
+					return newSentinelError("Server terminated statement", ErrConnClosed, ErrQueryTimeout)
+				}
+				return newSentinelError("Server terminated statement", ErrConnClosed)
 			}
-			return fmt.Errorf("WebSocket API Error recving: %s", err)
+			if isClosedError(err) {
+				err = newSentinelError(err.Error(), ErrConnClosed)
+			}
+			return fmt.Errorf("WebSocket API Error recving: %w", err)
 		}
 		r := reflect.Indirect(reflect.ValueOf(response))
+		if f := r.FieldByName("Attributes"); f.IsValid() {
+			if a, ok := f.Interface().(*Attributes); ok {
+				c.trackAttributes(a)
+			}
+		}
 		status := r.FieldByName("Status").String()
+		c.log.Debugf("Command %d (session %d): Received %s", id, c.SessionID, status)
 		if status != "ok" {
-			err := reflect.Indirect(r.FieldByName("Exception")).
-				FieldByName("Text").String()
-			return fmt.Errorf("Server Error: %s", err)
+			exc := reflect.Indirect(r.FieldByName("Exception"))
+			return newExaError(
+				exc.FieldByName("Text").String(),
+				exc.FieldByName("Sqlcode").String(),
+			)
 		}
 		return nil
 	}, nil
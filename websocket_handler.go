@@ -13,7 +13,15 @@
 package exasol
 
 import (
+	"bytes"
+	"context"
 	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
 	"net/url"
 	"time"
 
@@ -21,33 +29,70 @@ import (
 )
 
 // This is the default websocket handler that uses gorilla/websocket implementation
-// and conforms to the WSHandler interface
+// and conforms to the WSHandlerV2 interface.
+// Cancelling the context of a read or write interrupts it but also
+// leaves the websocket unusable (as is the case for gorilla timeouts).
 
 type defWSHandler struct {
-	ws *websocket.Conn
+	ws        *websocket.Conn
+	dialer    websocket.Dialer
+	readLimit int64
+	codec     Codec
+	headers   http.Header
 }
 
-func newDefaultWSHandler() *defWSHandler {
-	return &defWSHandler{}
+// Returned when a message from the server exceeds ConnConf.MaxMessageSize
+type MessageSizeError struct {
+	Limit int64
+	Size  int64 // The size of the message that was received
+}
+
+func (e *MessageSizeError) Error() string {
+	return fmt.Sprintf(
+		"Websocket message of %d bytes exceeds the %d byte limit. "+
+			"Increase ConnConf.MaxMessageSize or decrease ConnConf.FetchReqSize",
+		e.Size, e.Limit,
+	)
+}
+
+func newDefaultWSHandler(conf ConnConf) *defWSHandler {
+	wsh := &defWSHandler{
+		dialer:    defaultDialer,
+		readLimit: conf.MaxMessageSize,
+		codec:     conf.Codec,
+		headers:   conf.Headers,
+	}
+	if wsh.codec == nil {
+		wsh.codec = stdCodec{}
+	}
+	wsh.dialer.ReadBufferSize = conf.ReadBufferSize
+	wsh.dialer.WriteBufferSize = conf.WriteBufferSize
+	wsh.dialer.Subprotocols = conf.Subprotocols
+	if conf.KeepAlive != 0 {
+		wsh.dialer.NetDialContext = (&net.Dialer{KeepAlive: conf.KeepAlive}).DialContext
+	}
+	return wsh
 }
 
 var defaultDialer = *websocket.DefaultDialer
 
+// Returned if the websocket was never connected or has since been closed
+var errWSNotConnected = errors.New("Websocket is not connected")
+
 func init() {
 	defaultDialer.Proxy = nil // TODO use proxy env
 	defaultDialer.EnableCompression = false
 }
 
-func (wsh *defWSHandler) Connect(url url.URL, tls *tls.Config, timeout time.Duration) error {
-	if timeout != time.Duration(0) {
-		defaultDialer.HandshakeTimeout = timeout
-	}
-	defaultDialer.TLSClientConfig = tls
+func (wsh *defWSHandler) Connect(ctx context.Context, url url.URL, tls *tls.Config) error {
+	wsh.dialer.TLSClientConfig = tls
 
-	// According to documentation:
-	// > It is safe to call Dialer's methods concurrently.
-	ws, _, err := defaultDialer.Dial(url.String(), nil)
+	ws, resp, err := wsh.dialer.DialContext(ctx, url.String(), wsh.headers)
 	if err != nil {
+		if resp != nil && err == websocket.ErrBadHandshake {
+			// e.g. a proxy rejecting the headers
+			return fmt.Errorf("%w: %s", err, resp.Status)
+		}
 		return err
 	}
 
@@ -55,10 +100,163 @@ func (wsh *defWSHandler) Connect(url url.URL, tls *tls.Config, timeout time.Dura
 	return nil
 }
 
-func (wsh *defWSHandler) WriteJSON(req interface{}) error { return wsh.ws.WriteJSON(req) }
-func (wsh *defWSHandler) ReadJSON(resp interface{}) error { return wsh.ws.ReadJSON(resp) }
-func (wsh *defWSHandler) EnableCompression(e bool)        { wsh.ws.EnableWriteCompression(e) }
-func (wsh *defWSHandler) Close() {
-	wsh.ws.Close()
-	wsh.ws = nil
+func (wsh *defWSHandler) EnableCompression(e bool) {
+	if wsh.ws != nil {
+		wsh.ws.EnableWriteCompression(e)
+	}
+}
+
+func (wsh *defWSHandler) Close() error {
+	if wsh.ws == nil {
+		return errWSNotConnected
+	}
+	// The websocket is left in place so that a read or write which is
+	// in progress gets an error from it rather than racing with this
+	return wsh.ws.Close()
+}
+
+func (wsh *defWSHandler) WriteJSON(ctx context.Context, req interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if wsh.ws == nil {
+		return errWSNotConnected
+	}
+	data, err := wsh.codec.Marshal(req)
+	if err != nil {
+		return err
+	}
+	stop := watchContext(ctx, wsh.ws.SetWriteDeadline)
+	err = wsh.ws.WriteMessage(websocket.TextMessage, data)
+	stop()
+	return contextError(ctx, err)
+}
+
+func (wsh *defWSHandler) ReadJSON(ctx context.Context, resp interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if wsh.ws == nil {
+		return errWSNotConnected
+	}
+	stop := watchContext(ctx, wsh.ws.SetReadDeadline)
+	err := wsh.readJSON(resp)
+	stop()
+	return contextError(ctx, err)
+}
+
+func (wsh *defWSHandler) readJSON(resp interface{}) error {
+	_, r, err := wsh.ws.NextReader()
+	if err != nil {
+		return err
+	}
+	if wsh.readLimit <= 0 {
+		data, err := ioutil.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		return wsh.codec.Unmarshal(data, resp)
+	}
+
+	var buf bytes.Buffer
+	n, err := io.Copy(&buf, io.LimitReader(r, wsh.readLimit+1))
+	if err != nil {
+		return err
+	}
+	if n > wsh.readLimit {
+		// Drain the rest of the message so the connection stays usable
+		rest, err := io.Copy(ioutil.Discard, r)
+		if err != nil {
+			return err
+		}
+		return &MessageSizeError{Limit: wsh.readLimit, Size: n + rest}
+	}
+	return wsh.codec.Unmarshal(buf.Bytes(), resp)
+}
+
+// Reports I/O errors caused by the context as the context's error
+func contextError(ctx context.Context, err error) error {
+	if err != nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// Whether the error means the websocket is closed or has been dropped
+func isClosedError(err error) bool {
+	var closeErr *websocket.CloseError
+	return errors.As(err, &closeErr) ||
+		errors.Is(err, net.ErrClosed) ||
+		errors.Is(err, websocket.ErrCloseSent)
+}
+
+// Applies the context's deadline (if any) via setDeadline and
+// interrupts the pending I/O if the context is cancelled.
+// The returned func must be called once the I/O is complete.
+func watchContext(ctx context.Context, setDeadline func(time.Time) error) func() {
+	if ctx == nil || ctx.Done() == nil {
+		return func() {}
+	}
+	if dl, ok := ctx.Deadline(); ok {
+		setDeadline(dl)
+	}
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		select {
+		case <-ctx.Done():
+			setDeadline(time.Now())
+		case <-done:
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+		setDeadline(time.Time{})
+	}
+}
+
+// Wraps an original WSHandler so that it conforms to WSHandlerV2.
+// The context deadline is passed to Connect as its timeout and reads
+// and writes fail if the context is already done but, as WSHandler
+// has no means of doing so, they can't be interrupted midway.
+func AdaptWSHandler(wsh WSHandler) WSHandlerV2 {
+	return &wsHandlerAdapter{wsh}
+}
+
+type wsHandlerAdapter struct {
+	wsh WSHandler
+}
+
+func (a *wsHandlerAdapter) Connect(ctx context.Context, u url.URL, tls *tls.Config) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	var timeout time.Duration
+	if dl, ok := ctx.Deadline(); ok {
+		timeout = time.Until(dl)
+	}
+	return a.wsh.Connect(u, tls, timeout)
+}
+
+func (a *wsHandlerAdapter) EnableCompression(e bool) { a.wsh.EnableCompression(e) }
+
+func (a *wsHandlerAdapter) WriteJSON(ctx context.Context, req interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return a.wsh.WriteJSON(req)
+}
+
+func (a *wsHandlerAdapter) ReadJSON(ctx context.Context, resp interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return a.wsh.ReadJSON(resp)
+}
+
+func (a *wsHandlerAdapter) Close() error {
+	a.wsh.Close()
+	return nil
 }
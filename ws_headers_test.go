@@ -0,0 +1,54 @@
+package exasol
+
+import (
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"sync"
+)
+
+// Forwards the websocket to the test server checking the proxy
+// credentials along the way.
+func (s *testSuite) TestWSHeaders() {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	s.Require().NoError(err)
+	defer ln.Close()
+
+	var mu sync.Mutex
+	var gotProtocols []string
+	target := &url.URL{Scheme: "http", Host: net.JoinHostPort(*testHost, strconv.Itoa(*testPort))}
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	go http.Serve(ln, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Proxy-Authorization") != "Bearer s3cret" {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		mu.Lock()
+		gotProtocols = r.Header.Values("Sec-Websocket-Protocol")
+		mu.Unlock()
+		r.Header.Del("Sec-Websocket-Protocol")
+		proxy.ServeHTTP(w, r)
+	}))
+	proxyPort := uint16(ln.Addr().(*net.TCPAddr).Port)
+
+	conf := s.connConf()
+	conf.Host = "127.0.0.1"
+	conf.Port = proxyPort
+	_, err = Connect(conf)
+	s.Require().Error(err, "Rejected without the header")
+	s.Contains(err.Error(), "403")
+
+	WithHeader("Proxy-Authorization", "Bearer s3cret")(&conf)
+	WithSubprotocols("exasol")(&conf)
+	c, err := Connect(conf)
+	s.Require().NoError(err)
+	defer c.Disconnect()
+	_, err = c.Execute("SELECT 1 FROM dual")
+	s.NoError(err)
+
+	mu.Lock()
+	defer mu.Unlock()
+	s.Equal([]string{"exasol"}, gotProtocols)
+}
@@ -0,0 +1,166 @@
+/*
+	Middleware for intercepting websocket traffic without re-implementing
+	the whole WSHandlerV2 interface. A WSMiddleware wraps a handler and
+	Chain stacks them on top of a base handler, the first listed being
+	the outermost e.g.
+
+	    conf.WSHandlerV2 = exasol.Chain(
+	        exasol.NewDefaultWSHandler(conf),
+	        exasol.LoggingWS(logger),
+	        exasol.MetricsWS(func(ev exasol.WSEvent) { ... }),
+	        exasol.RetryWS(3, time.Second),
+	    )
+
+	Custom middleware can embed the wrapped WSHandlerV2 in a struct and
+	override only the methods it cares about:
+
+	    type tracer struct{ exasol.WSHandlerV2 }
+
+	    func (t tracer) WriteJSON(ctx context.Context, req interface{}) error {
+	        ...
+	        return t.WSHandlerV2.WriteJSON(ctx, req)
+	    }
+
+	    conf.WSHandlerV2 = exasol.Chain(base, func(next exasol.WSHandlerV2) exasol.WSHandlerV2 {
+	        return tracer{next}
+	    })
+
+
+	AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"context"
+	"crypto/tls"
+	"net/url"
+	"time"
+)
+
+type WSMiddleware func(next WSHandlerV2) WSHandlerV2
+
+// The websocket operations reported in WSEvent.Op
+const (
+	WSConnect = "connect"
+	WSWrite   = "write"
+	WSRead    = "read"
+)
+
+type WSEvent struct {
+	Op       string        // One of WSConnect, WSWrite or WSRead
+	Command  string        // The command of the request written or being responded to
+	Duration time.Duration // For reads this is the time since the request was written
+	Err      error
+}
+
+// Returns the handler the connection would use by default so it
+// can serve as the base of a Chain.
+func NewDefaultWSHandler(conf ConnConf) WSHandlerV2 {
+	return newDefaultWSHandler(conf)
+}
+
+// Wraps base with the middlewares, the first being the outermost.
+func Chain(base WSHandlerV2, mws ...WSMiddleware) WSHandlerV2 {
+	wsh := base
+	for i := len(mws) - 1; i >= 0; i-- {
+		wsh = mws[i](wsh)
+	}
+	return wsh
+}
+
+// Reports every connect, write and read to the observer.
+// The observer is called synchronously so it must be quick.
+func MetricsWS(observe func(WSEvent)) WSMiddleware {
+	return func(next WSHandlerV2) WSHandlerV2 {
+		return &observedWS{WSHandlerV2: next, observe: observe}
+	}
+}
+
+// Logs every websocket operation at debug level along with any errors.
+func LoggingWS(logger Logger) WSMiddleware {
+	return MetricsWS(func(ev WSEvent) {
+		switch {
+		case ev.Err != nil:
+			logger.Errorf("Websocket %s (%s) failed after %s: %s", ev.Op, ev.Command, ev.Duration, ev.Err)
+		case ev.Op == WSRead:
+			logger.Debugf("Websocket %s (%s) took %s", ev.Op, ev.Command, ev.Duration)
+		default:
+			logger.Debugf("Websocket %s (%s)", ev.Op, ev.Command)
+		}
+	})
+}
+
+// Retries failed connects up to attempts times in total, sleeping
+// backoff (doubled each time) in between. Only Connect is retried
+// because a resent request may already have been executed
+// and the response to an interrupted read is lost.
+func RetryWS(attempts int, backoff time.Duration) WSMiddleware {
+	return func(next WSHandlerV2) WSHandlerV2 {
+		return &retryWS{WSHandlerV2: next, attempts: attempts, backoff: backoff}
+	}
+}
+
+/*--- Private Routines ---*/
+
+type observedWS struct {
+	WSHandlerV2
+	observe func(WSEvent)
+	lastCmd string
+	written time.Time
+}
+
+func (o *observedWS) Connect(ctx context.Context, u url.URL, tls *tls.Config) error {
+	start := time.Now()
+	err := o.WSHandlerV2.Connect(ctx, u, tls)
+	o.observe(WSEvent{Op: WSConnect, Duration: time.Since(start), Err: err})
+	return err
+}
+
+func (o *observedWS) WriteJSON(ctx context.Context, req interface{}) error {
+	start := time.Now()
+	err := o.WSHandlerV2.WriteJSON(ctx, req)
+	// Requests and responses strictly alternate on a connection
+	// so reads can be attributed to the last write.
+	o.lastCmd = commandName(req)
+	o.written = time.Now()
+	o.observe(WSEvent{Op: WSWrite, Command: o.lastCmd, Duration: o.written.Sub(start), Err: err})
+	return err
+}
+
+func (o *observedWS) ReadJSON(ctx context.Context, resp interface{}) error {
+	err := o.WSHandlerV2.ReadJSON(ctx, resp)
+	o.observe(WSEvent{Op: WSRead, Command: o.lastCmd, Duration: time.Since(o.written), Err: err})
+	return err
+}
+
+type retryWS struct {
+	WSHandlerV2
+	attempts int
+	backoff  time.Duration
+}
+
+func (r *retryWS) Connect(ctx context.Context, u url.URL, tls *tls.Config) error {
+	backoff := r.backoff
+	var err error
+	for i := 0; ; i++ {
+		err = r.WSHandlerV2.Connect(ctx, u, tls)
+		if err == nil || i+1 >= r.attempts || ctx.Err() != nil {
+			return err
+		}
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return err
+		}
+		backoff *= 2
+	}
+}
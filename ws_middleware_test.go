@@ -0,0 +1,61 @@
+package exasol
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net/url"
+	"sync"
+	"time"
+)
+
+type failingConnectWS struct {
+	WSHandlerV2
+	failures int
+	connects int
+}
+
+func (f *failingConnectWS) Connect(ctx context.Context, u url.URL, tls *tls.Config) error {
+	f.connects++
+	if f.connects <= f.failures {
+		return errors.New("connection refused")
+	}
+	return f.WSHandlerV2.Connect(ctx, u, tls)
+}
+
+func (s *testSuite) TestWSMiddleware() {
+	var mu sync.Mutex
+	var events []WSEvent
+	conf := s.connConf()
+	flaky := &failingConnectWS{WSHandlerV2: NewDefaultWSHandler(conf), failures: 2}
+	conf.WSHandlerV2 = Chain(flaky,
+		LoggingWS(s.log),
+		MetricsWS(func(ev WSEvent) {
+			mu.Lock()
+			events = append(events, ev)
+			mu.Unlock()
+		}),
+		RetryWS(3, time.Millisecond),
+	)
+	c, err := Connect(conf)
+	s.Require().NoError(err)
+	defer c.Disconnect()
+	s.Equal(3, flaky.connects, "Connect retried")
+
+	_, err = c.Execute("SELECT 1 FROM dual")
+	s.NoError(err)
+
+	mu.Lock()
+	defer mu.Unlock()
+	s.Equal(WSConnect, events[0].Op)
+	s.NoError(events[0].Err, "Retries hidden from outer middleware")
+	var execWrite, execRead bool
+	for _, ev := range events {
+		if ev.Command == "execute" {
+			execWrite = execWrite || ev.Op == WSWrite
+			execRead = execRead || ev.Op == WSRead
+		}
+	}
+	s.True(execWrite, "Execute request observed")
+	s.True(execRead, "Execute response observed")
+}